@@ -13,12 +13,16 @@ var rootCmd = &cobra.Command{
 	Use:  "server",
 	Long: "RESTFul KEK API Server",
 	Run: func(cmd *cobra.Command, args []string) {
-		runApplication()
+		runServer()
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(serverCmd)
+	rootCmd.AddCommand(workerCmd)
+	for _, cmd := range []*cobra.Command{rootCmd, serverCmd} {
+		cmd.Flags().BoolVarP(&withWorker, "with-worker", "", false, "run background jobs in the api server")
+	}
 	rootCmd.PersistentFlags().StringVarP(&configFile, "conf", "", "", "config file path")
 }
 
@@ -12,6 +12,10 @@ import (
 	"kek-backend/internal/config"
 	"kek-backend/internal/database"
 	"kek-backend/internal/metric"
+	"kek-backend/internal/notification"
+	"kek-backend/internal/token"
+	tokenDB "kek-backend/internal/token/database"
+	"kek-backend/internal/uniswap"
 	"kek-backend/pkg/logging"
 	"net/http"
 	"time"
@@ -22,10 +26,14 @@ import (
 	"go.uber.org/fx"
 )
 
+// withWorker runs background jobs in the api server process for local development
+var withWorker bool
+
 var serverCmd = &cobra.Command{
-	Use: "server",
+	Use:   "server",
+	Short: "Run the api server",
 	Run: func(cmd *cobra.Command, args []string) {
-		runApplication()
+		runServer()
 	},
 }
 
@@ -72,15 +80,36 @@ func loadConfig() (*config.Config, error) {
 	return config.Load(configFile)
 }
 
-func runApplication() {
-	// setup application(di + run server)
-	app := fx.New(
+// commonOptions provides dependencies shared by the api server and the worker
+func commonOptions() fx.Option {
+	return fx.Provide(
+		// load config
+		loadConfig,
+		metric.NewMetricsProvider,
+		// setup database
+		database.NewDatabase,
+		// setup notification packages
+		notification.NewNotifier,
+		notification.NewWebhookSender,
+		notification.NewEmailSender,
+		notification.NewBotSender,
+		// setup uniswap packages
+		uniswap.NewClient,
+		uniswap.NewPriceCache,
+		tokenDB.NewTokenDB,
+		// setup alert packages
+		alertDB.NewAlertDB,
+		alert.NewDispatcher,
+	)
+}
+
+// serverOptions provides the api server and its handlers
+func serverOptions() fx.Option {
+	return fx.Options(
 		fx.Provide(
-			// load config
-			loadConfig,
-			metric.NewMetricsProvider,
-			// setup database
-			database.NewDatabase,
+			// setup token packages
+			token.NewResolver,
+			token.NewHandler,
 			// setup account packages
 			accountDB.NewAccountDB,
 			account.NewAuthMiddleware,
@@ -89,7 +118,6 @@ func runApplication() {
 			articleDB.NewArticleDB,
 			article.NewHandler,
 			// setup alert packages
-			alertDB.NewAlertDB,
 			alert.NewHandler,
 			// server
 			newServer,
@@ -98,8 +126,25 @@ func runApplication() {
 			account.RouteV1,
 			article.RouteV1,
 			alert.RouteV1,
-			printAppInfo,
+			token.RouteV1,
 		),
 	)
+}
+
+func runServer() {
+	options := []fx.Option{serverOptions()}
+	if withWorker {
+		options = append(options, workerOptions())
+	}
+	runApplication(options...)
+}
+
+func runApplication(options ...fx.Option) {
+	// setup application(di + run server)
+	app := fx.New(
+		commonOptions(),
+		fx.Options(options...),
+		fx.Invoke(printAppInfo),
+	)
 	app.Run()
 }
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"kek-backend/internal/alert"
+	alertDB "kek-backend/internal/alert/database"
+	"kek-backend/internal/config"
+	"kek-backend/internal/metric"
+	"kek-backend/internal/token"
+	"kek-backend/pkg/logging"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+// healthTimeout is a timeout of a database ping of the health check
+const healthTimeout = 3 * time.Second
+
+var workerCmd = &cobra.Command{
+	Use:   "worker",
+	Short: "Run background jobs without the api server",
+	Run: func(cmd *cobra.Command, args []string) {
+		runApplication(workerOptions())
+	},
+}
+
+// workerOptions provides background jobs and the health server of the worker
+func workerOptions() fx.Option {
+	return fx.Options(
+		fx.Provide(
+			newTokenLeases,
+			token.NewRollup,
+			token.NewSync,
+			alert.NewOutboxWorker,
+			alert.NewScheduler,
+		),
+		fx.Invoke(
+			token.StartRollup,
+			token.StartSync,
+			alert.StartScheduler,
+			startWorkerServer,
+		),
+	)
+}
+
+// newTokenLeases provides leases of token jobs held in the same table as the scheduler lease
+func newTokenLeases(db alertDB.AlertDB) token.Leases {
+	return db
+}
+
+// startWorkerServer serves health and metrics endpoints of the worker
+func startWorkerServer(lc fx.Lifecycle, cfg *config.Config, db *gorm.DB) {
+	r := gin.New()
+	r.Use(gin.Recovery())
+
+	metric.Route(r)
+	r.GET("health", func(c *gin.Context) {
+		sqlDB, err := db.DB()
+		if err == nil {
+			ctx, cancel := context.WithTimeout(c.Request.Context(), healthTimeout)
+			defer cancel()
+			err = sqlDB.PingContext(ctx)
+		}
+		if err != nil {
+			logging.FromContext(c).Errorw("worker.health failed to ping database", "err", err)
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	srv := &http.Server{
+		Addr:    fmt.Sprintf(":%d", cfg.WorkerConfig.Port),
+		Handler: r,
+	}
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			logging.FromContext(ctx).Infof("Start to worker server :%d", cfg.WorkerConfig.Port)
+			go srv.ListenAndServe()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			logging.FromContext(ctx).Infof("Stopped worker server")
+			return srv.Shutdown(ctx)
+		},
+	})
+}
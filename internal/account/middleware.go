@@ -4,8 +4,10 @@ import (
 	accountDB "kek-backend/internal/account/database"
 	"kek-backend/internal/account/model"
 	"kek-backend/internal/config"
+	"kek-backend/internal/middleware/handler"
 	"kek-backend/pkg/logging"
 	"net/http"
+	"strings"
 	"time"
 
 	jwt "github.com/appleboy/gin-jwt/v2"
@@ -39,6 +41,24 @@ func MustCurrentUser(c *gin.Context) *model.Account {
 	panic("no account in gin.Context")
 }
 
+// AdminMiddleware aborts requests with 403 unless the current user is listed in admin.emails.
+// It must be used after the auth middleware.
+func AdminMiddleware(cfg *config.Config) gin.HandlerFunc {
+	admins := make(map[string]bool)
+	for _, email := range cfg.AdminConfig.Emails {
+		admins[strings.ToLower(email)] = true
+	}
+	return func(c *gin.Context) {
+		acc, ok := CurrentUser(c)
+		if !ok || !admins[strings.ToLower(acc.Email)] {
+			logging.FromContext(c).Infow("middleware.admin forbidden", "account", acc)
+			c.AbortWithStatusJSON(http.StatusForbidden, &handler.ErrorResponse{Code: handler.Forbidden, Message: "admin only"})
+			return
+		}
+		c.Next()
+	}
+}
+
 func NewAuthMiddleware(cfg *config.Config, accountDB accountDB.AccountDB) (*jwt.GinJWTMiddleware, error) {
 	return jwt.New(&jwt.GinJWTMiddleware{
 		Realm:       "test zone",
@@ -0,0 +1,78 @@
+package alert
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/notification"
+)
+
+// ActionError is returned if an alert holds an invalid action
+type ActionError struct {
+	Field   string
+	Value   string
+	Message string
+}
+
+func (e *ActionError) Error() string {
+	return fmt.Sprintf("invalid action. field:%s, value:%s, message:%s", e.Field, e.Value, e.Message)
+}
+
+// ValidateActions checks given actions have all fields required by their types.
+// An alert must have at least one action and at most one action per type.
+// Webhook urls must use https and bot urls must be http or https, and both must not point to
+// private, loopback or link-local hosts.
+func ValidateActions(actions model.AlertActions) error {
+	if len(actions) == 0 {
+		return &ActionError{Field: "alertActions", Message: "required at least one alertAction"}
+	}
+	seen := make(map[string]bool)
+	for i, action := range actions {
+		field := func(name string) string {
+			return fmt.Sprintf("alertActions[%d].%s", i, name)
+		}
+		if seen[action.Type] {
+			return &ActionError{Field: field("type"), Value: action.Type, Message: "duplicate alertAction type"}
+		}
+		seen[action.Type] = true
+
+		switch action.Type {
+		case model.ChannelPush:
+		case model.ChannelWebhook:
+			if !isURL(action.URL, "https") {
+				return &ActionError{Field: field("url"), Value: action.URL, Message: "url must be a public https url"}
+			}
+		case model.ChannelEmail:
+			if addr, err := mail.ParseAddress(action.Email); err != nil || addr.Address != action.Email {
+				return &ActionError{Field: field("email"), Value: action.Email, Message: "required email format"}
+			}
+		case model.ChannelBot:
+			if !isURL(action.URL, "http", "https") {
+				return &ActionError{Field: field("url"), Value: action.URL, Message: "url must be a public http or https url"}
+			}
+			if strings.TrimSpace(action.ChatID) == "" {
+				return &ActionError{Field: field("chatId"), Value: action.ChatID, Message: "required chatId"}
+			}
+		default:
+			return &ActionError{Field: field("type"), Value: action.Type, Message: "unsupported alertAction type"}
+		}
+	}
+	return nil
+}
+
+// isURL returns true if given raw url is an absolute url of a public host with one of given schemes
+func isURL(raw string, schemes ...string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || !notification.IsPublicHost(u.Hostname()) {
+		return false
+	}
+	for _, s := range schemes {
+		if u.Scheme == s {
+			return true
+		}
+	}
+	return false
+}
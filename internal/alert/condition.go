@@ -0,0 +1,265 @@
+package alert
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"kek-backend/internal/alert/model"
+)
+
+// ConditionType is a kind of market value an alert watches
+type ConditionType string
+
+const (
+	// ConditionPrice compares the current USD price with a threshold
+	ConditionPrice = ConditionType("price")
+	// ConditionPercentChange compares the percent change of the USD price
+	// between the previous and the current observation with a threshold
+	ConditionPercentChange = ConditionType("percent_change")
+	// ConditionPercentChangeWindow compares the percent change of the USD price
+	// between the first price observed within the window and the current observation with a threshold
+	ConditionPercentChangeWindow = ConditionType("percent_change_window")
+	// ConditionPercentChangeSinceCreation compares the percent change of the USD price
+	// between the price at the alert's creation and the current observation with a threshold
+	ConditionPercentChangeSinceCreation = ConditionType("percent_change_since_creation")
+	// ConditionLiquidity compares the total liquidity of the token in USD with a threshold
+	ConditionLiquidity = ConditionType("liquidity")
+	// ConditionVolumeSpike compares the USD volume of the current UTC day so far with a threshold
+	// multiple of the average daily volume of the previous days pro-rated to the elapsed part of
+	// the day, e.g. at 06:00 UTC the volume is compared with a quarter of the average
+	ConditionVolumeSpike = ConditionType("volume_spike")
+)
+
+const (
+	minConditionWindow = time.Minute
+	// maxConditionWindow must not exceed the retention of price snapshots
+	maxConditionWindow = 24 * time.Hour
+)
+
+// Operator is a comparison applied between an observed value and a threshold
+type Operator string
+
+const (
+	OperatorAbove        = Operator("above")
+	OperatorBelow        = Operator("below")
+	OperatorCrossesAbove = Operator("crosses_above")
+	OperatorCrossesBelow = Operator("crosses_below")
+	// OperatorMoves holds if a percent change exceeds a threshold in either direction
+	OperatorMoves = Operator("moves")
+)
+
+var supportedOperators = map[ConditionType][]Operator{
+	ConditionPrice:                      {OperatorAbove, OperatorBelow, OperatorCrossesAbove, OperatorCrossesBelow},
+	ConditionPercentChange:              {OperatorAbove, OperatorBelow},
+	ConditionPercentChangeWindow:        {OperatorAbove, OperatorBelow, OperatorMoves},
+	ConditionPercentChangeSinceCreation: {OperatorAbove, OperatorBelow, OperatorMoves},
+	ConditionLiquidity:                  {OperatorAbove, OperatorBelow},
+	ConditionVolumeSpike:                {OperatorAbove},
+}
+
+// Condition is a typed form of an alert's AlertType, AlertOption, AlertValue and AlertWindow.
+// Window is only set for percent_change_window conditions.
+type Condition struct {
+	Type      ConditionType
+	Operator  Operator
+	Threshold float64
+	Window    time.Duration
+}
+
+// Observation is a USD price and market data observed for an alert's pair.
+// Previous, Baseline, Liquidity and the volumes are only meaningful if
+// HasPrevious, HasBaseline, HasLiquidity and HasVolume are true respectively.
+// AverageVolume is pro-rated to the elapsed part of the current day as Volume is.
+type Observation struct {
+	Price         float64
+	Previous      float64
+	HasPrevious   bool
+	Baseline      float64
+	HasBaseline   bool
+	Liquidity     float64
+	HasLiquidity  bool
+	Volume        float64
+	AverageVolume float64
+	HasVolume     bool
+}
+
+// ConditionError is returned if an alert holds an invalid condition
+type ConditionError struct {
+	Field   string
+	Value   string
+	Message string
+}
+
+func (e *ConditionError) Error() string {
+	return fmt.Sprintf("invalid condition. field:%s, value:%s, message:%s", e.Field, e.Value, e.Message)
+}
+
+// ParseAlertCondition parses a condition from given alert
+func ParseAlertCondition(a *model.Alert) (*Condition, error) {
+	return ParseCondition(a.AlertType, a.AlertOption, a.AlertValue, a.AlertWindow)
+}
+
+// ParseCondition parses given alert type, operator, threshold value and window to a Condition.
+// The window is required for percent_change_window and must be empty for the other types.
+func ParseCondition(alertType, option, value, window string) (*Condition, error) {
+	return parseCondition(alertType, option, value, window, supportedOperators, alertConditionFields)
+}
+
+// conditionFields are the names of the fields reported by ConditionError
+type conditionFields struct {
+	Type   string
+	Option string
+	Value  string
+	Window string
+}
+
+var alertConditionFields = conditionFields{
+	Type:   "alertType",
+	Option: "alertOption",
+	Value:  "alertValue",
+	Window: "alertWindow",
+}
+
+// parseCondition parses a Condition whose type and operator are one of given operators.
+// ConditionError is returned with the field names in given fields
+func parseCondition(alertType, option, value, window string, operators map[ConditionType][]Operator, fields conditionFields) (*Condition, error) {
+	ct := ConditionType(strings.ToLower(strings.TrimSpace(alertType)))
+	supportedOps, ok := operators[ct]
+	if !ok {
+		return nil, &ConditionError{Field: fields.Type, Value: alertType, Message: fmt.Sprintf("unsupported %s", fields.Type)}
+	}
+
+	op := Operator(strings.ToLower(strings.TrimSpace(option)))
+	supported := false
+	for _, o := range supportedOps {
+		if o == op {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return nil, &ConditionError{Field: fields.Option, Value: option, Message: fmt.Sprintf("unsupported %s for %s", fields.Option, ct)}
+	}
+
+	threshold, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return nil, &ConditionError{Field: fields.Value, Value: value, Message: fmt.Sprintf("%s must be numeric", fields.Value)}
+	}
+	if threshold <= 0 {
+		return nil, &ConditionError{Field: fields.Value, Value: value, Message: fmt.Sprintf("%s must be greater than 0", fields.Value)}
+	}
+
+	var w time.Duration
+	window = strings.TrimSpace(window)
+	if ct == ConditionPercentChangeWindow {
+		if window == "" {
+			return nil, &ConditionError{Field: fields.Window, Value: window, Message: fmt.Sprintf("%s is required for %s", fields.Window, ct)}
+		}
+		if w, err = time.ParseDuration(window); err != nil {
+			return nil, &ConditionError{Field: fields.Window, Value: window, Message: fmt.Sprintf("%s must be a duration such as 15m or 4h", fields.Window)}
+		}
+		if w < minConditionWindow || w > maxConditionWindow {
+			return nil, &ConditionError{Field: fields.Window, Value: window, Message: fmt.Sprintf("%s must be between %s and %s", fields.Window, minConditionWindow, maxConditionWindow)}
+		}
+	} else if window != "" {
+		return nil, &ConditionError{Field: fields.Window, Value: window, Message: fmt.Sprintf("%s is not allowed for %s", fields.Window, ct)}
+	}
+	return &Condition{
+		Type:      ct,
+		Operator:  op,
+		Threshold: threshold,
+		Window:    w,
+	}, nil
+}
+
+// Holds returns true if given observation satisfies the condition.
+// Crossing and percent change conditions never hold without a previous observation,
+// conditions with a baseline never hold without the baseline and liquidity and
+// volume conditions never hold without the market data.
+func (c *Condition) Holds(o Observation) bool {
+	switch c.Type {
+	case ConditionPrice, ConditionRatio:
+		switch c.Operator {
+		case OperatorAbove:
+			return o.Price >= c.Threshold
+		case OperatorBelow:
+			return o.Price <= c.Threshold
+		case OperatorCrossesAbove:
+			return o.HasPrevious && o.Previous < c.Threshold && o.Price >= c.Threshold
+		case OperatorCrossesBelow:
+			return o.HasPrevious && o.Previous > c.Threshold && o.Price <= c.Threshold
+		}
+	case ConditionPercentChange:
+		if !o.HasPrevious || o.Previous == 0 {
+			return false
+		}
+		return c.changeHolds(o.Previous, o.Price)
+	case ConditionPercentChangeWindow, ConditionPercentChangeSinceCreation:
+		if !o.HasBaseline || o.Baseline == 0 {
+			return false
+		}
+		return c.changeHolds(o.Baseline, o.Price)
+	case ConditionLiquidity:
+		if !o.HasLiquidity {
+			return false
+		}
+		switch c.Operator {
+		case OperatorAbove:
+			return o.Liquidity >= c.Threshold
+		case OperatorBelow:
+			return o.Liquidity <= c.Threshold
+		}
+	case ConditionVolumeSpike:
+		if !o.HasVolume || o.AverageVolume == 0 {
+			return false
+		}
+		return o.Volume >= o.AverageVolume*c.Threshold
+	}
+	return false
+}
+
+// PastBand returns true if given observation returned past the threshold by more than
+// given hysteresis percent in the opposite direction of the condition, e.g. a price above
+// 100 with 5% hysteresis is past the band once the price falls to 95 or below.
+// Conditions without a single observed value compared to the threshold are past the band
+// once they no longer hold.
+func (c *Condition) PastBand(o Observation, hysteresis float64) bool {
+	band := c.Threshold * hysteresis / 100
+	switch c.Type {
+	case ConditionPrice, ConditionRatio:
+		switch c.Operator {
+		case OperatorAbove, OperatorCrossesAbove:
+			return o.Price <= c.Threshold-band
+		case OperatorBelow, OperatorCrossesBelow:
+			return o.Price >= c.Threshold+band
+		}
+	case ConditionLiquidity:
+		if !o.HasLiquidity {
+			return false
+		}
+		switch c.Operator {
+		case OperatorAbove:
+			return o.Liquidity <= c.Threshold-band
+		case OperatorBelow:
+			return o.Liquidity >= c.Threshold+band
+		}
+	}
+	return !c.Holds(o)
+}
+
+// changeHolds returns true if the percent change from given base price to given price satisfies the condition
+func (c *Condition) changeHolds(base, price float64) bool {
+	change := (price - base) / base * 100
+	switch c.Operator {
+	case OperatorAbove:
+		return change >= c.Threshold
+	case OperatorBelow:
+		return change <= -c.Threshold
+	case OperatorMoves:
+		return math.Abs(change) >= c.Threshold
+	}
+	return false
+}
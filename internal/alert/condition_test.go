@@ -0,0 +1,302 @@
+package alert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCondition(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Type   string
+		Option string
+		Value  string
+		Window string
+		// expected
+		Condition *Condition
+		Field     string
+	}{
+		{
+			Name:      "Price above",
+			Type:      "price",
+			Option:    "above",
+			Value:     "1.5",
+			Condition: &Condition{Type: ConditionPrice, Operator: OperatorAbove, Threshold: 1.5},
+		}, {
+			Name:      "Percent change below with spaces and upper case",
+			Type:      " Percent_Change ",
+			Option:    "BELOW",
+			Value:     " 10 ",
+			Condition: &Condition{Type: ConditionPercentChange, Operator: OperatorBelow, Threshold: 10},
+		}, {
+			Name:   "Unknown type",
+			Type:   "volume",
+			Option: "above",
+			Value:  "1",
+			Field:  "alertType",
+		}, {
+			Name:   "Crossing percent change",
+			Type:   "percent_change",
+			Option: "crosses_above",
+			Value:  "1",
+			Field:  "alertOption",
+		}, {
+			Name:   "Non numeric value",
+			Type:   "price",
+			Option: "above",
+			Value:  "abc",
+			Field:  "alertValue",
+		}, {
+			Name:   "Negative value",
+			Type:   "price",
+			Option: "below",
+			Value:  "-2",
+			Field:  "alertValue",
+		}, {
+			Name:      "Percent change window moves",
+			Type:      "percent_change_window",
+			Option:    "moves",
+			Value:     "5",
+			Window:    "15m",
+			Condition: &Condition{Type: ConditionPercentChangeWindow, Operator: OperatorMoves, Threshold: 5, Window: 15 * time.Minute},
+		}, {
+			Name:   "Percent change window without window",
+			Type:   "percent_change_window",
+			Option: "above",
+			Value:  "5",
+			Field:  "alertWindow",
+		}, {
+			Name:   "Percent change window with invalid window",
+			Type:   "percent_change_window",
+			Option: "above",
+			Value:  "5",
+			Window: "an hour",
+			Field:  "alertWindow",
+		}, {
+			Name:   "Percent change window too long",
+			Type:   "percent_change_window",
+			Option: "above",
+			Value:  "5",
+			Window: "48h",
+			Field:  "alertWindow",
+		}, {
+			Name:      "Percent change since creation",
+			Type:      "percent_change_since_creation",
+			Option:    "below",
+			Value:     "20",
+			Condition: &Condition{Type: ConditionPercentChangeSinceCreation, Operator: OperatorBelow, Threshold: 20},
+		}, {
+			Name:      "Liquidity below",
+			Type:      "liquidity",
+			Option:    "below",
+			Value:     "100000",
+			Condition: &Condition{Type: ConditionLiquidity, Operator: OperatorBelow, Threshold: 100000},
+		}, {
+			Name:      "Volume spike",
+			Type:      "volume_spike",
+			Option:    "above",
+			Value:     "3",
+			Condition: &Condition{Type: ConditionVolumeSpike, Operator: OperatorAbove, Threshold: 3},
+		}, {
+			Name:   "Volume spike below",
+			Type:   "volume_spike",
+			Option: "below",
+			Value:  "3",
+			Field:  "alertOption",
+		}, {
+			Name:   "Window of price condition",
+			Type:   "price",
+			Option: "above",
+			Value:  "1",
+			Window: "1h",
+			Field:  "alertWindow",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			cond, err := ParseCondition(tc.Type, tc.Option, tc.Value, tc.Window)
+
+			if tc.Field != "" {
+				assert.Nil(t, cond)
+				cErr, ok := err.(*ConditionError)
+				assert.True(t, ok)
+				assert.Equal(t, tc.Field, cErr.Field)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Condition, cond)
+		})
+	}
+}
+
+func TestConditionHolds(t *testing.T) {
+	cases := []struct {
+		Name        string
+		Condition   Condition
+		Observation Observation
+		// expected
+		Holds bool
+	}{
+		{
+			Name:        "Above",
+			Condition:   Condition{Type: ConditionPrice, Operator: OperatorAbove, Threshold: 2},
+			Observation: Observation{Price: 2.1},
+			Holds:       true,
+		}, {
+			Name:        "Not above",
+			Condition:   Condition{Type: ConditionPrice, Operator: OperatorAbove, Threshold: 2},
+			Observation: Observation{Price: 1.9},
+		}, {
+			Name:        "Below",
+			Condition:   Condition{Type: ConditionPrice, Operator: OperatorBelow, Threshold: 2},
+			Observation: Observation{Price: 1.9},
+			Holds:       true,
+		}, {
+			Name:        "Crosses above",
+			Condition:   Condition{Type: ConditionPrice, Operator: OperatorCrossesAbove, Threshold: 2},
+			Observation: Observation{Price: 2.1, Previous: 1.9, HasPrevious: true},
+			Holds:       true,
+		}, {
+			Name:        "Crosses above without previous",
+			Condition:   Condition{Type: ConditionPrice, Operator: OperatorCrossesAbove, Threshold: 2},
+			Observation: Observation{Price: 2.1},
+		}, {
+			Name:        "Already above",
+			Condition:   Condition{Type: ConditionPrice, Operator: OperatorCrossesAbove, Threshold: 2},
+			Observation: Observation{Price: 2.2, Previous: 2.1, HasPrevious: true},
+		}, {
+			Name:        "Crosses below",
+			Condition:   Condition{Type: ConditionPrice, Operator: OperatorCrossesBelow, Threshold: 2},
+			Observation: Observation{Price: 1.9, Previous: 2.1, HasPrevious: true},
+			Holds:       true,
+		}, {
+			Name:        "Percent change above",
+			Condition:   Condition{Type: ConditionPercentChange, Operator: OperatorAbove, Threshold: 10},
+			Observation: Observation{Price: 110, Previous: 100, HasPrevious: true},
+			Holds:       true,
+		}, {
+			Name:        "Percent change not above",
+			Condition:   Condition{Type: ConditionPercentChange, Operator: OperatorAbove, Threshold: 10},
+			Observation: Observation{Price: 105, Previous: 100, HasPrevious: true},
+		}, {
+			Name:        "Percent change below",
+			Condition:   Condition{Type: ConditionPercentChange, Operator: OperatorBelow, Threshold: 10},
+			Observation: Observation{Price: 85, Previous: 100, HasPrevious: true},
+			Holds:       true,
+		}, {
+			Name:        "Percent change without previous",
+			Condition:   Condition{Type: ConditionPercentChange, Operator: OperatorBelow, Threshold: 10},
+			Observation: Observation{Price: 85},
+		}, {
+			Name:        "Window moves up",
+			Condition:   Condition{Type: ConditionPercentChangeWindow, Operator: OperatorMoves, Threshold: 5, Window: time.Hour},
+			Observation: Observation{Price: 106, Baseline: 100, HasBaseline: true},
+			Holds:       true,
+		}, {
+			Name:        "Window moves down",
+			Condition:   Condition{Type: ConditionPercentChangeWindow, Operator: OperatorMoves, Threshold: 5, Window: time.Hour},
+			Observation: Observation{Price: 94, Baseline: 100, HasBaseline: true},
+			Holds:       true,
+		}, {
+			Name:        "Window does not move enough",
+			Condition:   Condition{Type: ConditionPercentChangeWindow, Operator: OperatorMoves, Threshold: 5, Window: time.Hour},
+			Observation: Observation{Price: 104, Baseline: 100, HasBaseline: true, Previous: 90, HasPrevious: true},
+		}, {
+			Name:        "Since creation below",
+			Condition:   Condition{Type: ConditionPercentChangeSinceCreation, Operator: OperatorBelow, Threshold: 20},
+			Observation: Observation{Price: 79, Baseline: 100, HasBaseline: true},
+			Holds:       true,
+		}, {
+			Name:        "Liquidity below",
+			Condition:   Condition{Type: ConditionLiquidity, Operator: OperatorBelow, Threshold: 1000},
+			Observation: Observation{Price: 1, Liquidity: 999, HasLiquidity: true},
+			Holds:       true,
+		}, {
+			Name:        "Liquidity not above",
+			Condition:   Condition{Type: ConditionLiquidity, Operator: OperatorAbove, Threshold: 1000},
+			Observation: Observation{Price: 1, Liquidity: 999, HasLiquidity: true},
+		}, {
+			Name:        "Liquidity without market data",
+			Condition:   Condition{Type: ConditionLiquidity, Operator: OperatorBelow, Threshold: 1000},
+			Observation: Observation{Price: 1},
+		}, {
+			Name:        "Volume spike",
+			Condition:   Condition{Type: ConditionVolumeSpike, Operator: OperatorAbove, Threshold: 3},
+			Observation: Observation{Price: 1, Volume: 3000, AverageVolume: 1000, HasVolume: true},
+			Holds:       true,
+		}, {
+			Name:        "No volume spike",
+			Condition:   Condition{Type: ConditionVolumeSpike, Operator: OperatorAbove, Threshold: 3},
+			Observation: Observation{Price: 1, Volume: 2500, AverageVolume: 1000, HasVolume: true},
+		}, {
+			Name:        "Volume spike without previous volume",
+			Condition:   Condition{Type: ConditionVolumeSpike, Operator: OperatorAbove, Threshold: 3},
+			Observation: Observation{Price: 1, Volume: 2500, HasVolume: true},
+		}, {
+			Name:        "Since creation without baseline",
+			Condition:   Condition{Type: ConditionPercentChangeSinceCreation, Operator: OperatorAbove, Threshold: 20},
+			Observation: Observation{Price: 200, Previous: 100, HasPrevious: true},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, tc.Holds, tc.Condition.Holds(tc.Observation))
+		})
+	}
+}
+
+func TestConditionPastBand(t *testing.T) {
+	cases := []struct {
+		Name        string
+		Condition   Condition
+		Observation Observation
+		Hysteresis  float64
+		// expected
+		PastBand bool
+	}{
+		{
+			Name:        "Above within band",
+			Condition:   Condition{Type: ConditionPrice, Operator: OperatorAbove, Threshold: 100},
+			Observation: Observation{Price: 96},
+			Hysteresis:  5,
+		}, {
+			Name:        "Above past band",
+			Condition:   Condition{Type: ConditionPrice, Operator: OperatorAbove, Threshold: 100},
+			Observation: Observation{Price: 95},
+			Hysteresis:  5,
+			PastBand:    true,
+		}, {
+			Name:        "Crosses below past band",
+			Condition:   Condition{Type: ConditionPrice, Operator: OperatorCrossesBelow, Threshold: 100},
+			Observation: Observation{Price: 106},
+			Hysteresis:  5,
+			PastBand:    true,
+		}, {
+			Name:        "Liquidity below within band",
+			Condition:   Condition{Type: ConditionLiquidity, Operator: OperatorBelow, Threshold: 1000},
+			Observation: Observation{Price: 1, Liquidity: 1040, HasLiquidity: true},
+			Hysteresis:  5,
+		}, {
+			Name:        "Liquidity without market data",
+			Condition:   Condition{Type: ConditionLiquidity, Operator: OperatorAbove, Threshold: 1000},
+			Observation: Observation{Price: 1},
+			Hysteresis:  5,
+		}, {
+			Name:        "Percent change no longer holds",
+			Condition:   Condition{Type: ConditionPercentChange, Operator: OperatorAbove, Threshold: 10},
+			Observation: Observation{Price: 101, Previous: 100, HasPrevious: true},
+			Hysteresis:  5,
+			PastBand:    true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, tc.PastBand, tc.Condition.PastBand(tc.Observation, tc.Hysteresis))
+		})
+	}
+}
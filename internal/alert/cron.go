@@ -1,101 +1,139 @@
 package alert
 
 import (
-	"encoding/json"
-	"fmt"
-	"log"
-	"strconv"
-	"sync"
+	"context"
+	"time"
 
 	alertDB "kek-backend/internal/alert/database"
+	"kek-backend/internal/alert/model"
+	tokenDB "kek-backend/internal/token/database"
+	tokenModel "kek-backend/internal/token/model"
 	"kek-backend/internal/uniswap"
-
-	"github.com/appleboy/go-fcm"
-	"github.com/robfig/cron/v3"
+	"kek-backend/pkg/logging"
 )
 
-func sendMessage(title string, body string, token string) {
-	// Create the message to be sent.
-	msg := &fcm.Message{
-		To: token,
-		Data: map[string]interface{}{
-			"foo": "bar",
-		},
-		Notification: &fcm.Notification{
-			Title: title,
-			Body:  body,
-		},
-	}
-
-	// Create a FCM client to send the message.
-	client, err := fcm.NewClient("AAAAlSnRveU:APA91bF_XWeThMJnZuUGUyQ5wIBBBRyqGfryJ818ItRFUcJg0HubP6ekcNw0FF-ebQMHFZwva2wfEBIViv9qTh7QTeafiyHk8BWPgdE-j3DQEe2orVHpyayxF7DOyOujlarj2_SEhIr_")
-	if err != nil {
-		log.Fatalln(err)
-	}
-
-	// Send the message and receive the response without retries.
-	response, err := client.Send(msg)
-	if err != nil {
-		log.Fatalln(err)
+// handleEvaluation moves given alert through its lifecycle with the evaluation result
+// and enqueues deliveries of the alert to the outbox if the alert is triggered.
+// It returns true if the alert fired.
+//
+//	active -> triggered if the condition holds
+//	triggered -> completed if the alert fires once
+//	triggered -> active if the alert re-arms by its re-arm policy
+func handleEvaluation(ctx context.Context, db alertDB.AlertDB, a *model.Alert, result *Result) bool {
+	logger := logging.FromContext(ctx)
+	holds := result.Holds
+	switch a.AlertStatus {
+	case model.AlertStatusActive:
+		if !holds {
+			return false
+		}
+		// the status change, the event and its deliveries are saved together so that
+		// the outbox worker never misses a notification of a triggered alert
+		err := db.RunInTx(ctx, func(ctx context.Context) error {
+			if err := db.TransitAlertStatus(ctx, a.ID, model.AlertStatusActive, model.AlertStatusTriggered); err != nil {
+				return err
+			}
+			pending := model.DeliveryStatus{}
+			for _, t := range a.AlertActions.Types() {
+				pending[t] = model.DeliveryPending
+			}
+			now := time.Now()
+			event := model.AlertEvent{
+				AlertID:        a.ID,
+				ObservedPrice:  result.Observation.Price,
+				Threshold:      result.Condition.Threshold,
+				DeliveryStatus: pending,
+				FiredAt:        now,
+			}
+			if err := db.SaveAlertEvent(ctx, &event); err != nil {
+				return err
+			}
+			title, body := renderAlert(ctx, a, &TemplateData{
+				Symbol:      result.Symbol,
+				Name:        result.Name,
+				PairAddress: a.PairAddress,
+				PriceUSD:    result.Observation.Price,
+				Threshold:   result.Condition.Threshold,
+				ChangePct:   changePct(result.Observation),
+				FiredAt:     now,
+			})
+			if err := db.SaveDeliveries(ctx, newDeliveries(a, title, body, &event, now)); err != nil {
+				return err
+			}
+			if a.RearmPolicy != model.RearmOnce {
+				return nil
+			}
+			return db.TransitAlertStatus(ctx, a.ID, model.AlertStatusTriggered, model.AlertStatusCompleted)
+		})
+		if err != nil {
+			logger.Errorw("alert.cron failed to trigger alert", "alert", a.Slug, "err", err)
+			return false
+		}
+		return true
+	case model.AlertStatusTriggered:
+		next := model.AlertStatusCompleted
+		if a.RearmPolicy != model.RearmOnce {
+			if !rearmed(a, result) {
+				return false
+			}
+			next = model.AlertStatusActive
+		}
+		if err := db.TransitAlertStatus(ctx, a.ID, model.AlertStatusTriggered, next); err != nil {
+			logger.Errorw("alert.cron failed to move triggered alert", "alert", a.Slug, "to", next, "err", err)
+		}
 	}
-
-	log.Printf("%#v\n", response)
+	return false
 }
 
-func StartCron(db alertDB.AlertDB) {
-	c := cron.New(cron.WithSeconds())
-	c.AddFunc("@every 5s", func() {
-		criteria := alertDB.IterateAlertCriteria{
-			Account: 1,
-			Offset:  0,
-			Limit:   1,
-		}
-		alerts, _, err := db.FindAlertsWithoutContext(criteria)
+// pairAddresses returns distinct normalized addresses of tokens watched by given alerts.
+// Alerts with invalid pair addresses or expressions are skipped.
+func pairAddresses(alerts []*model.Alert) []string {
+	var addresses []string
+	seen := make(map[string]bool)
+	for _, a := range alerts {
+		watched, err := alertAddresses(a)
 		if err != nil {
-			return
+			logging.DefaultLogger().Warnw("alert.cron invalid alert addresses", "alert", a.Slug, "pairAddress", a.PairAddress, "err", err)
+			continue
 		}
-
-		var wg sync.WaitGroup
-		wg.Add(2)
-
-		ch := make(chan int)
-
-		var ethPrice float64
-		var tokenPrice float64
-
-		go func() {
-			c1 := make(chan string, 1)
-			query1 := uniswap.QueryBundles()
-			uniswap.Request(query1, c1)
-
-			msg1 := <-c1
-			var bundles uniswap.Bundles
-			json.Unmarshal([]byte(msg1), &bundles)
-			ethPrice, _ = strconv.ParseFloat(bundles.Data.Bundles[0].EthPrice, 64)
-			ch <- 1
-			wg.Done()
-		}()
-
-		go func() {
-			<-ch
-
-			for _, alert := range alerts {
-				c2 := make(chan string, 1)
-				query2 := uniswap.QueryToken(alert.PairAddress)
-				uniswap.Request(query2, c2)
-
-				msg2 := <-c2
-				var tokens uniswap.Tokens
-				json.Unmarshal([]byte(msg2), &tokens)
-				tokenPrice, _ = strconv.ParseFloat(tokens.Data.Tokens[0].DerivedETH, 64)
-				go sendMessage(alert.Title, alert.Body, alert.Account.Token)
+		for _, address := range watched {
+			if seen[address] {
+				continue
 			}
+			seen[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
 
-			wg.Done()
-		}()
+// alertAddresses returns normalized addresses of tokens watched by given alert
+func alertAddresses(a *model.Alert) ([]string, error) {
+	if IsExpressionAlert(a) {
+		x, err := ParseAlertExpression(a)
+		if err != nil {
+			return nil, err
+		}
+		return x.Addresses(), nil
+	}
+	address, err := uniswap.NormalizeAddress(a.PairAddress)
+	if err != nil {
+		return nil, err
+	}
+	return []string{address}, nil
+}
 
-		wg.Wait()
-		fmt.Println("$$$$$:   ", ethPrice*tokenPrice)
-	})
-	c.Start()
+// recordPrices saves given USD prices keyed by pair address as price snapshots
+func recordPrices(ctx context.Context, db tokenDB.TokenDB, usdPrices map[string]float64, now time.Time) {
+	snapshots := make([]*tokenModel.PriceSnapshot, 0, len(usdPrices))
+	for address, price := range usdPrices {
+		snapshots = append(snapshots, &tokenModel.PriceSnapshot{
+			TokenID:    address,
+			PriceUSD:   price,
+			ObservedAt: now,
+		})
+	}
+	if err := db.SavePriceSnapshots(ctx, snapshots); err != nil {
+		logging.FromContext(ctx).Errorw("alert.cron failed to save price snapshots", "count", len(snapshots), "err", err)
+	}
 }
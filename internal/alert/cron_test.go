@@ -0,0 +1,247 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	alertDBMock "kek-backend/internal/alert/database/mocks"
+	"kek-backend/internal/alert/model"
+	tokenDBMock "kek-backend/internal/token/database/mocks"
+	tokenModel "kek-backend/internal/token/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandleEvaluation_Trigger(t *testing.T) {
+	// given
+	db := newTxAlertDB()
+	alert := newCronAlert(model.AlertStatusActive, model.RearmOnce)
+	db.On("TransitAlertStatus", mock.Anything, alert.ID, model.AlertStatusActive, model.AlertStatusTriggered).Return(nil)
+	db.On("TransitAlertStatus", mock.Anything, alert.ID, model.AlertStatusTriggered, model.AlertStatusCompleted).Return(nil)
+	db.On("SaveAlertEvent", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*model.AlertEvent).ID = 10
+	}).Return(nil)
+	db.On("SaveDeliveries", mock.Anything, mock.Anything).Return(nil)
+
+	// when
+	handleEvaluation(context.Background(), db, alert, newCronResult(2, true))
+
+	// then
+	db.AssertCalled(t, "SaveAlertEvent", mock.Anything, mock.MatchedBy(func(e *model.AlertEvent) bool {
+		return e.AlertID == alert.ID && e.ObservedPrice == 2 && e.Threshold == 1 &&
+			e.DeliveryStatus[model.ChannelPush] == model.DeliveryPending
+	}))
+	db.AssertCalled(t, "SaveDeliveries", mock.Anything, mock.MatchedBy(func(d []*model.Delivery) bool {
+		if len(d) != 1 {
+			return false
+		}
+		p := d[0].Payload
+		return d[0].AlertEventID == 10 && d[0].Channel == model.ChannelPush && d[0].Status == model.DeliveryPending &&
+			p.Token == alert.Account.Token && p.Slug == alert.Slug && p.ObservedPrice == 2
+	}))
+	db.AssertCalled(t, "TransitAlertStatus", mock.Anything, alert.ID, model.AlertStatusTriggered, model.AlertStatusCompleted)
+}
+
+func TestHandleEvaluation_RenderTemplates(t *testing.T) {
+	// given
+	db := newTxAlertDB()
+	alert := newCronAlert(model.AlertStatusActive, model.RearmAuto)
+	alert.Title = "{{symbol}} is above {{threshold}}"
+	alert.Body = "{{symbol}} is now {{price_usd}} ({{change_pct}}%)"
+	db.On("TransitAlertStatus", mock.Anything, alert.ID, model.AlertStatusActive, model.AlertStatusTriggered).Return(nil)
+	db.On("SaveAlertEvent", mock.Anything, mock.Anything).Return(nil)
+	db.On("SaveDeliveries", mock.Anything, mock.Anything).Return(nil)
+	result := newCronResult(2, true)
+	result.Symbol = "DAI"
+	result.Observation.Previous, result.Observation.HasPrevious = 1, true
+
+	// when
+	handleEvaluation(context.Background(), db, alert, result)
+
+	// then
+	db.AssertCalled(t, "SaveDeliveries", mock.Anything, mock.MatchedBy(func(d []*model.Delivery) bool {
+		return len(d) == 1 && d[0].Payload.Title == "DAI is above 1" && d[0].Payload.Body == "DAI is now 2 (100%)"
+	}))
+}
+
+func TestHandleEvaluation_RollbackIfFailedToEnqueue(t *testing.T) {
+	// given
+	db := newTxAlertDB()
+	alert := newCronAlert(model.AlertStatusActive, model.RearmOnce)
+	db.On("TransitAlertStatus", mock.Anything, alert.ID, model.AlertStatusActive, model.AlertStatusTriggered).Return(nil)
+	db.On("SaveAlertEvent", mock.Anything, mock.Anything).Return(nil)
+	db.On("SaveDeliveries", mock.Anything, mock.Anything).Return(errors.New("connection lost"))
+
+	// when
+	handleEvaluation(context.Background(), db, alert, newCronResult(2, true))
+
+	// then
+	db.AssertCalled(t, "RunInTx", mock.Anything, mock.Anything)
+	db.AssertNotCalled(t, "TransitAlertStatus", mock.Anything, alert.ID, model.AlertStatusTriggered, model.AlertStatusCompleted)
+}
+
+func TestHandleEvaluation_Transitions(t *testing.T) {
+	cases := []struct {
+		Name       string
+		Status     string
+		Rearm      string
+		Cooldown   int
+		Hysteresis float64
+		Triggered  time.Duration
+		Price      float64
+		Holds      bool
+		// expected
+		Next string
+	}{
+		{
+			Name:   "Active alert not holds",
+			Status: model.AlertStatusActive,
+			Rearm:  model.RearmOnce,
+			Holds:  false,
+		}, {
+			Name:   "Triggered alert re-arms",
+			Status: model.AlertStatusTriggered,
+			Rearm:  model.RearmAuto,
+			Holds:  false,
+			Next:   model.AlertStatusActive,
+		}, {
+			Name:   "Triggered alert still holds",
+			Status: model.AlertStatusTriggered,
+			Rearm:  model.RearmAuto,
+			Holds:  true,
+		}, {
+			Name:   "Triggered alert completes",
+			Status: model.AlertStatusTriggered,
+			Rearm:  model.RearmOnce,
+			Holds:  true,
+			Next:   model.AlertStatusCompleted,
+		}, {
+			Name:      "Triggered alert in cooldown",
+			Status:    model.AlertStatusTriggered,
+			Rearm:     model.RearmCooldown,
+			Cooldown:  30,
+			Triggered: 10 * time.Minute,
+			Holds:     true,
+		}, {
+			Name:      "Triggered alert re-arms after cooldown",
+			Status:    model.AlertStatusTriggered,
+			Rearm:     model.RearmCooldown,
+			Cooldown:  30,
+			Triggered: 40 * time.Minute,
+			Holds:     true,
+			Next:      model.AlertStatusActive,
+		}, {
+			Name:       "Triggered alert within band",
+			Status:     model.AlertStatusTriggered,
+			Rearm:      model.RearmBand,
+			Hysteresis: 10,
+			Price:      0.95,
+			Holds:      false,
+		}, {
+			Name:       "Triggered alert re-arms past band",
+			Status:     model.AlertStatusTriggered,
+			Rearm:      model.RearmBand,
+			Hysteresis: 10,
+			Price:      0.9,
+			Holds:      false,
+			Next:       model.AlertStatusActive,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			db := &alertDBMock.AlertDB{}
+			now := time.Now()
+			alert := newCronAlert(tc.Status, tc.Rearm)
+			alert.CooldownMinutes = tc.Cooldown
+			alert.Hysteresis = tc.Hysteresis
+			if tc.Triggered != 0 {
+				triggeredAt := now.Add(-tc.Triggered)
+				alert.LastTriggeredAt = &triggeredAt
+			}
+			price := tc.Price
+			if price == 0 {
+				price = 2
+			}
+			result := newCronResult(price, tc.Holds)
+			result.ObservedAt = now
+			db.On("TransitAlertStatus", mock.Anything, alert.ID, tc.Status, mock.Anything).Return(nil)
+
+			handleEvaluation(context.Background(), db, alert, result)
+
+			if tc.Next == "" {
+				db.AssertNotCalled(t, "TransitAlertStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				db.AssertCalled(t, "TransitAlertStatus", mock.Anything, alert.ID, tc.Status, tc.Next)
+			}
+			db.AssertNotCalled(t, "SaveDeliveries", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestPairAddresses(t *testing.T) {
+	alerts := []*model.Alert{
+		{Slug: "a", PairAddress: "0x6b175474e89094c44da98b954eedeac495271d0f"},
+		{Slug: "b", PairAddress: "0x6B175474E89094C44Da98b954EedeAC495271d0F"},
+		{Slug: "c", PairAddress: "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"},
+		{Slug: "d", PairAddress: "invalid"},
+		{Slug: "e", PairAddress: "0x6b175474e89094c44da98b954eedeac495271d0f", AlertType: "expression", AlertExpression: &model.Expression{
+			Op: "and",
+			Conditions: []*model.Expression{
+				{Type: "price", Option: "above", Threshold: "1"},
+				{Type: "ratio", Option: "above", Threshold: "1", QuoteAddress: "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2"},
+			},
+		}},
+	}
+
+	addresses := pairAddresses(alerts)
+
+	assert.Equal(t, []string{
+		"0x6b175474e89094c44da98b954eedeac495271d0f",
+		"0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48",
+		"0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2",
+	}, addresses)
+}
+
+func TestRecordPrices(t *testing.T) {
+	now := time.Now()
+	db := &tokenDBMock.TokenDB{}
+	db.On("SavePriceSnapshots", mock.Anything, mock.Anything).Return(nil)
+
+	recordPrices(context.Background(), db, map[string]float64{
+		"0x6b175474e89094c44da98b954eedeac495271d0f": 1.01,
+	}, now)
+
+	db.AssertCalled(t, "SavePriceSnapshots", mock.Anything, []*tokenModel.PriceSnapshot{
+		{TokenID: "0x6b175474e89094c44da98b954eedeac495271d0f", PriceUSD: 1.01, ObservedAt: now},
+	})
+}
+
+// newTxAlertDB returns a mock AlertDB whose RunInTx invokes given function
+func newTxAlertDB() *alertDBMock.AlertDB {
+	db := &alertDBMock.AlertDB{}
+	db.On("RunInTx", mock.Anything, mock.Anything).Return(func(ctx context.Context, f func(context.Context) error) error {
+		return f(ctx)
+	})
+	return db
+}
+
+func newCronAlert(status, rearm string) *model.Alert {
+	alert := dAlert
+	alert.AlertStatus = status
+	alert.RearmPolicy = rearm
+	alert.Account = dUser
+	alert.Account.Token = "device-token"
+	return &alert
+}
+
+func newCronResult(price float64, holds bool) *Result {
+	return &Result{
+		Condition:   &Condition{Type: ConditionPrice, Operator: OperatorAbove, Threshold: 1},
+		Observation: Observation{Price: price},
+		Observed:    true,
+		Holds:       holds,
+	}
+}
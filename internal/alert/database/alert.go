@@ -12,10 +12,14 @@ import (
 	"gorm.io/gorm"
 )
 
+// ErrInvalidStatusTransition is returned if an alert is not allowed to move to a requested status
+var ErrInvalidStatusTransition = errors.New("invalid alert status transition")
+
 type IterateAlertCriteria struct {
-	Account uint
-	Offset  uint
-	Limit   uint
+	Account  uint
+	Statuses []string
+	Offset   uint
+	Limit    uint
 }
 
 //go:generate mockery --name AlertDB --filename alert_mock.go
@@ -32,12 +36,101 @@ type AlertDB interface {
 	// FindAlerts returns alert list with given criteria and total count
 	FindAlerts(ctx context.Context, criteria IterateAlertCriteria) ([]*model.Alert, int64, error)
 
-	// FindAlertsWithoutContext returns alert list with given criteria and total count
-	FindAlertsWithoutContext(criteria IterateAlertCriteria) ([]*model.Alert, int64, error)
+	// FindAlertsAfter returns at most limit alerts in given statuses of all accounts
+	// whose id is greater than given id in ascending order of id
+	FindAlertsAfter(ctx context.Context, statuses []string, afterID, limit uint) ([]*model.Alert, error)
+
+	// UpdateAlert updates slug, title, body, condition value and option, expiration time and actions
+	// of given alert owned by given alert's account. The last observation is not changed
+	// since it may have been updated by the scheduler after the alert was read
+	// database.ErrNotFound error is returned if not exist and
+	// database.ErrKeyConflict error is returned if the slug is duplicated
+	UpdateAlert(ctx context.Context, alert *model.Alert) error
 
 	// DeleteAlertBySlug deletes a alert with given slug
 	// and returns nil if success to delete, otherwise returns an error
 	DeleteAlertBySlug(ctx context.Context, accountId uint, slug string) error
+
+	// TransitAlertStatus moves a alert with given id from given status to given status
+	// ErrInvalidStatusTransition error is returned if the transition is not allowed and
+	// database.ErrNotFound error is returned if the alert does not exist in given status
+	TransitAlertStatus(ctx context.Context, id uint, from, to string) error
+
+	// SnoozeAlert moves a alert with given id from given status to snoozed status until given time.
+	// A snoozed alert is snoozed again with the new time.
+	// ErrInvalidStatusTransition error is returned if the transition is not allowed and
+	// database.ErrNotFound error is returned if the alert does not exist in given status
+	SnoozeAlert(ctx context.Context, id uint, from string, until time.Time) error
+
+	// ResumeSnoozedAlerts moves snoozed alerts whose snooze ended at given time to active status
+	// and returns the number of resumed alerts
+	ResumeSnoozedAlerts(ctx context.Context, now time.Time) (int64, error)
+
+	// PauseAccountAlerts moves active, triggered and snoozed alerts of given account to paused status
+	// and returns the number of paused alerts
+	PauseAccountAlerts(ctx context.Context, accountId uint) (int64, error)
+
+	// ResumeAccountAlerts moves paused alerts of given account to active status
+	// and returns the number of resumed alerts
+	ResumeAccountAlerts(ctx context.Context, accountId uint) (int64, error)
+
+	// UpdateAlertObservation saves the price observed for a alert with given id at given time
+	// database.ErrNotFound error is returned if not exist
+	UpdateAlertObservation(ctx context.Context, id uint, price float64, at time.Time) error
+
+	// ClearAlertObservation clears the last observation of a alert with given id
+	// database.ErrNotFound error is returned if not exist
+	ClearAlertObservation(ctx context.Context, id uint) error
+
+	// ExpireAlerts moves alerts whose expiration time passed given time to expired status
+	// and returns the number of expired alerts
+	ExpireAlerts(ctx context.Context, now time.Time) (int64, error)
+
+	// SaveAlertEvent saves a given alert event
+	SaveAlertEvent(ctx context.Context, event *model.AlertEvent) error
+
+	// UpdateAlertEventDelivery updates a delivery status of given channel in a alert event with given id
+	// database.ErrNotFound error is returned if not exist
+	UpdateAlertEventDelivery(ctx context.Context, id uint, channel, status string) error
+
+	// CountTestAlertEvents returns the number of test events of alerts owned by given account
+	// fired since given time
+	CountTestAlertEvents(ctx context.Context, accountId uint, since time.Time) (int64, error)
+
+	// LockAccount locks the row of given account until the transaction of given context ends
+	// so that checks of per-account limits are serialized
+	// database.ErrNotFound error is returned if not exist
+	LockAccount(ctx context.Context, accountId uint) error
+
+	// FindAlertEvents returns alert event list with given criteria and total count
+	FindAlertEvents(ctx context.Context, criteria IterateAlertEventCriteria) ([]*model.AlertEvent, int64, error)
+
+	// SaveDeliveries saves given deliveries to the notification outbox
+	SaveDeliveries(ctx context.Context, deliveries []*model.Delivery) error
+
+	// FindDueDeliveries returns pending deliveries whose next attempt time is before given time
+	FindDueDeliveries(ctx context.Context, now time.Time, limit uint) ([]*model.Delivery, error)
+
+	// CountDueDeliveries returns the number of pending deliveries whose next attempt time is before given time
+	CountDueDeliveries(ctx context.Context, now time.Time) (int64, error)
+
+	// UpdateDelivery updates status, attempts, next attempt time and last error of given delivery
+	// database.ErrNotFound error is returned if not exist
+	UpdateDelivery(ctx context.Context, delivery *model.Delivery) error
+
+	// FindDeliveries returns delivery list with given criteria and total count
+	FindDeliveries(ctx context.Context, criteria IterateDeliveryCriteria) ([]*model.Delivery, int64, error)
+
+	// AcquireLease acquires or renews a lease with given name for given holder until ttl after given time
+	// and returns false if the lease is held by another holder
+	AcquireLease(ctx context.Context, name, holder string, now time.Time, ttl time.Duration) (bool, error)
+
+	// ReleaseLease releases a lease with given name if held by given holder
+	ReleaseLease(ctx context.Context, name, holder string) error
+
+	// ReplayDelivery resets a dead or pending delivery with given id to be sent at given time
+	// database.ErrNotFound error is returned if not exist or already sent
+	ReplayDelivery(ctx context.Context, id uint, now time.Time) error
 }
 
 type alertDB struct {
@@ -110,6 +203,9 @@ func (a *alertDB) FindAlerts(ctx context.Context, criteria IterateAlertCriteria)
 	if criteria.Account != 0 {
 		chain = chain.Where("au.id = ?", criteria.Account).Joins("LEFT JOIN accounts au on au.id = a.account_id")
 	}
+	if len(criteria.Statuses) != 0 {
+		chain = chain.Where("a.alert_status IN (?)", criteria.Statuses)
+	}
 
 	// get total count
 	var totalCount int64
@@ -156,53 +252,54 @@ func (a *alertDB) FindAlerts(ctx context.Context, criteria IterateAlertCriteria)
 	return ret, totalCount, nil
 }
 
-func (a *alertDB) FindAlertsWithoutContext(criteria IterateAlertCriteria) ([]*model.Alert, int64, error) {
-	db := a.db
-
-	chain := db.Table("alerts a").Where("deleted_at_unix = 0")
-	if criteria.Account != 0 {
-		chain = chain.Where("au.id = ?", criteria.Account).Joins("LEFT JOIN accounts au on au.id = a.account_id")
-	}
+func (a *alertDB) FindAlertsAfter(ctx context.Context, statuses []string, afterID, limit uint) ([]*model.Alert, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.FindAlertsAfter", "statuses", statuses, "afterID", afterID, "limit", limit)
 
-	// get total count
-	var totalCount int64
-	err := chain.Distinct("a.id").Count(&totalCount).Error
+	ret := []*model.Alert{}
+	err := db.WithContext(ctx).Joins("Account").
+		Where("alerts.deleted_at_unix = 0 AND alerts.id > ?", afterID).
+		Where("alerts.alert_status IN (?)", statuses).
+		Order("alerts.id ASC").
+		Limit(int(limit)).
+		Find(&ret).Error
 	if err != nil {
+		logger.Errorw("alert.db.FindAlertsAfter failed to find alerts", "err", err)
+		return nil, err
 	}
+	return ret, nil
+}
 
-	// get alert ids
-	rows, err := chain.Select("(a.id) id").
-		Offset(int(criteria.Offset)).
-		Limit(int(criteria.Limit)).
-		Order("a.id DESC").
-		Rows()
-	if err != nil {
-		return nil, 0, err
-	}
-	var ids []uint
-	for rows.Next() {
-		var id uint
-		err := rows.Scan(&id)
-		if err != nil {
-			return nil, 0, err
-		}
-		ids = append(ids, id)
-	}
+func (a *alertDB) UpdateAlert(ctx context.Context, alert *model.Alert) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.UpdateAlert", "alert", alert)
 
-	// get alerts with account by ids
-	var ret []*model.Alert
-	if len(ids) == 0 {
-		return []*model.Alert{}, totalCount, nil
+	chain := db.WithContext(ctx).Model(&model.Alert{}).
+		Where("id = ? AND deleted_at_unix = 0", alert.ID).
+		Where("account_id = ?", alert.AccountId).
+		Updates(map[string]interface{}{
+			"slug":            alert.Slug,
+			"title":           alert.Title,
+			"body":            alert.Body,
+			"alert_value":     alert.AlertValue,
+			"alert_option":    alert.AlertOption,
+			"expiration_time": alert.ExpirationTime,
+			"alert_actions":   alert.AlertActions,
+		})
+	if chain.Error != nil {
+		logger.Errorw("alert.db.UpdateAlert failed to update alert", "err", chain.Error)
+		if database.IsKeyConflictErr(chain.Error) {
+			return database.ErrKeyConflict
+		}
+		return chain.Error
 	}
-	err = db.Joins("Account").
-		Where("alerts.id IN (?)", ids).
-		Order("alerts.id DESC").
-		Find(&ret).Error
-	if err != nil {
-		return nil, 0, err
+	if chain.RowsAffected == 0 {
+		logger.Error("failed to update an alert because not found")
+		return database.ErrNotFound
 	}
-
-	return ret, totalCount, nil
+	return nil
 }
 
 func (a *alertDB) DeleteAlertBySlug(ctx context.Context, accountId uint, slug string) error {
@@ -226,6 +323,195 @@ func (a *alertDB) DeleteAlertBySlug(ctx context.Context, accountId uint, slug st
 	return nil
 }
 
+func (a *alertDB) TransitAlertStatus(ctx context.Context, id uint, from, to string) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.TransitAlertStatus", "id", id, "from", from, "to", to)
+
+	if !model.CanTransitAlertStatus(from, to) {
+		logger.Errorw("alert.db.TransitAlertStatus not allowed transition", "from", from, "to", to)
+		return ErrInvalidStatusTransition
+	}
+
+	now := time.Now()
+	fields := map[string]interface{}{
+		"alert_status":      to,
+		"status_changed_at": now,
+	}
+	if to == model.AlertStatusTriggered {
+		fields["last_triggered_at"] = now
+	}
+	if from == model.AlertStatusSnoozed {
+		fields["snoozed_until"] = nil
+	}
+	chain := db.WithContext(ctx).Model(&model.Alert{}).
+		Where("id = ? AND alert_status = ? AND deleted_at_unix = 0", id, from).
+		Updates(fields)
+	if chain.Error != nil {
+		logger.Errorw("failed to transit an alert status", "err", chain.Error)
+		return chain.Error
+	}
+	if chain.RowsAffected == 0 {
+		logger.Error("failed to transit an alert status because not found")
+		return database.ErrNotFound
+	}
+	return nil
+}
+
+func (a *alertDB) SnoozeAlert(ctx context.Context, id uint, from string, until time.Time) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.SnoozeAlert", "id", id, "from", from, "until", until)
+
+	if from != model.AlertStatusSnoozed && !model.CanTransitAlertStatus(from, model.AlertStatusSnoozed) {
+		logger.Errorw("alert.db.SnoozeAlert not allowed transition", "from", from)
+		return ErrInvalidStatusTransition
+	}
+
+	chain := db.WithContext(ctx).Model(&model.Alert{}).
+		Where("id = ? AND alert_status = ? AND deleted_at_unix = 0", id, from).
+		Updates(map[string]interface{}{
+			"alert_status":      model.AlertStatusSnoozed,
+			"status_changed_at": time.Now(),
+			"snoozed_until":     until,
+		})
+	if chain.Error != nil {
+		logger.Errorw("failed to snooze an alert", "err", chain.Error)
+		return chain.Error
+	}
+	if chain.RowsAffected == 0 {
+		logger.Error("failed to snooze an alert because not found")
+		return database.ErrNotFound
+	}
+	return nil
+}
+
+func (a *alertDB) ResumeSnoozedAlerts(ctx context.Context, now time.Time) (int64, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.ResumeSnoozedAlerts", "now", now)
+
+	chain := db.WithContext(ctx).Model(&model.Alert{}).
+		Where("deleted_at_unix = 0 AND alert_status = ?", model.AlertStatusSnoozed).
+		Where("snoozed_until <= ?", now).
+		Updates(map[string]interface{}{
+			"alert_status":      model.AlertStatusActive,
+			"status_changed_at": now,
+			"snoozed_until":     nil,
+		})
+	if chain.Error != nil {
+		logger.Errorw("failed to resume snoozed alerts", "err", chain.Error)
+		return 0, chain.Error
+	}
+	return chain.RowsAffected, nil
+}
+
+func (a *alertDB) PauseAccountAlerts(ctx context.Context, accountId uint) (int64, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.PauseAccountAlerts", "accountId", accountId)
+
+	chain := db.WithContext(ctx).Model(&model.Alert{}).
+		Where("deleted_at_unix = 0 AND alert_status IN (?)", []string{
+			model.AlertStatusActive, model.AlertStatusTriggered, model.AlertStatusSnoozed,
+		}).
+		Where("account_id = ?", accountId).
+		Updates(map[string]interface{}{
+			"alert_status":      model.AlertStatusPaused,
+			"status_changed_at": time.Now(),
+			"snoozed_until":     nil,
+		})
+	if chain.Error != nil {
+		logger.Errorw("failed to pause account alerts", "err", chain.Error)
+		return 0, chain.Error
+	}
+	return chain.RowsAffected, nil
+}
+
+func (a *alertDB) ResumeAccountAlerts(ctx context.Context, accountId uint) (int64, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.ResumeAccountAlerts", "accountId", accountId)
+
+	chain := db.WithContext(ctx).Model(&model.Alert{}).
+		Where("deleted_at_unix = 0 AND alert_status = ?", model.AlertStatusPaused).
+		Where("account_id = ?", accountId).
+		Updates(map[string]interface{}{
+			"alert_status":      model.AlertStatusActive,
+			"status_changed_at": time.Now(),
+		})
+	if chain.Error != nil {
+		logger.Errorw("failed to resume account alerts", "err", chain.Error)
+		return 0, chain.Error
+	}
+	return chain.RowsAffected, nil
+}
+
+func (a *alertDB) UpdateAlertObservation(ctx context.Context, id uint, price float64, at time.Time) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.UpdateAlertObservation", "id", id, "price", price, "at", at)
+
+	// updated_at is not changed since the observation is not a change by the owner
+	chain := db.WithContext(ctx).Model(&model.Alert{}).
+		Where("id = ? AND deleted_at_unix = 0", id).
+		UpdateColumns(map[string]interface{}{
+			"last_observed_price": price,
+			"last_observed_at":    at,
+		})
+	if chain.Error != nil {
+		logger.Errorw("alert.db.UpdateAlertObservation failed to update alert", "err", chain.Error)
+		return chain.Error
+	}
+	if chain.RowsAffected == 0 {
+		return database.ErrNotFound
+	}
+	return nil
+}
+
+func (a *alertDB) ClearAlertObservation(ctx context.Context, id uint) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.ClearAlertObservation", "id", id)
+
+	chain := db.WithContext(ctx).Model(&model.Alert{}).
+		Where("id = ? AND deleted_at_unix = 0", id).
+		UpdateColumns(map[string]interface{}{
+			"last_observed_price": nil,
+			"last_observed_at":    nil,
+		})
+	if chain.Error != nil {
+		logger.Errorw("alert.db.ClearAlertObservation failed to update alert", "err", chain.Error)
+		return chain.Error
+	}
+	if chain.RowsAffected == 0 {
+		return database.ErrNotFound
+	}
+	return nil
+}
+
+func (a *alertDB) ExpireAlerts(ctx context.Context, now time.Time) (int64, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.ExpireAlerts", "now", now)
+
+	chain := db.WithContext(ctx).Model(&model.Alert{}).
+		Where("deleted_at_unix = 0 AND alert_status IN (?)", []string{
+			model.AlertStatusActive, model.AlertStatusTriggered, model.AlertStatusPaused, model.AlertStatusSnoozed,
+		}).
+		Where("expiration_time IS NOT NULL AND expiration_time <= ?", now).
+		Updates(map[string]interface{}{
+			"alert_status":      model.AlertStatusExpired,
+			"status_changed_at": now,
+			"snoozed_until":     nil,
+		})
+	if chain.Error != nil {
+		logger.Errorw("failed to expire alerts", "err", chain.Error)
+		return 0, chain.Error
+	}
+	return chain.RowsAffected, nil
+}
+
 // NewAlertDB creates a new alert db with given db
 func NewAlertDB(db *gorm.DB) AlertDB {
 	return &alertDB{
@@ -40,7 +40,9 @@ func (s *DBSuite) SetupSuite() {
 
 func (s *DBSuite) SetupTest() {
 	s.NoError(database.DeleteRecordAll(s.T(), s.originDB, []string{
-		"comments", "id > 0",
+		"scheduler_leases", "name != ''",
+		"notification_outbox", "id > 0",
+		"alert_events", "id > 0",
 		"alerts", "id > 0",
 		"accounts", "id > 0",
 	}))
@@ -172,7 +174,7 @@ func (s *DBSuite) TestFindAlerts() {
 	s.NoError(s.db.SaveAlert(nil, alert7))
 
 	criteria := IterateAlertCriteria{
-		Account: user1.Username,
+		Account: user1.ID,
 		Offset:  0,
 		Limit:   2,
 	}
@@ -198,6 +200,102 @@ func (s *DBSuite) TestFindAlerts() {
 	s.assertAlert(alert1, results[0])
 }
 
+func (s *DBSuite) TestFindAlertsAfter() {
+	// given
+	// User1
+	// alert1 - active    <- first page [0]
+	// alert2 - paused
+	// alert3 - active    <- first page [1]
+	// alert4 - active (deleted)
+	// User2
+	// alert5 - triggered <- second page [0]
+	user2 := accountModel.Account{Username: "test-user2", Email: "test-user2@gmail.com", Password: "password"}
+	s.NoError(s.accountDB.Save(nil, &user2))
+	alert1 := newAlert("alert1", "alert1", "body1", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert1))
+	alert2 := newAlert("alert2", "alert2", "body2", dUser)
+	alert2.AlertStatus = model.AlertStatusPaused
+	s.NoError(s.db.SaveAlert(nil, alert2))
+	alert3 := newAlert("alert3", "alert3", "body3", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert3))
+	alert4 := newAlert("alert4", "alert4", "body4", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert4))
+	s.NoError(s.db.DeleteAlertBySlug(nil, dUser.ID, alert4.Slug))
+	alert5 := newAlert("alert5", "alert5", "body5", user2)
+	alert5.AlertStatus = model.AlertStatusTriggered
+	s.NoError(s.db.SaveAlert(nil, alert5))
+	statuses := []string{model.AlertStatusActive, model.AlertStatusTriggered}
+
+	// when
+	first, err := s.db.FindAlertsAfter(nil, statuses, 0, 2)
+	s.NoError(err)
+	second, err := s.db.FindAlertsAfter(nil, statuses, first[len(first)-1].ID, 2)
+	s.NoError(err)
+
+	// then
+	s.Equal(2, len(first))
+	s.assertAlert(alert1, first[0])
+	s.assertAlert(alert3, first[1])
+	s.Equal(1, len(second))
+	s.assertAlert(alert5, second[0])
+}
+
+func (s *DBSuite) TestUpdateAlert() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+	s.NoError(s.db.UpdateAlertObservation(nil, alert.ID, 1.5, time.Now()))
+
+	// when
+	alert.Slug = "title2"
+	alert.Title = "title2"
+	alert.Body = "body2"
+	alert.AlertValue = "2"
+	alert.AlertActions = model.AlertActions{{Type: model.ChannelPush}}
+	err := s.db.UpdateAlert(nil, alert)
+
+	// then
+	s.NoError(err)
+	find, err := s.db.FindAlertBySlug(nil, "title2")
+	s.NoError(err)
+	s.Equal("title2", find.Title)
+	s.Equal("body2", find.Body)
+	s.Equal("2", find.AlertValue)
+	s.Equal(alert.AlertActions, find.AlertActions)
+	// the observation made by the scheduler is kept
+	s.NotNil(find.LastObservedPrice)
+	s.Equal(1.5, *find.LastObservedPrice)
+	_, err = s.db.FindAlertBySlug(nil, "title1")
+	s.Equal(database.ErrNotFound, err)
+}
+
+func (s *DBSuite) TestUpdateAlert_FailIfDuplicateSlug() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+	s.NoError(s.db.SaveAlert(nil, newAlert("title2", "title2", "body", dUser)))
+
+	// when
+	alert.Slug = "title2"
+	err := s.db.UpdateAlert(nil, alert)
+
+	// then
+	s.Equal(database.ErrKeyConflict, err)
+}
+
+func (s *DBSuite) TestUpdateAlert_FailIfNotOwner() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+
+	// when
+	alert.AccountId = dUser.ID + 1000
+	err := s.db.UpdateAlert(nil, alert)
+
+	// then
+	s.Equal(database.ErrNotFound, err)
+}
+
 func (s *DBSuite) TestDeleteAlertBySlug() {
 	// given
 	alert := newAlert("title1", "title1", "body", dUser)
@@ -247,6 +345,225 @@ func (s *DBSuite) TestDeleteAlertBySlug_FailIfNotExist() {
 	}
 }
 
+func (s *DBSuite) TestTransitAlertStatus() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+
+	// when
+	now := time.Now()
+	err := s.db.TransitAlertStatus(nil, alert.ID, model.AlertStatusActive, model.AlertStatusTriggered)
+
+	// then
+	s.NoError(err)
+	find, err := s.db.FindAlertBySlug(nil, alert.Slug)
+	s.NoError(err)
+	s.Equal(model.AlertStatusTriggered, find.AlertStatus)
+	s.NotNil(find.StatusChangedAt)
+	s.WithinDuration(now, *find.StatusChangedAt, time.Second)
+	s.NotNil(find.LastTriggeredAt)
+	s.WithinDuration(now, *find.LastTriggeredAt, time.Second)
+}
+
+func (s *DBSuite) TestTransitAlertStatus_FailIfNotAllowed() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+	s.NoError(s.db.TransitAlertStatus(nil, alert.ID, model.AlertStatusActive, model.AlertStatusTriggered))
+	s.NoError(s.db.TransitAlertStatus(nil, alert.ID, model.AlertStatusTriggered, model.AlertStatusCompleted))
+
+	// when
+	err := s.db.TransitAlertStatus(nil, alert.ID, model.AlertStatusCompleted, model.AlertStatusPaused)
+
+	// then
+	s.Equal(ErrInvalidStatusTransition, err)
+}
+
+func (s *DBSuite) TestTransitAlertStatus_FailIfStatusChanged() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+	s.NoError(s.db.TransitAlertStatus(nil, alert.ID, model.AlertStatusActive, model.AlertStatusPaused))
+
+	// when
+	err := s.db.TransitAlertStatus(nil, alert.ID, model.AlertStatusActive, model.AlertStatusTriggered)
+
+	// then
+	s.Equal(database.ErrNotFound, err)
+}
+
+func (s *DBSuite) TestUpdateAlertObservation() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+	now := time.Now()
+
+	// when
+	err := s.db.UpdateAlertObservation(nil, alert.ID, 1.5, now)
+
+	// then
+	s.NoError(err)
+	find, err := s.db.FindAlertBySlug(nil, alert.Slug)
+	s.NoError(err)
+	s.NotNil(find.LastObservedPrice)
+	s.Equal(1.5, *find.LastObservedPrice)
+	s.NotNil(find.LastObservedAt)
+	s.WithinDuration(now, *find.LastObservedAt, time.Second)
+}
+
+func (s *DBSuite) TestClearAlertObservation() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+	s.NoError(s.db.UpdateAlertObservation(nil, alert.ID, 1.5, time.Now()))
+
+	// when
+	err := s.db.ClearAlertObservation(nil, alert.ID)
+
+	// then
+	s.NoError(err)
+	find, err := s.db.FindAlertBySlug(nil, alert.Slug)
+	s.NoError(err)
+	s.Nil(find.LastObservedPrice)
+	s.Nil(find.LastObservedAt)
+	s.Equal(database.ErrNotFound, s.db.ClearAlertObservation(nil, 0))
+}
+
+func (s *DBSuite) TestUpdateAlertObservation_FailIfNotExist() {
+	// when
+	err := s.db.UpdateAlertObservation(nil, 0, 1.5, time.Now())
+
+	// then
+	s.Equal(database.ErrNotFound, err)
+}
+
+func (s *DBSuite) TestExpireAlerts() {
+	// given
+	now := time.Now()
+	alert1 := newAlert("alert1", "alert1", "body1", dUser)
+	alert1.ExpirationTime = now.Add(-time.Minute)
+	s.NoError(s.db.SaveAlert(nil, alert1))
+	alert2 := newAlert("alert2", "alert2", "body2", dUser)
+	alert2.ExpirationTime = now.Add(time.Hour)
+	s.NoError(s.db.SaveAlert(nil, alert2))
+
+	// when
+	expired, err := s.db.ExpireAlerts(nil, now)
+
+	// then
+	s.NoError(err)
+	s.Equal(int64(1), expired)
+	find, err := s.db.FindAlertBySlug(nil, alert1.Slug)
+	s.NoError(err)
+	s.Equal(model.AlertStatusExpired, find.AlertStatus)
+	find, err = s.db.FindAlertBySlug(nil, alert2.Slug)
+	s.NoError(err)
+	s.Equal(model.AlertStatusActive, find.AlertStatus)
+}
+
+func (s *DBSuite) TestSnoozeAlert() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+	until := time.Now().Add(time.Hour)
+
+	// when
+	err := s.db.SnoozeAlert(nil, alert.ID, model.AlertStatusActive, until)
+
+	// then
+	s.NoError(err)
+	find, err := s.db.FindAlertBySlug(nil, alert.Slug)
+	s.NoError(err)
+	s.Equal(model.AlertStatusSnoozed, find.AlertStatus)
+	s.NotNil(find.SnoozedUntil)
+	s.WithinDuration(until, *find.SnoozedUntil, time.Second)
+
+	// when snoozed again
+	until = until.Add(time.Hour)
+	s.NoError(s.db.SnoozeAlert(nil, alert.ID, model.AlertStatusSnoozed, until))
+	find, err = s.db.FindAlertBySlug(nil, alert.Slug)
+	s.NoError(err)
+	s.WithinDuration(until, *find.SnoozedUntil, time.Second)
+
+	// when resumed
+	s.NoError(s.db.TransitAlertStatus(nil, alert.ID, model.AlertStatusSnoozed, model.AlertStatusActive))
+	find, err = s.db.FindAlertBySlug(nil, alert.Slug)
+	s.NoError(err)
+	s.Nil(find.SnoozedUntil)
+}
+
+func (s *DBSuite) TestSnoozeAlert_FailIfNotAllowed() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+	s.NoError(s.db.TransitAlertStatus(nil, alert.ID, model.AlertStatusActive, model.AlertStatusPaused))
+
+	// when
+	err := s.db.SnoozeAlert(nil, alert.ID, model.AlertStatusPaused, time.Now().Add(time.Hour))
+
+	// then
+	s.Equal(ErrInvalidStatusTransition, err)
+}
+
+func (s *DBSuite) TestResumeSnoozedAlerts() {
+	// given
+	now := time.Now()
+	alert1 := newAlert("alert1", "alert1", "body1", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert1))
+	s.NoError(s.db.SnoozeAlert(nil, alert1.ID, model.AlertStatusActive, now.Add(-time.Minute)))
+	alert2 := newAlert("alert2", "alert2", "body2", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert2))
+	s.NoError(s.db.SnoozeAlert(nil, alert2.ID, model.AlertStatusActive, now.Add(time.Hour)))
+
+	// when
+	resumed, err := s.db.ResumeSnoozedAlerts(nil, now)
+
+	// then
+	s.NoError(err)
+	s.Equal(int64(1), resumed)
+	find, err := s.db.FindAlertBySlug(nil, alert1.Slug)
+	s.NoError(err)
+	s.Equal(model.AlertStatusActive, find.AlertStatus)
+	s.Nil(find.SnoozedUntil)
+	find, err = s.db.FindAlertBySlug(nil, alert2.Slug)
+	s.NoError(err)
+	s.Equal(model.AlertStatusSnoozed, find.AlertStatus)
+}
+
+func (s *DBSuite) TestPauseAndResumeAccountAlerts() {
+	// given
+	alert1 := newAlert("alert1", "alert1", "body1", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert1))
+	alert2 := newAlert("alert2", "alert2", "body2", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert2))
+	s.NoError(s.db.SnoozeAlert(nil, alert2.ID, model.AlertStatusActive, time.Now().Add(time.Hour)))
+	alert3 := newAlert("alert3", "alert3", "body3", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert3))
+	s.NoError(s.db.TransitAlertStatus(nil, alert3.ID, model.AlertStatusActive, model.AlertStatusTriggered))
+	s.NoError(s.db.TransitAlertStatus(nil, alert3.ID, model.AlertStatusTriggered, model.AlertStatusCompleted))
+
+	// when
+	paused, err := s.db.PauseAccountAlerts(nil, dUser.ID)
+
+	// then
+	s.NoError(err)
+	s.Equal(int64(2), paused)
+	find, err := s.db.FindAlertBySlug(nil, alert2.Slug)
+	s.NoError(err)
+	s.Equal(model.AlertStatusPaused, find.AlertStatus)
+	s.Nil(find.SnoozedUntil)
+
+	// when
+	resumed, err := s.db.ResumeAccountAlerts(nil, dUser.ID)
+
+	// then
+	s.NoError(err)
+	s.Equal(int64(2), resumed)
+	find, err = s.db.FindAlertBySlug(nil, alert3.Slug)
+	s.NoError(err)
+	s.Equal(model.AlertStatusCompleted, find.AlertStatus)
+}
+
 func (s *DBSuite) assertAlert(expected, actual *model.Alert) {
 	s.Equal(expected.Slug, actual.Slug)
 	s.Equal(expected.Title, actual.Title)
@@ -261,9 +578,11 @@ func (s *DBSuite) assertAlert(expected, actual *model.Alert) {
 
 func newAlert(slug, title, body string, account accountModel.Account) *model.Alert {
 	return &model.Alert{
-		Slug:    slug,
-		Title:   title,
-		Body:    body,
-		Account: account,
+		Slug:        slug,
+		Title:       title,
+		Body:        body,
+		AlertStatus: model.AlertStatusActive,
+		RearmPolicy: model.RearmOnce,
+		Account:     account,
 	}
 }
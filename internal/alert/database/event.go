@@ -0,0 +1,118 @@
+package database
+
+import (
+	"context"
+	accountModel "kek-backend/internal/account/model"
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/database"
+	"kek-backend/pkg/logging"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IterateAlertEventCriteria struct {
+	AlertID uint
+	Offset  uint
+	Limit   uint
+}
+
+func (a *alertDB) SaveAlertEvent(ctx context.Context, event *model.AlertEvent) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.SaveAlertEvent", "event", event)
+
+	if err := db.WithContext(ctx).Create(event).Error; err != nil {
+		logger.Errorw("alert.db.SaveAlertEvent failed to save event", "err", err)
+		return err
+	}
+	return nil
+}
+
+func (a *alertDB) UpdateAlertEventDelivery(ctx context.Context, id uint, channel, status string) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.UpdateAlertEventDelivery", "id", id, "channel", channel, "status", status)
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var event model.AlertEvent
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, "id = ?", id).Error
+		if err != nil {
+			return err
+		}
+		if event.DeliveryStatus == nil {
+			event.DeliveryStatus = model.DeliveryStatus{}
+		}
+		event.DeliveryStatus[channel] = status
+		return tx.Model(&event).Update("delivery_status", event.DeliveryStatus).Error
+	})
+	if err != nil {
+		logger.Errorw("alert.db.UpdateAlertEventDelivery failed to update delivery status", "err", err)
+		if database.IsRecordNotFoundErr(err) {
+			return database.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (a *alertDB) CountTestAlertEvents(ctx context.Context, accountId uint, since time.Time) (int64, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.CountTestAlertEvents", "accountId", accountId, "since", since)
+
+	var count int64
+	err := db.WithContext(ctx).Model(&model.AlertEvent{}).
+		Joins("JOIN alerts a ON a.id = alert_events.alert_id").
+		Where("a.account_id = ? AND alert_events.is_test = ? AND alert_events.fired_at >= ?", accountId, true, since).
+		Count(&count).Error
+	if err != nil {
+		logger.Errorw("alert.db.CountTestAlertEvents failed to count events", "err", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+func (a *alertDB) LockAccount(ctx context.Context, accountId uint) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.LockAccount", "accountId", accountId)
+
+	var account accountModel.Account
+	err := db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").First(&account, "id = ?", accountId).Error
+	if err != nil {
+		logger.Errorw("alert.db.LockAccount failed to lock account", "err", err)
+		if database.IsRecordNotFoundErr(err) {
+			return database.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (a *alertDB) FindAlertEvents(ctx context.Context, criteria IterateAlertEventCriteria) ([]*model.AlertEvent, int64, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.FindAlertEvents", "criteria", criteria)
+
+	chain := db.WithContext(ctx).Model(&model.AlertEvent{}).Where("alert_id = ?", criteria.AlertID)
+
+	var totalCount int64
+	if err := chain.Count(&totalCount).Error; err != nil {
+		logger.Errorw("alert.db.FindAlertEvents failed to get total count", "err", err)
+		return nil, 0, err
+	}
+
+	ret := []*model.AlertEvent{}
+	err := chain.Offset(int(criteria.Offset)).
+		Limit(int(criteria.Limit)).
+		Order("fired_at DESC, id DESC").
+		Find(&ret).Error
+	if err != nil {
+		logger.Errorw("alert.db.FindAlertEvents failed to find events", "err", err)
+		return nil, 0, err
+	}
+	return ret, totalCount, nil
+}
@@ -0,0 +1,136 @@
+package database
+
+import (
+	"context"
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/database"
+	"time"
+)
+
+func (s *DBSuite) TestSaveAlertEvent() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+	event := newAlertEvent(alert.ID, time.Now())
+
+	// when
+	err := s.db.SaveAlertEvent(nil, event)
+
+	// then
+	s.NoError(err)
+	s.NotEqual(uint(0), event.ID)
+}
+
+func (s *DBSuite) TestUpdateAlertEventDelivery() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+	event := newAlertEvent(alert.ID, time.Now())
+	s.NoError(s.db.SaveAlertEvent(nil, event))
+
+	// when
+	err := s.db.UpdateAlertEventDelivery(nil, event.ID, model.ChannelPush, model.DeliveryFailed)
+
+	// then
+	s.NoError(err)
+	events, _, err := s.db.FindAlertEvents(nil, IterateAlertEventCriteria{AlertID: alert.ID, Limit: 1})
+	s.NoError(err)
+	s.Equal(model.DeliveryFailed, events[0].DeliveryStatus[model.ChannelPush])
+}
+
+func (s *DBSuite) TestUpdateAlertEventDelivery_FailIfNotExist() {
+	// when
+	err := s.db.UpdateAlertEventDelivery(nil, 1000, model.ChannelPush, model.DeliverySent)
+
+	// then
+	s.Equal(database.ErrNotFound, err)
+}
+
+func (s *DBSuite) TestFindAlertEvents() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+	alert2 := newAlert("title2", "title2", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert2))
+
+	now := time.Now()
+	event1 := newAlertEvent(alert.ID, now.Add(-2*time.Minute))
+	s.NoError(s.db.SaveAlertEvent(nil, event1))
+	event2 := newAlertEvent(alert.ID, now.Add(-time.Minute))
+	s.NoError(s.db.SaveAlertEvent(nil, event2))
+	event3 := newAlertEvent(alert.ID, now)
+	s.NoError(s.db.SaveAlertEvent(nil, event3))
+	s.NoError(s.db.SaveAlertEvent(nil, newAlertEvent(alert2.ID, now)))
+
+	criteria := IterateAlertEventCriteria{
+		AlertID: alert.ID,
+		Offset:  0,
+		Limit:   2,
+	}
+
+	// when : first iteration
+	results, total, err := s.db.FindAlertEvents(nil, criteria)
+
+	// then
+	s.NoError(err)
+	s.Equal(int64(3), total)
+	s.Equal(2, len(results))
+	s.Equal(event3.ID, results[0].ID)
+	s.Equal(event2.ID, results[1].ID)
+
+	// second iteration
+	criteria.Offset = criteria.Offset + uint(len(results))
+	results, total, err = s.db.FindAlertEvents(nil, criteria)
+
+	// then
+	s.NoError(err)
+	s.Equal(int64(3), total)
+	s.Equal(1, len(results))
+	s.Equal(event1.ID, results[0].ID)
+}
+
+func newAlertEvent(alertID uint, firedAt time.Time) *model.AlertEvent {
+	return &model.AlertEvent{
+		AlertID:        alertID,
+		ObservedPrice:  1.1,
+		Threshold:      1,
+		DeliveryStatus: model.DeliveryStatus{model.ChannelPush: model.DeliveryPending},
+		FiredAt:        firedAt,
+	}
+}
+
+func (s *DBSuite) TestLockAccount() {
+	// when
+	err := s.db.RunInTx(context.Background(), func(ctx context.Context) error {
+		return s.db.LockAccount(ctx, dUser.ID)
+	})
+
+	// then
+	s.NoError(err)
+	s.Equal(database.ErrNotFound, s.db.LockAccount(nil, dUser.ID+1000))
+}
+
+func (s *DBSuite) TestCountTestAlertEvents() {
+	// given
+	alert := newAlert("title1", "title1", "body", dUser)
+	s.NoError(s.db.SaveAlert(nil, alert))
+
+	now := time.Now()
+	old := newAlertEvent(alert.ID, now.Add(-2*time.Hour))
+	old.IsTest = true
+	s.NoError(s.db.SaveAlertEvent(nil, old))
+	recent := newAlertEvent(alert.ID, now.Add(-time.Minute))
+	recent.IsTest = true
+	s.NoError(s.db.SaveAlertEvent(nil, recent))
+	s.NoError(s.db.SaveAlertEvent(nil, newAlertEvent(alert.ID, now)))
+
+	// when
+	count, err := s.db.CountTestAlertEvents(nil, dUser.ID, now.Add(-time.Hour))
+
+	// then
+	s.NoError(err)
+	s.Equal(int64(1), count)
+	count, err = s.db.CountTestAlertEvents(nil, dUser.ID+1000, now.Add(-time.Hour))
+	s.NoError(err)
+	s.Equal(int64(0), count)
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/database"
+	"kek-backend/pkg/logging"
+	"time"
+)
+
+func (a *alertDB) AcquireLease(ctx context.Context, name, holder string, now time.Time, ttl time.Duration) (bool, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.AcquireLease", "name", name, "holder", holder)
+
+	// renew the lease if held by given holder or take over the expired one
+	chain := db.WithContext(ctx).Model(&model.Lease{}).
+		Where("name = ? AND (holder = ? OR expires_at < ?)", name, holder, now).
+		Updates(map[string]interface{}{
+			"holder":     holder,
+			"expires_at": now.Add(ttl),
+		})
+	if chain.Error != nil {
+		logger.Errorw("alert.db.AcquireLease failed to update lease", "err", chain.Error)
+		return false, chain.Error
+	}
+	if chain.RowsAffected != 0 {
+		return true, nil
+	}
+
+	// create the lease if not exist. creating fails if another holder created it in the meantime
+	lease := model.Lease{Name: name, Holder: holder, ExpiresAt: now.Add(ttl)}
+	if err := db.WithContext(ctx).Create(&lease).Error; err == nil {
+		return true, nil
+	}
+	// rows of an update without any change are not counted by some databases
+	// so that the lease is checked again
+	var find model.Lease
+	if err := db.WithContext(ctx).First(&find, "name = ?", name).Error; err != nil {
+		logger.Errorw("alert.db.AcquireLease failed to find lease", "err", err)
+		return false, err
+	}
+	return find.Holder == holder && !find.ExpiresAt.Before(now), nil
+}
+
+func (a *alertDB) ReleaseLease(ctx context.Context, name, holder string) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.ReleaseLease", "name", name, "holder", holder)
+
+	err := db.WithContext(ctx).Where("name = ? AND holder = ?", name, holder).Delete(&model.Lease{}).Error
+	if err != nil {
+		logger.Errorw("alert.db.ReleaseLease failed to delete lease", "err", err)
+		return err
+	}
+	return nil
+}
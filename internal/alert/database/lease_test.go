@@ -0,0 +1,52 @@
+package database
+
+import (
+	"time"
+)
+
+func (s *DBSuite) TestAcquireLease() {
+	now := time.Now().Truncate(time.Second)
+
+	// acquire a new lease
+	acquired, err := s.db.AcquireLease(nil, "job", "replica-1", now, time.Minute)
+	s.NoError(err)
+	s.True(acquired)
+
+	// renew the lease by the holder
+	acquired, err = s.db.AcquireLease(nil, "job", "replica-1", now, time.Minute)
+	s.NoError(err)
+	s.True(acquired)
+
+	// another holder can not acquire the lease until expired
+	acquired, err = s.db.AcquireLease(nil, "job", "replica-2", now.Add(30*time.Second), time.Minute)
+	s.NoError(err)
+	s.False(acquired)
+
+	// another holder takes over the expired lease
+	acquired, err = s.db.AcquireLease(nil, "job", "replica-2", now.Add(2*time.Minute), time.Minute)
+	s.NoError(err)
+	s.True(acquired)
+	acquired, err = s.db.AcquireLease(nil, "job", "replica-1", now.Add(2*time.Minute), time.Minute)
+	s.NoError(err)
+	s.False(acquired)
+}
+
+func (s *DBSuite) TestReleaseLease() {
+	// given
+	now := time.Now().Truncate(time.Second)
+	_, err := s.db.AcquireLease(nil, "job", "replica-1", now, time.Minute)
+	s.NoError(err)
+
+	// when
+	s.NoError(s.db.ReleaseLease(nil, "job", "replica-2"))
+	acquired, err := s.db.AcquireLease(nil, "job", "replica-2", now, time.Minute)
+	s.NoError(err)
+	s.False(acquired)
+
+	s.NoError(s.db.ReleaseLease(nil, "job", "replica-1"))
+	acquired, err = s.db.AcquireLease(nil, "job", "replica-2", now, time.Minute)
+
+	// then
+	s.NoError(err)
+	s.True(acquired)
+}
@@ -5,6 +5,7 @@ package mocks
 import (
 	context "context"
 	database "kek-backend/internal/alert/database"
+	time "time"
 
 	mock "github.com/stretchr/testify/mock"
 
@@ -16,13 +17,34 @@ type AlertDB struct {
 	mock.Mock
 }
 
-// DeleteAlertBySlug provides a mock function with given fields: ctx, accountId, slug
-func (_m *AlertDB) DeleteAlertBySlug(ctx context.Context, accountId uint, slug string) error {
-	ret := _m.Called(ctx, accountId, slug)
+// AcquireLease provides a mock function with given fields: ctx, name, holder, now, ttl
+func (_m *AlertDB) AcquireLease(ctx context.Context, name string, holder string, now time.Time, ttl time.Duration) (bool, error) {
+	ret := _m.Called(ctx, name, holder, now, ttl)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time, time.Duration) bool); ok {
+		r0 = rf(ctx, name, holder, now, ttl)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, time.Time, time.Duration) error); ok {
+		r1 = rf(ctx, name, holder, now, ttl)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ClearAlertObservation provides a mock function with given fields: ctx, id
+func (_m *AlertDB) ClearAlertObservation(ctx context.Context, id uint) error {
+	ret := _m.Called(ctx, id)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint, string) error); ok {
-		r0 = rf(ctx, accountId, slug)
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(ctx, id)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -30,13 +52,55 @@ func (_m *AlertDB) DeleteAlertBySlug(ctx context.Context, accountId uint, slug s
 	return r0
 }
 
-// DeleteCommentById provides a mock function with given fields: ctx, accountId, slug, id
-func (_m *AlertDB) DeleteCommentById(ctx context.Context, accountId uint, slug string, id uint) error {
-	ret := _m.Called(ctx, accountId, slug, id)
+// CountDueDeliveries provides a mock function with given fields: ctx, now
+func (_m *AlertDB) CountDueDeliveries(ctx context.Context, now time.Time) (int64, error) {
+	ret := _m.Called(ctx, now)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, now)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountTestAlertEvents provides a mock function with given fields: ctx, accountId, since
+func (_m *AlertDB) CountTestAlertEvents(ctx context.Context, accountId uint, since time.Time) (int64, error) {
+	ret := _m.Called(ctx, accountId, since)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, uint, time.Time) int64); ok {
+		r0 = rf(ctx, accountId, since)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint, time.Time) error); ok {
+		r1 = rf(ctx, accountId, since)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteAlertBySlug provides a mock function with given fields: ctx, accountId, slug
+func (_m *AlertDB) DeleteAlertBySlug(ctx context.Context, accountId uint, slug string) error {
+	ret := _m.Called(ctx, accountId, slug)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint, string, uint) error); ok {
-		r0 = rf(ctx, accountId, slug, id)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string) error); ok {
+		r0 = rf(ctx, accountId, slug)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -44,20 +108,20 @@ func (_m *AlertDB) DeleteCommentById(ctx context.Context, accountId uint, slug s
 	return r0
 }
 
-// DeleteComments provides a mock function with given fields: ctx, accountId, slug
-func (_m *AlertDB) DeleteComments(ctx context.Context, accountId uint, slug string) (int64, error) {
-	ret := _m.Called(ctx, accountId, slug)
+// ExpireAlerts provides a mock function with given fields: ctx, now
+func (_m *AlertDB) ExpireAlerts(ctx context.Context, now time.Time) (int64, error) {
+	ret := _m.Called(ctx, now)
 
 	var r0 int64
-	if rf, ok := ret.Get(0).(func(context.Context, uint, string) int64); ok {
-		r0 = rf(ctx, accountId, slug)
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, now)
 	} else {
 		r0 = ret.Get(0).(int64)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, uint, string) error); ok {
-		r1 = rf(ctx, accountId, slug)
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -88,6 +152,36 @@ func (_m *AlertDB) FindAlertBySlug(ctx context.Context, slug string) (*model.Ale
 	return r0, r1
 }
 
+// FindAlertEvents provides a mock function with given fields: ctx, criteria
+func (_m *AlertDB) FindAlertEvents(ctx context.Context, criteria database.IterateAlertEventCriteria) ([]*model.AlertEvent, int64, error) {
+	ret := _m.Called(ctx, criteria)
+
+	var r0 []*model.AlertEvent
+	if rf, ok := ret.Get(0).(func(context.Context, database.IterateAlertEventCriteria) []*model.AlertEvent); ok {
+		r0 = rf(ctx, criteria)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.AlertEvent)
+		}
+	}
+
+	var r1 int64
+	if rf, ok := ret.Get(1).(func(context.Context, database.IterateAlertEventCriteria) int64); ok {
+		r1 = rf(ctx, criteria)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.IterateAlertEventCriteria) error); ok {
+		r2 = rf(ctx, criteria)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // FindAlerts provides a mock function with given fields: ctx, criteria
 func (_m *AlertDB) FindAlerts(ctx context.Context, criteria database.IterateAlertCriteria) ([]*model.Alert, int64, error) {
 	ret := _m.Called(ctx, criteria)
@@ -118,6 +212,187 @@ func (_m *AlertDB) FindAlerts(ctx context.Context, criteria database.IterateAler
 	return r0, r1, r2
 }
 
+// FindAlertsAfter provides a mock function with given fields: ctx, statuses, afterID, limit
+func (_m *AlertDB) FindAlertsAfter(ctx context.Context, statuses []string, afterID uint, limit uint) ([]*model.Alert, error) {
+	ret := _m.Called(ctx, statuses, afterID, limit)
+
+	var r0 []*model.Alert
+	if rf, ok := ret.Get(0).(func(context.Context, []string, uint, uint) []*model.Alert); ok {
+		r0 = rf(ctx, statuses, afterID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Alert)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []string, uint, uint) error); ok {
+		r1 = rf(ctx, statuses, afterID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindDeliveries provides a mock function with given fields: ctx, criteria
+func (_m *AlertDB) FindDeliveries(ctx context.Context, criteria database.IterateDeliveryCriteria) ([]*model.Delivery, int64, error) {
+	ret := _m.Called(ctx, criteria)
+
+	var r0 []*model.Delivery
+	if rf, ok := ret.Get(0).(func(context.Context, database.IterateDeliveryCriteria) []*model.Delivery); ok {
+		r0 = rf(ctx, criteria)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Delivery)
+		}
+	}
+
+	var r1 int64
+	if rf, ok := ret.Get(1).(func(context.Context, database.IterateDeliveryCriteria) int64); ok {
+		r1 = rf(ctx, criteria)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.IterateDeliveryCriteria) error); ok {
+		r2 = rf(ctx, criteria)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// FindDueDeliveries provides a mock function with given fields: ctx, now, limit
+func (_m *AlertDB) FindDueDeliveries(ctx context.Context, now time.Time, limit uint) ([]*model.Delivery, error) {
+	ret := _m.Called(ctx, now, limit)
+
+	var r0 []*model.Delivery
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, uint) []*model.Delivery); ok {
+		r0 = rf(ctx, now, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Delivery)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, uint) error); ok {
+		r1 = rf(ctx, now, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LockAccount provides a mock function with given fields: ctx, accountId
+func (_m *AlertDB) LockAccount(ctx context.Context, accountId uint) error {
+	ret := _m.Called(ctx, accountId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(ctx, accountId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PauseAccountAlerts provides a mock function with given fields: ctx, accountId
+func (_m *AlertDB) PauseAccountAlerts(ctx context.Context, accountId uint) (int64, error) {
+	ret := _m.Called(ctx, accountId)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, uint) int64); ok {
+		r0 = rf(ctx, accountId)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, accountId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReleaseLease provides a mock function with given fields: ctx, name, holder
+func (_m *AlertDB) ReleaseLease(ctx context.Context, name string, holder string) error {
+	ret := _m.Called(ctx, name, holder)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, name, holder)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReplayDelivery provides a mock function with given fields: ctx, id, now
+func (_m *AlertDB) ReplayDelivery(ctx context.Context, id uint, now time.Time) error {
+	ret := _m.Called(ctx, id, now)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, time.Time) error); ok {
+		r0 = rf(ctx, id, now)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ResumeAccountAlerts provides a mock function with given fields: ctx, accountId
+func (_m *AlertDB) ResumeAccountAlerts(ctx context.Context, accountId uint) (int64, error) {
+	ret := _m.Called(ctx, accountId)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, uint) int64); ok {
+		r0 = rf(ctx, accountId)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, accountId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResumeSnoozedAlerts provides a mock function with given fields: ctx, now
+func (_m *AlertDB) ResumeSnoozedAlerts(ctx context.Context, now time.Time) (int64, error) {
+	ret := _m.Called(ctx, now)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, now)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RunInTx provides a mock function with given fields: ctx, f
 func (_m *AlertDB) RunInTx(ctx context.Context, f func(context.Context) error) error {
 	ret := _m.Called(ctx, f)
@@ -145,3 +420,115 @@ func (_m *AlertDB) SaveAlert(ctx context.Context, alert *model.Alert) error {
 
 	return r0
 }
+
+// SaveAlertEvent provides a mock function with given fields: ctx, event
+func (_m *AlertDB) SaveAlertEvent(ctx context.Context, event *model.AlertEvent) error {
+	ret := _m.Called(ctx, event)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.AlertEvent) error); ok {
+		r0 = rf(ctx, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveDeliveries provides a mock function with given fields: ctx, deliveries
+func (_m *AlertDB) SaveDeliveries(ctx context.Context, deliveries []*model.Delivery) error {
+	ret := _m.Called(ctx, deliveries)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*model.Delivery) error); ok {
+		r0 = rf(ctx, deliveries)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SnoozeAlert provides a mock function with given fields: ctx, id, from, until
+func (_m *AlertDB) SnoozeAlert(ctx context.Context, id uint, from string, until time.Time) error {
+	ret := _m.Called(ctx, id, from, until)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, time.Time) error); ok {
+		r0 = rf(ctx, id, from, until)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TransitAlertStatus provides a mock function with given fields: ctx, id, from, to
+func (_m *AlertDB) TransitAlertStatus(ctx context.Context, id uint, from string, to string) error {
+	ret := _m.Called(ctx, id, from, to)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, string) error); ok {
+		r0 = rf(ctx, id, from, to)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateAlert provides a mock function with given fields: ctx, alert
+func (_m *AlertDB) UpdateAlert(ctx context.Context, alert *model.Alert) error {
+	ret := _m.Called(ctx, alert)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Alert) error); ok {
+		r0 = rf(ctx, alert)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateAlertEventDelivery provides a mock function with given fields: ctx, id, channel, status
+func (_m *AlertDB) UpdateAlertEventDelivery(ctx context.Context, id uint, channel string, status string) error {
+	ret := _m.Called(ctx, id, channel, status)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, string) error); ok {
+		r0 = rf(ctx, id, channel, status)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateAlertObservation provides a mock function with given fields: ctx, id, price, at
+func (_m *AlertDB) UpdateAlertObservation(ctx context.Context, id uint, price float64, at time.Time) error {
+	ret := _m.Called(ctx, id, price, at)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, float64, time.Time) error); ok {
+		r0 = rf(ctx, id, price, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateDelivery provides a mock function with given fields: ctx, delivery
+func (_m *AlertDB) UpdateDelivery(ctx context.Context, delivery *model.Delivery) error {
+	ret := _m.Called(ctx, delivery)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.Delivery) error); ok {
+		r0 = rf(ctx, delivery)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
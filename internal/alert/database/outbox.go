@@ -0,0 +1,139 @@
+package database
+
+import (
+	"context"
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/database"
+	"kek-backend/pkg/logging"
+	"time"
+)
+
+type IterateDeliveryCriteria struct {
+	Statuses []string
+	Offset   uint
+	Limit    uint
+}
+
+func (a *alertDB) SaveDeliveries(ctx context.Context, deliveries []*model.Delivery) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.SaveDeliveries", "count", len(deliveries))
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err := db.WithContext(ctx).Create(&deliveries).Error; err != nil {
+		logger.Errorw("alert.db.SaveDeliveries failed to save deliveries", "err", err)
+		return err
+	}
+	return nil
+}
+
+func (a *alertDB) FindDueDeliveries(ctx context.Context, now time.Time, limit uint) ([]*model.Delivery, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.FindDueDeliveries", "now", now, "limit", limit)
+
+	ret := []*model.Delivery{}
+	err := db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", model.DeliveryPending, now).
+		Order("next_attempt_at ASC, id ASC").
+		Limit(int(limit)).
+		Find(&ret).Error
+	if err != nil {
+		logger.Errorw("alert.db.FindDueDeliveries failed to find deliveries", "err", err)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (a *alertDB) CountDueDeliveries(ctx context.Context, now time.Time) (int64, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.CountDueDeliveries", "now", now)
+
+	var count int64
+	err := db.WithContext(ctx).Model(&model.Delivery{}).
+		Where("status = ? AND next_attempt_at <= ?", model.DeliveryPending, now).
+		Count(&count).Error
+	if err != nil {
+		logger.Errorw("alert.db.CountDueDeliveries failed to count deliveries", "err", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+func (a *alertDB) UpdateDelivery(ctx context.Context, delivery *model.Delivery) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.UpdateDelivery", "delivery", delivery)
+
+	result := db.WithContext(ctx).Model(&model.Delivery{}).
+		Where("id = ?", delivery.ID).
+		Updates(map[string]interface{}{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"last_error":      delivery.LastError,
+			"updated_at":      time.Now(),
+		})
+	if result.Error != nil {
+		logger.Errorw("alert.db.UpdateDelivery failed to update delivery", "err", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return database.ErrNotFound
+	}
+	return nil
+}
+
+func (a *alertDB) FindDeliveries(ctx context.Context, criteria IterateDeliveryCriteria) ([]*model.Delivery, int64, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.FindDeliveries", "criteria", criteria)
+
+	chain := db.WithContext(ctx).Model(&model.Delivery{})
+	if len(criteria.Statuses) != 0 {
+		chain = chain.Where("status IN (?)", criteria.Statuses)
+	}
+
+	var totalCount int64
+	if err := chain.Count(&totalCount).Error; err != nil {
+		logger.Errorw("alert.db.FindDeliveries failed to get total count", "err", err)
+		return nil, 0, err
+	}
+
+	ret := []*model.Delivery{}
+	err := chain.Offset(int(criteria.Offset)).
+		Limit(int(criteria.Limit)).
+		Order("id DESC").
+		Find(&ret).Error
+	if err != nil {
+		logger.Errorw("alert.db.FindDeliveries failed to find deliveries", "err", err)
+		return nil, 0, err
+	}
+	return ret, totalCount, nil
+}
+
+func (a *alertDB) ReplayDelivery(ctx context.Context, id uint, now time.Time) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, a.db)
+	logger.Debugw("alert.db.ReplayDelivery", "id", id)
+
+	result := db.WithContext(ctx).Model(&model.Delivery{}).
+		Where("id = ? AND status IN (?)", id, []string{model.DeliveryDead, model.DeliveryPending}).
+		Updates(map[string]interface{}{
+			"status":          model.DeliveryPending,
+			"attempts":        0,
+			"next_attempt_at": now,
+			"updated_at":      now,
+		})
+	if result.Error != nil {
+		logger.Errorw("alert.db.ReplayDelivery failed to replay delivery", "err", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return database.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package database
+
+import (
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/database"
+	"time"
+)
+
+func (s *DBSuite) TestFindDueDeliveries() {
+	// given
+	now := time.Now()
+	due := newDelivery(1, now.Add(-time.Minute))
+	notDue := newDelivery(1, now.Add(time.Minute))
+	sent := newDelivery(1, now.Add(-time.Minute))
+	sent.Status = model.DeliverySent
+	s.NoError(s.db.SaveDeliveries(nil, []*model.Delivery{due, notDue, sent}))
+
+	// when
+	results, err := s.db.FindDueDeliveries(nil, now, 10)
+
+	// then
+	s.NoError(err)
+	s.Equal(1, len(results))
+	s.Equal(due.ID, results[0].ID)
+	s.Equal(due.Payload, results[0].Payload)
+}
+
+func (s *DBSuite) TestCountDueDeliveries() {
+	// given
+	now := time.Now()
+	sent := newDelivery(1, now.Add(-time.Minute))
+	sent.Status = model.DeliverySent
+	s.NoError(s.db.SaveDeliveries(nil, []*model.Delivery{
+		newDelivery(1, now.Add(-time.Minute)),
+		newDelivery(1, now.Add(-time.Second)),
+		newDelivery(1, now.Add(time.Minute)),
+		sent,
+	}))
+
+	// when
+	count, err := s.db.CountDueDeliveries(nil, now)
+
+	// then
+	s.NoError(err)
+	s.Equal(int64(2), count)
+}
+
+func (s *DBSuite) TestUpdateDelivery() {
+	// given
+	delivery := newDelivery(1, time.Now())
+	s.NoError(s.db.SaveDeliveries(nil, []*model.Delivery{delivery}))
+	delivery.Status = model.DeliveryDead
+	delivery.Attempts = 3
+	delivery.LastError = "timeout"
+
+	// when
+	err := s.db.UpdateDelivery(nil, delivery)
+
+	// then
+	s.NoError(err)
+	results, total, err := s.db.FindDeliveries(nil, IterateDeliveryCriteria{Statuses: []string{model.DeliveryDead}, Limit: 10})
+	s.NoError(err)
+	s.Equal(int64(1), total)
+	s.Equal(3, results[0].Attempts)
+	s.Equal("timeout", results[0].LastError)
+}
+
+func (s *DBSuite) TestReplayDelivery() {
+	// given
+	delivery := newDelivery(1, time.Now().Add(-time.Hour))
+	delivery.Status = model.DeliveryDead
+	delivery.Attempts = 8
+	s.NoError(s.db.SaveDeliveries(nil, []*model.Delivery{delivery}))
+
+	// when
+	now := time.Now()
+	err := s.db.ReplayDelivery(nil, delivery.ID, now)
+
+	// then
+	s.NoError(err)
+	results, err := s.db.FindDueDeliveries(nil, now.Add(time.Second), 10)
+	s.NoError(err)
+	s.Equal(1, len(results))
+	s.Equal(0, results[0].Attempts)
+}
+
+func (s *DBSuite) TestReplayDelivery_FailIfSent() {
+	// given
+	delivery := newDelivery(1, time.Now())
+	delivery.Status = model.DeliverySent
+	s.NoError(s.db.SaveDeliveries(nil, []*model.Delivery{delivery}))
+
+	// when
+	err := s.db.ReplayDelivery(nil, delivery.ID, time.Now())
+
+	// then
+	s.Equal(database.ErrNotFound, err)
+}
+
+func newDelivery(eventID uint, nextAttemptAt time.Time) *model.Delivery {
+	return &model.Delivery{
+		AlertEventID: eventID,
+		AlertID:      1,
+		Channel:      model.ChannelPush,
+		Payload: model.DeliveryPayload{
+			Action: model.AlertAction{Type: model.ChannelPush},
+			Token:  "device-token",
+			Slug:   "title1",
+		},
+		Status:        model.DeliveryPending,
+		NextAttemptAt: nextAttemptAt,
+	}
+}
@@ -0,0 +1,129 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/notification"
+	"kek-backend/pkg/logging"
+)
+
+// WebhookPayload is a json body posted to webhook actions when an alert fires
+type WebhookPayload struct {
+	Slug          string    `json:"slug"`
+	Title         string    `json:"title"`
+	Body          string    `json:"body"`
+	PairAddress   string    `json:"pairAddress"`
+	AlertType     string    `json:"alertType"`
+	AlertOption   string    `json:"alertOption"`
+	Threshold     float64   `json:"threshold"`
+	ObservedPrice float64   `json:"observedPrice"`
+	FiredAt       time.Time `json:"firedAt"`
+	// IsTest is true if the alert is fired by a test fire
+	IsTest bool `json:"isTest"`
+}
+
+// Dispatcher delivers fired alerts through their actions
+type Dispatcher struct {
+	notifier notification.Notifier
+	webhook  *notification.WebhookSender
+	email    *notification.EmailSender
+	bot      *notification.BotSender
+}
+
+// Send delivers given payload through its action
+func (d *Dispatcher) Send(ctx context.Context, p *model.DeliveryPayload) error {
+	price := strconv.FormatFloat(p.ObservedPrice, 'f', -1, 64)
+	switch p.Action.Type {
+	case model.ChannelPush:
+		return d.notifier.Notify(ctx, &notification.Message{
+			Token: p.Token,
+			Title: p.Title,
+			Body:  p.Body,
+			Data: map[string]string{
+				"slug":        p.Slug,
+				"pairAddress": p.PairAddress,
+				"price":       price,
+				"isTest":      strconv.FormatBool(p.IsTest),
+			},
+		})
+	case model.ChannelWebhook:
+		return d.webhook.Send(ctx, p.Action.URL, p.Action.Secret, &WebhookPayload{
+			Slug:          p.Slug,
+			Title:         p.Title,
+			Body:          p.Body,
+			PairAddress:   p.PairAddress,
+			AlertType:     p.AlertType,
+			AlertOption:   p.AlertOption,
+			Threshold:     p.Threshold,
+			ObservedPrice: p.ObservedPrice,
+			FiredAt:       p.FiredAt,
+			IsTest:        p.IsTest,
+		})
+	case model.ChannelEmail:
+		return d.email.Send(ctx, p.Action.Email, p.Title, fmt.Sprintf("%s\n\npair: %s\nprice: %s", p.Body, p.PairAddress, price))
+	case model.ChannelBot:
+		return d.bot.Send(ctx, p.Action.URL, p.Action.ChatID, fmt.Sprintf("%s\n%s\nprice: %s", p.Title, p.Body, price), p.IsTest)
+	}
+	return fmt.Errorf("unsupported action type: %s", p.Action.Type)
+}
+
+// newDeliveries returns an outbox delivery of given rendered title and body for each action
+// of given alert fired with given event
+func newDeliveries(a *model.Alert, title, body string, event *model.AlertEvent, now time.Time) []*model.Delivery {
+	var deliveries []*model.Delivery
+	for _, action := range a.AlertActions {
+		deliveries = append(deliveries, &model.Delivery{
+			AlertEventID: event.ID,
+			AlertID:      a.ID,
+			Channel:      action.Type,
+			Payload: model.DeliveryPayload{
+				Action:        action,
+				Token:         a.Account.Token,
+				Slug:          a.Slug,
+				Title:         title,
+				Body:          body,
+				PairAddress:   a.PairAddress,
+				AlertType:     a.AlertType,
+				AlertOption:   a.AlertOption,
+				Threshold:     event.Threshold,
+				ObservedPrice: event.ObservedPrice,
+				FiredAt:       event.FiredAt,
+				IsTest:        event.IsTest,
+			},
+			Status:        model.DeliveryPending,
+			NextAttemptAt: now,
+		})
+	}
+	return deliveries
+}
+
+// renderAlert renders the title and the body of given alert with given data.
+// A template failed to render is sent as it is since templates are validated on save.
+func renderAlert(ctx context.Context, a *model.Alert, d *TemplateData) (string, string) {
+	logger := logging.FromContext(ctx)
+	title, err := RenderTemplate(a.Title, d)
+	if err != nil {
+		logger.Warnw("alert.dispatcher failed to render title", "alert", a.Slug, "err", err)
+		title = a.Title
+	}
+	body, err := RenderTemplate(a.Body, d)
+	if err != nil {
+		logger.Warnw("alert.dispatcher failed to render body", "alert", a.Slug, "err", err)
+		body = a.Body
+	}
+	return title, body
+}
+
+// NewDispatcher creates a new dispatcher with given senders
+func NewDispatcher(notifier notification.Notifier, webhook *notification.WebhookSender, email *notification.EmailSender, bot *notification.BotSender) *Dispatcher {
+	return &Dispatcher{
+		notifier: notifier,
+		webhook:  webhook,
+		email:    email,
+		bot:      bot,
+	}
+}
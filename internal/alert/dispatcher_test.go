@@ -0,0 +1,175 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/config"
+	"kek-backend/internal/notification"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDispatcher_Send(t *testing.T) {
+	// given
+	var (
+		webhook WebhookPayload
+		bot     map[string]interface{}
+	)
+	webhookServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&webhook)
+	}))
+	defer webhookServer.Close()
+	botServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&bot)
+	}))
+	defer botServer.Close()
+
+	notifier := notification.NewFakeNotifier()
+	dispatcher := NewDispatcher(notifier,
+		notification.NewWebhookSenderWithClient(webhookServer.Client()),
+		notification.NewEmailSender(&config.Config{}),
+		notification.NewBotSenderWithClient(botServer.Client()))
+	alert := newCronAlert(model.AlertStatusActive, model.RearmOnce)
+	alert.AlertActions = model.AlertActions{
+		{Type: model.ChannelPush},
+		{Type: model.ChannelWebhook, URL: webhookServer.URL, Secret: "secret"},
+		{Type: model.ChannelEmail, Email: "user1@gmail.com"},
+		{Type: model.ChannelBot, URL: botServer.URL, ChatID: "100"},
+	}
+	event := model.AlertEvent{ID: 10, ObservedPrice: 2, Threshold: 1, FiredAt: time.Now()}
+
+	// when
+	errs := map[string]error{}
+	for _, d := range newDeliveries(alert, alert.Title, alert.Body, &event, time.Now()) {
+		errs[d.Channel] = dispatcher.Send(context.Background(), &d.Payload)
+	}
+
+	// then
+	assert.NoError(t, errs[model.ChannelPush])
+	assert.NoError(t, errs[model.ChannelWebhook])
+	// email is not configured
+	assert.Equal(t, notification.ErrNotConfigured, errs[model.ChannelEmail])
+	assert.NoError(t, errs[model.ChannelBot])
+	assert.Equal(t, 1, len(notifier.Messages()))
+	assert.Equal(t, alert.Account.Token, notifier.Messages()[0].Token)
+	assert.Equal(t, "2", notifier.Messages()[0].Data["price"])
+	assert.Equal(t, alert.Slug, webhook.Slug)
+	assert.Equal(t, float64(2), webhook.ObservedPrice)
+	assert.Equal(t, float64(1), webhook.Threshold)
+	assert.Equal(t, "100", bot["chat_id"])
+	assert.Contains(t, bot["text"], alert.Title)
+	assert.Equal(t, false, bot["is_test"])
+	assert.False(t, webhook.IsTest)
+	assert.Equal(t, "false", notifier.Messages()[0].Data["isTest"])
+}
+
+func TestDispatcher_SendTestFire(t *testing.T) {
+	// given
+	var (
+		webhook WebhookPayload
+		bot     map[string]interface{}
+	)
+	webhookServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&webhook)
+	}))
+	defer webhookServer.Close()
+	botServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&bot)
+	}))
+	defer botServer.Close()
+	dispatcher := NewDispatcher(notification.NewFakeNotifier(),
+		notification.NewWebhookSenderWithClient(webhookServer.Client()), nil,
+		notification.NewBotSenderWithClient(botServer.Client()))
+	alert := newCronAlert(model.AlertStatusActive, model.RearmOnce)
+	alert.AlertActions = model.AlertActions{
+		{Type: model.ChannelWebhook, URL: webhookServer.URL},
+		{Type: model.ChannelBot, URL: botServer.URL, ChatID: "100"},
+	}
+	event := model.AlertEvent{ID: 10, ObservedPrice: 2, Threshold: 1, IsTest: true, FiredAt: time.Now()}
+
+	// when
+	for _, d := range newDeliveries(alert, alert.Title, alert.Body, &event, time.Now()) {
+		assert.NoError(t, dispatcher.Send(context.Background(), &d.Payload))
+	}
+
+	// then
+	assert.True(t, webhook.IsTest)
+	assert.Equal(t, true, bot["is_test"])
+}
+
+func TestValidateActions(t *testing.T) {
+	cases := []struct {
+		Name    string
+		Actions model.AlertActions
+		// expected
+		Field string
+	}{
+		{
+			Name: "All types",
+			Actions: model.AlertActions{
+				{Type: model.ChannelPush},
+				{Type: model.ChannelWebhook, URL: "https://example.com/hook", Secret: "secret"},
+				{Type: model.ChannelEmail, Email: "user1@gmail.com"},
+				{Type: model.ChannelBot, URL: "https://api.telegram.org/bot1/sendMessage", ChatID: "100"},
+			},
+		}, {
+			Name:  "Empty",
+			Field: "alertActions",
+		}, {
+			Name:    "Unknown type",
+			Actions: model.AlertActions{{Type: "sms"}},
+			Field:   "alertActions[0].type",
+		}, {
+			Name:    "Duplicate type",
+			Actions: model.AlertActions{{Type: model.ChannelPush}, {Type: model.ChannelPush}},
+			Field:   "alertActions[1].type",
+		}, {
+			Name:    "Plain http webhook",
+			Actions: model.AlertActions{{Type: model.ChannelWebhook, URL: "http://example.com/hook"}},
+			Field:   "alertActions[0].url",
+		}, {
+			Name:    "Loopback webhook",
+			Actions: model.AlertActions{{Type: model.ChannelWebhook, URL: "https://127.0.0.1:8080/hook"}},
+			Field:   "alertActions[0].url",
+		}, {
+			Name:    "Metadata bot",
+			Actions: model.AlertActions{{Type: model.ChannelBot, URL: "http://169.254.169.254/latest", ChatID: "100"}},
+			Field:   "alertActions[0].url",
+		}, {
+			Name:    "Private network webhook",
+			Actions: model.AlertActions{{Type: model.ChannelWebhook, URL: "https://10.0.0.5/hook"}},
+			Field:   "alertActions[0].url",
+		}, {
+			Name:    "Localhost webhook",
+			Actions: model.AlertActions{{Type: model.ChannelWebhook, URL: "https://localhost/hook"}},
+			Field:   "alertActions[0].url",
+		}, {
+			Name:    "Invalid email",
+			Actions: model.AlertActions{{Type: model.ChannelEmail, Email: "user1"}},
+			Field:   "alertActions[0].email",
+		}, {
+			Name:    "Bot without chat id",
+			Actions: model.AlertActions{{Type: model.ChannelBot, URL: "https://example.com/bot"}},
+			Field:   "alertActions[0].chatId",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := ValidateActions(tc.Actions)
+
+			if tc.Field == "" {
+				assert.NoError(t, err)
+				return
+			}
+			aErr, ok := err.(*ActionError)
+			assert.True(t, ok)
+			assert.Equal(t, tc.Field, aErr.Field)
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/database"
+	tokenDB "kek-backend/internal/token/database"
+	"kek-backend/internal/uniswap"
+)
+
+// Result is an outcome of evaluating an alert's condition at ObservedAt.
+// Observed is false if the price of the alert's pair was not observed
+// which happens for expressions not watching the pair.
+type Result struct {
+	Condition   *Condition
+	Observation Observation
+	Observed    bool
+	Symbol      string
+	Name        string
+	Holds       bool
+	ObservedAt  time.Time
+}
+
+// ErrMarketNotFound is returned if the market data of a token watched by an alert is not observed
+var ErrMarketNotFound = errors.New("not found market")
+
+// Market is market data of an alert's pair observed at a tick.
+// Liquidity and the volumes are only meaningful if HasLiquidity and HasVolume are true.
+type Market struct {
+	Symbol        string
+	Name          string
+	Price         float64
+	Liquidity     float64
+	HasLiquidity  bool
+	Volume        float64
+	AverageVolume float64
+	HasVolume     bool
+}
+
+// Evaluator checks alerts' conditions against observed USD prices.
+// Crossing and percent change conditions compare the current observation with
+// the last price observed for the alert, and conditions with a baseline price
+// look up stored price history.
+type Evaluator struct {
+	tokenDB tokenDB.TokenDB
+}
+
+// Evaluate checks the condition or the expression of given alert with given market data
+// keyed by normalized address observed at now.
+// An error is returned if the alert has an invalid condition, the market data of a watched
+// token is missing or failed to find the baseline price.
+func (e *Evaluator) Evaluate(ctx context.Context, a *model.Alert, markets map[string]*Market, now time.Time) (*Result, error) {
+	address, err := uniswap.NormalizeAddress(a.PairAddress)
+	if err != nil {
+		return nil, err
+	}
+	if IsExpressionAlert(a) {
+		return e.evaluateExpression(ctx, a, address, markets, now)
+	}
+	cond, err := ParseAlertCondition(a)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := markets[address]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrMarketNotFound, address)
+	}
+	baseline, hasBaseline, err := e.baseline(ctx, a, address, cond, now)
+	if err != nil {
+		return nil, err
+	}
+
+	o := observationOf(m)
+	if a.LastObservedPrice != nil {
+		o.Previous, o.HasPrevious = *a.LastObservedPrice, true
+	}
+	o.Baseline, o.HasBaseline = baseline, hasBaseline
+	return &Result{
+		Condition:   cond,
+		Observation: o,
+		Observed:    true,
+		Symbol:      m.Symbol,
+		Name:        m.Name,
+		Holds:       cond.Holds(o),
+		ObservedAt:  now,
+	}, nil
+}
+
+// evaluateExpression evaluates the expression of given alert.
+// The observation of the result is the market data of the alert's pair if observed.
+func (e *Evaluator) evaluateExpression(ctx context.Context, a *model.Alert, address string, markets map[string]*Market, now time.Time) (*Result, error) {
+	x, err := ParseAlertExpression(a)
+	if err != nil {
+		return nil, err
+	}
+	holds, err := x.holds(ctx, e, markets, now)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		o            Observation
+		symbol, name string
+	)
+	m, observed := markets[address]
+	if observed {
+		o = observationOf(m)
+		symbol, name = m.Symbol, m.Name
+	}
+	return &Result{
+		Condition:   &Condition{Type: ConditionExpression},
+		Observation: o,
+		Observed:    observed,
+		Symbol:      symbol,
+		Name:        name,
+		Holds:       holds,
+		ObservedAt:  now,
+	}, nil
+}
+
+// baseline returns the price the condition compares observations with.
+//
+//	percent_change_window: the first price observed within the window
+//	percent_change_since_creation: the price captured at the alert's creation
+func (e *Evaluator) baseline(ctx context.Context, a *model.Alert, address string, cond *Condition, now time.Time) (float64, bool, error) {
+	switch cond.Type {
+	case ConditionPercentChangeWindow:
+		return e.windowBaseline(ctx, address, cond.Window, now)
+	case ConditionPercentChangeSinceCreation:
+		if a.BaselinePrice == nil {
+			return 0, false, nil
+		}
+		return *a.BaselinePrice, true, nil
+	}
+	return 0, false, nil
+}
+
+// windowBaseline returns the first price of a token with given normalized address observed within the window
+func (e *Evaluator) windowBaseline(ctx context.Context, address string, window time.Duration, now time.Time) (float64, bool, error) {
+	snapshot, err := e.tokenDB.FindFirstPriceSnapshot(ctx, address, now.Add(-window))
+	if err != nil {
+		if database.IsRecordNotFoundErr(err) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return snapshot.PriceUSD, true, nil
+}
+
+// observationOf returns an observation of given market data without previous and baseline prices
+func observationOf(m *Market) Observation {
+	return Observation{
+		Price:         m.Price,
+		Liquidity:     m.Liquidity,
+		HasLiquidity:  m.HasLiquidity,
+		Volume:        m.Volume,
+		AverageVolume: m.AverageVolume,
+		HasVolume:     m.HasVolume,
+	}
+}
+
+func NewEvaluator(tokenDB tokenDB.TokenDB) *Evaluator {
+	return &Evaluator{
+		tokenDB: tokenDB,
+	}
+}
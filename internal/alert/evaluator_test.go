@@ -0,0 +1,162 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/database"
+	tokenDBMock "kek-backend/internal/token/database/mocks"
+	tokenModel "kek-backend/internal/token/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestEvaluator_PercentChangeWindow(t *testing.T) {
+	now := time.Now()
+	a := newConditionAlert("percent_change_window", "above", "10", "1h")
+	db := &tokenDBMock.TokenDB{}
+	db.On("FindFirstPriceSnapshot", mock.Anything, a.PairAddress, now.Add(-time.Hour)).
+		Return(&tokenModel.PriceSnapshot{TokenID: a.PairAddress, PriceUSD: 1}, nil)
+
+	result, err := NewEvaluator(db).Evaluate(context.Background(), a, markets(a.PairAddress, 1.1), now)
+
+	assert.NoError(t, err)
+	assert.True(t, result.Holds)
+	assert.Equal(t, 1.0, result.Observation.Baseline)
+}
+
+func TestEvaluator_PercentChangeWindowWithoutHistory(t *testing.T) {
+	now := time.Now()
+	a := newConditionAlert("percent_change_window", "moves", "10", "15m")
+	db := &tokenDBMock.TokenDB{}
+	db.On("FindFirstPriceSnapshot", mock.Anything, a.PairAddress, mock.Anything).Return(nil, database.ErrNotFound)
+
+	result, err := NewEvaluator(db).Evaluate(context.Background(), a, markets(a.PairAddress, 2), now)
+
+	assert.NoError(t, err)
+	assert.False(t, result.Holds)
+	assert.False(t, result.Observation.HasBaseline)
+}
+
+func TestEvaluator_PercentChangeSinceCreation(t *testing.T) {
+	a := newConditionAlert("percent_change_since_creation", "below", "20", "")
+	baseline := 1.0
+	a.BaselinePrice = &baseline
+	db := &tokenDBMock.TokenDB{}
+
+	result, err := NewEvaluator(db).Evaluate(context.Background(), a, markets(a.PairAddress, 0.75), time.Now())
+
+	assert.NoError(t, err)
+	assert.True(t, result.Holds)
+	db.AssertNotCalled(t, "FindFirstPriceSnapshot", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func newConditionAlert(alertType, option, value, window string) *model.Alert {
+	alert := dAlert
+	alert.AlertType = alertType
+	alert.AlertOption = option
+	alert.AlertValue = value
+	alert.AlertWindow = window
+	return &alert
+}
+
+func TestEvaluator_CrossesWithLastObservedPrice(t *testing.T) {
+	a := newConditionAlert("price", "crosses_above", "1", "")
+	evaluator := NewEvaluator(&tokenDBMock.TokenDB{})
+	now := time.Now()
+
+	// when no price was observed for the alert
+	result, err := evaluator.Evaluate(context.Background(), a, markets(a.PairAddress, 1.1), now)
+	assert.NoError(t, err)
+	assert.False(t, result.Holds)
+	assert.True(t, result.Observed)
+	assert.Equal(t, now, result.ObservedAt)
+
+	// when the last observed price was below the threshold
+	last := 0.9
+	a.LastObservedPrice = &last
+	result, err = evaluator.Evaluate(context.Background(), a, markets(a.PairAddress, 1.1), now)
+	assert.NoError(t, err)
+	assert.True(t, result.Holds)
+	assert.Equal(t, 0.9, result.Observation.Previous)
+}
+
+func TestEvaluator_FailIfMarketNotFound(t *testing.T) {
+	a := newConditionAlert("price", "above", "1", "")
+
+	result, err := NewEvaluator(&tokenDBMock.TokenDB{}).Evaluate(context.Background(), a, map[string]*Market{}, time.Now())
+
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, ErrMarketNotFound))
+}
+
+func TestEvaluator_Expression(t *testing.T) {
+	now := time.Now()
+	quote := "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+	a := newConditionAlert("expression", "", "", "")
+	a.AlertExpression = &model.Expression{
+		Op: "and",
+		Conditions: []*model.Expression{
+			{Type: "price", Option: "above", Threshold: "2"},
+			{Op: "or", Conditions: []*model.Expression{
+				{Type: "liquidity", Option: "above", Threshold: "100000"},
+				{Type: "ratio", Option: "above", Threshold: "1.5", QuoteAddress: quote},
+			}},
+		},
+	}
+	cases := []struct {
+		Name    string
+		Markets map[string]*Market
+		Holds   bool
+	}{
+		{
+			Name: "Price and liquidity",
+			Markets: map[string]*Market{
+				a.PairAddress: {Price: 2.5, Liquidity: 200000, HasLiquidity: true},
+				quote:         {Price: 2},
+			},
+			Holds: true,
+		}, {
+			Name: "Price and ratio",
+			Markets: map[string]*Market{
+				a.PairAddress: {Price: 3, Liquidity: 1000, HasLiquidity: true},
+				quote:         {Price: 1.5},
+			},
+			Holds: true,
+		}, {
+			Name: "Neither liquidity nor ratio",
+			Markets: map[string]*Market{
+				a.PairAddress: {Price: 2.5, Liquidity: 1000, HasLiquidity: true},
+				quote:         {Price: 2},
+			},
+		}, {
+			Name: "Price not above",
+			Markets: map[string]*Market{
+				a.PairAddress: {Price: 1, Liquidity: 200000, HasLiquidity: true},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			result, err := NewEvaluator(&tokenDBMock.TokenDB{}).Evaluate(context.Background(), a, tc.Markets, now)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Holds, result.Holds)
+			assert.Equal(t, ConditionExpression, result.Condition.Type)
+		})
+	}
+
+	// the quote token is required only if the ratio is evaluated
+	_, err := NewEvaluator(&tokenDBMock.TokenDB{}).Evaluate(context.Background(), a, map[string]*Market{
+		a.PairAddress: {Price: 2.5, Liquidity: 1000, HasLiquidity: true},
+	}, now)
+	assert.True(t, errors.Is(err, ErrMarketNotFound))
+}
+
+func markets(address string, price float64) map[string]*Market {
+	return map[string]*Market{address: {Price: price}}
+}
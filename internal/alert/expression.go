@@ -0,0 +1,218 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/uniswap"
+)
+
+const (
+	// ConditionExpression is the alert type of alerts holding an expression of conditions
+	ConditionExpression = ConditionType("expression")
+	// ConditionRatio compares the price of a token divided by the price of a quote token
+	// with a threshold. It is only supported in expressions
+	ConditionRatio = ConditionType("ratio")
+)
+
+const (
+	ExpressionAnd = "and"
+	ExpressionOr  = "or"
+
+	maxExpressionDepth      = 3
+	maxExpressionConditions = 8
+)
+
+// expressionOperators are the conditions supported as leaves of expressions.
+// Conditions comparing consecutive observations or a baseline at creation are not supported
+// since an expression remembers nothing between evaluations.
+var expressionOperators = map[ConditionType][]Operator{
+	ConditionPrice:               {OperatorAbove, OperatorBelow},
+	ConditionRatio:               {OperatorAbove, OperatorBelow},
+	ConditionLiquidity:           {OperatorAbove, OperatorBelow},
+	ConditionVolumeSpike:         {OperatorAbove},
+	ConditionPercentChangeWindow: {OperatorAbove, OperatorBelow, OperatorMoves},
+}
+
+// Expr is a parsed form of an alert expression.
+// Op is empty for a leaf holding Condition. Addresses of leaves are normalized.
+type Expr struct {
+	Op           string
+	Args         []*Expr
+	Condition    *Condition
+	PairAddress  string
+	QuoteAddress string
+	// Field is the path of the node in the request used for error details
+	Field string
+}
+
+// IsExpressionAlert returns true if given alert holds an expression instead of a single condition
+func IsExpressionAlert(a *model.Alert) bool {
+	return ConditionType(strings.ToLower(strings.TrimSpace(a.AlertType))) == ConditionExpression
+}
+
+// ParseAlertExpression parses an expression from given alert
+func ParseAlertExpression(a *model.Alert) (*Expr, error) {
+	return ParseExpression(a.AlertExpression, a.PairAddress)
+}
+
+// ParseExpression parses given expression. Leaves without a pair address watch given pair address.
+// ConditionError is returned with the path of the invalid field, e.g. alertExpression.conditions[1].value
+func ParseExpression(e *model.Expression, pairAddress string) (*Expr, error) {
+	if e == nil {
+		return nil, &ConditionError{Field: "alertExpression", Message: fmt.Sprintf("alertExpression is required for %s", ConditionExpression)}
+	}
+	leaves := 0
+	return parseExpression(e, pairAddress, "alertExpression", 1, &leaves)
+}
+
+func parseExpression(e *model.Expression, pairAddress, field string, depth int, leaves *int) (*Expr, error) {
+	if e == nil {
+		return nil, &ConditionError{Field: field, Message: "condition must not be null"}
+	}
+	if depth > maxExpressionDepth {
+		return nil, &ConditionError{Field: field, Message: fmt.Sprintf("expression must not be nested deeper than %d", maxExpressionDepth)}
+	}
+
+	op := strings.ToLower(strings.TrimSpace(e.Op))
+	switch op {
+	case ExpressionAnd, ExpressionOr:
+		if e.Type != "" {
+			return nil, &ConditionError{Field: field + ".type", Value: e.Type, Message: "type is not allowed with op"}
+		}
+		if len(e.Conditions) < 2 {
+			return nil, &ConditionError{Field: field + ".conditions", Value: fmt.Sprint(len(e.Conditions)), Message: fmt.Sprintf("%s requires at least 2 conditions", op)}
+		}
+		x := &Expr{Op: op, Field: field}
+		for i, c := range e.Conditions {
+			arg, err := parseExpression(c, pairAddress, fmt.Sprintf("%s.conditions[%d]", field, i), depth+1, leaves)
+			if err != nil {
+				return nil, err
+			}
+			x.Args = append(x.Args, arg)
+		}
+		return x, nil
+	case "":
+		return parseExpressionLeaf(e, pairAddress, field, leaves)
+	}
+	return nil, &ConditionError{Field: field + ".op", Value: e.Op, Message: "op must be and or or"}
+}
+
+func parseExpressionLeaf(e *model.Expression, pairAddress, field string, leaves *int) (*Expr, error) {
+	*leaves++
+	if *leaves > maxExpressionConditions {
+		return nil, &ConditionError{Field: field, Message: fmt.Sprintf("expression must not have more than %d conditions", maxExpressionConditions)}
+	}
+	if len(e.Conditions) != 0 {
+		return nil, &ConditionError{Field: field + ".conditions", Message: "conditions require op"}
+	}
+	cond, err := parseCondition(e.Type, e.Option, e.Threshold, e.Window, expressionOperators, conditionFields{
+		Type:   field + ".type",
+		Option: field + ".option",
+		Value:  field + ".value",
+		Window: field + ".window",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	x := &Expr{Condition: cond, Field: field}
+	pair := e.PairAddress
+	if pair == "" {
+		pair = pairAddress
+	}
+	if x.PairAddress, err = uniswap.NormalizeAddress(pair); err != nil {
+		return nil, &ConditionError{Field: field + ".pairAddress", Value: pair, Message: "pairAddress must be 0x followed by 40 hex characters"}
+	}
+	switch {
+	case cond.Type == ConditionRatio && e.QuoteAddress == "":
+		return nil, &ConditionError{Field: field + ".quoteAddress", Message: "quoteAddress is required for ratio"}
+	case cond.Type == ConditionRatio:
+		if x.QuoteAddress, err = uniswap.NormalizeAddress(e.QuoteAddress); err != nil {
+			return nil, &ConditionError{Field: field + ".quoteAddress", Value: e.QuoteAddress, Message: "quoteAddress must be 0x followed by 40 hex characters"}
+		}
+		if x.QuoteAddress == x.PairAddress {
+			return nil, &ConditionError{Field: field + ".quoteAddress", Value: e.QuoteAddress, Message: "quoteAddress must differ from pairAddress"}
+		}
+	case e.QuoteAddress != "":
+		return nil, &ConditionError{Field: field + ".quoteAddress", Value: e.QuoteAddress, Message: fmt.Sprintf("quoteAddress is not allowed for %s", cond.Type)}
+	}
+	return x, nil
+}
+
+// Leaves returns the leaf conditions of the expression in order
+func (x *Expr) Leaves() []*Expr {
+	if x.Op == "" {
+		return []*Expr{x}
+	}
+	var ret []*Expr
+	for _, arg := range x.Args {
+		ret = append(ret, arg.Leaves()...)
+	}
+	return ret
+}
+
+// Addresses returns distinct addresses watched by the expression in order
+func (x *Expr) Addresses() []string {
+	var ret []string
+	seen := make(map[string]bool)
+	for _, leaf := range x.Leaves() {
+		for _, address := range []string{leaf.PairAddress, leaf.QuoteAddress} {
+			if address == "" || seen[address] {
+				continue
+			}
+			seen[address] = true
+			ret = append(ret, address)
+		}
+	}
+	return ret
+}
+
+// holds evaluates the expression with given market data keyed by normalized address.
+// An error is returned if the market data of any watched token is missing
+func (x *Expr) holds(ctx context.Context, e *Evaluator, markets map[string]*Market, now time.Time) (bool, error) {
+	switch x.Op {
+	case ExpressionAnd, ExpressionOr:
+		for _, arg := range x.Args {
+			holds, err := arg.holds(ctx, e, markets, now)
+			if err != nil {
+				return false, err
+			}
+			// short circuit
+			if x.Op == ExpressionAnd && !holds {
+				return false, nil
+			}
+			if x.Op == ExpressionOr && holds {
+				return true, nil
+			}
+		}
+		return x.Op == ExpressionAnd, nil
+	}
+
+	m, ok := markets[x.PairAddress]
+	if !ok {
+		return false, fmt.Errorf("%w: %s", ErrMarketNotFound, x.PairAddress)
+	}
+	o := observationOf(m)
+	switch x.Condition.Type {
+	case ConditionRatio:
+		q, ok := markets[x.QuoteAddress]
+		if !ok {
+			return false, fmt.Errorf("%w: %s", ErrMarketNotFound, x.QuoteAddress)
+		}
+		if q.Price == 0 {
+			return false, nil
+		}
+		o.Price = m.Price / q.Price
+	case ConditionPercentChangeWindow:
+		baseline, ok, err := e.windowBaseline(ctx, x.PairAddress, x.Condition.Window, now)
+		if err != nil {
+			return false, err
+		}
+		o.Baseline, o.HasBaseline = baseline, ok
+	}
+	return x.Condition.Holds(o), nil
+}
@@ -0,0 +1,122 @@
+package alert
+
+import (
+	"kek-backend/internal/alert/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	dPair  = "0x6b175474e89094c44da98b954eedeac495271d0f"
+	dQuote = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+)
+
+func TestParseExpression(t *testing.T) {
+	// given
+	e := &model.Expression{
+		Op: "AND",
+		Conditions: []*model.Expression{
+			{Type: "price", Option: "above", Threshold: "2"},
+			{Type: "ratio", Option: "below", Threshold: "0.5", QuoteAddress: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"},
+		},
+	}
+
+	// when
+	x, err := ParseExpression(e, dPair)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, ExpressionAnd, x.Op)
+	leaves := x.Leaves()
+	assert.Len(t, leaves, 2)
+	assert.Equal(t, &Condition{Type: ConditionPrice, Operator: OperatorAbove, Threshold: 2}, leaves[0].Condition)
+	assert.Equal(t, dPair, leaves[0].PairAddress)
+	assert.Equal(t, dQuote, leaves[1].QuoteAddress)
+	assert.Equal(t, "alertExpression.conditions[1]", leaves[1].Field)
+	assert.Equal(t, []string{dPair, dQuote}, x.Addresses())
+}
+
+func TestParseExpression_Fail(t *testing.T) {
+	price := func() *model.Expression {
+		return &model.Expression{Type: "price", Option: "above", Threshold: "1"}
+	}
+	nested := func(depth int) *model.Expression {
+		e := price()
+		for i := 0; i < depth; i++ {
+			e = &model.Expression{Op: "or", Conditions: []*model.Expression{e, price()}}
+		}
+		return e
+	}
+	var many []*model.Expression
+	for i := 0; i < maxExpressionConditions+1; i++ {
+		many = append(many, price())
+	}
+
+	cases := []struct {
+		Name       string
+		Expression *model.Expression
+		// expected
+		Field string
+	}{
+		{
+			Name:  "Missing expression",
+			Field: "alertExpression",
+		}, {
+			Name:       "Unknown op",
+			Expression: &model.Expression{Op: "xor", Conditions: []*model.Expression{price(), price()}},
+			Field:      "alertExpression.op",
+		}, {
+			Name:       "Single condition",
+			Expression: &model.Expression{Op: "and", Conditions: []*model.Expression{price()}},
+			Field:      "alertExpression.conditions",
+		}, {
+			Name: "Invalid value of nested condition",
+			Expression: &model.Expression{Op: "and", Conditions: []*model.Expression{
+				price(),
+				{Type: "price", Option: "above", Threshold: "one"},
+			}},
+			Field: "alertExpression.conditions[1].value",
+		}, {
+			Name: "Unsupported type in expression",
+			Expression: &model.Expression{Op: "and", Conditions: []*model.Expression{
+				price(),
+				{Type: "percent_change_since_creation", Option: "above", Threshold: "1"},
+			}},
+			Field: "alertExpression.conditions[1].type",
+		}, {
+			Name: "Ratio without quote",
+			Expression: &model.Expression{Op: "and", Conditions: []*model.Expression{
+				price(),
+				{Type: "ratio", Option: "above", Threshold: "1"},
+			}},
+			Field: "alertExpression.conditions[1].quoteAddress",
+		}, {
+			Name: "Invalid pair address",
+			Expression: &model.Expression{Op: "and", Conditions: []*model.Expression{
+				{Type: "price", Option: "above", Threshold: "1", PairAddress: "0x1"},
+				price(),
+			}},
+			Field: "alertExpression.conditions[0].pairAddress",
+		}, {
+			Name:       "Too deep",
+			Expression: nested(maxExpressionDepth),
+			Field:      "alertExpression.conditions[0].conditions[0].conditions[0]",
+		}, {
+			Name:       "Too many conditions",
+			Expression: &model.Expression{Op: "and", Conditions: many},
+			Field:      "alertExpression.conditions[8]",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			x, err := ParseExpression(tc.Expression, dPair)
+
+			assert.Nil(t, x)
+			cErr, ok := err.(*ConditionError)
+			assert.True(t, ok)
+			assert.Equal(t, tc.Field, cErr.Field)
+		})
+	}
+}
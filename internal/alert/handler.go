@@ -2,6 +2,7 @@ package alert
 
 import (
 	"context"
+	"fmt"
 	"kek-backend/internal/account"
 	alertDB "kek-backend/internal/alert/database"
 	"kek-backend/internal/alert/model"
@@ -9,10 +10,13 @@ import (
 	"kek-backend/internal/database"
 	"kek-backend/internal/middleware"
 	"kek-backend/internal/middleware/handler"
+	"kek-backend/internal/token"
+	"kek-backend/internal/uniswap"
 	"kek-backend/pkg/logging"
 	"kek-backend/pkg/validate"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	jwt "github.com/appleboy/gin-jwt/v2"
@@ -23,7 +27,96 @@ import (
 )
 
 type Handler struct {
-	alertDB alertDB.AlertDB
+	alertDB    alertDB.AlertDB
+	resolver   *token.Resolver
+	prices     *uniswap.PriceCache
+	dispatcher *Dispatcher
+	// testFireLimit is the number of test fires allowed per account in testFireWindow
+	testFireLimit  int64
+	testFireWindow time.Duration
+}
+
+// parseCondition validates the condition or the expression of an alert watching given normalized pair address.
+// Tokens watched by the expression must resolve to known tokens.
+// ConditionError is returned if the condition is invalid.
+func (h *Handler) parseCondition(ctx context.Context, alertType, option, value, window string, expression *model.Expression, pairAddress string) (*Condition, error) {
+	if ConditionType(strings.ToLower(strings.TrimSpace(alertType))) != ConditionExpression {
+		if expression != nil {
+			return nil, &ConditionError{Field: "alertExpression", Message: fmt.Sprintf("alertExpression is only allowed for %s", ConditionExpression)}
+		}
+		// checked here instead of binding since the alert type is case insensitive
+		if strings.TrimSpace(option) == "" {
+			return nil, &ConditionError{Field: "alertOption", Message: "alertOption is required"}
+		}
+		if strings.TrimSpace(value) == "" {
+			return nil, &ConditionError{Field: "alertValue", Message: "alertValue is required"}
+		}
+		return ParseCondition(alertType, option, value, window)
+	}
+	if option != "" || value != "" || window != "" {
+		return nil, &ConditionError{Field: "alertType", Value: alertType, Message: "alertOption, alertValue and alertWindow are not allowed for expression"}
+	}
+	x, err := ParseExpression(expression, pairAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := map[string]bool{pairAddress: true}
+	for _, leaf := range x.Leaves() {
+		for _, f := range []struct{ field, address string }{{"pairAddress", leaf.PairAddress}, {"quoteAddress", leaf.QuoteAddress}} {
+			field, address := f.field, f.address
+			if address == "" || resolved[address] {
+				continue
+			}
+			if _, err := h.resolver.Resolve(ctx, address); err != nil {
+				if errors.Is(err, token.ErrUnknownToken) {
+					return nil, &ConditionError{Field: leaf.Field + "." + field, Value: address, Message: field + " is not a known token"}
+				}
+				return nil, err
+			}
+			resolved[address] = true
+		}
+	}
+	return &Condition{Type: ConditionExpression}, nil
+}
+
+// findOwnedAlert returns an alert with given slug owned by the current user.
+// An error response is returned if the alert does not exist or is owned by another user.
+func (h *Handler) findOwnedAlert(c *gin.Context, slug string) (*model.Alert, *handler.Response) {
+	alert, err := h.alertDB.FindAlertBySlug(c.Request.Context(), slug)
+	if err != nil {
+		if database.IsRecordNotFoundErr(err) {
+			return nil, handler.NewErrorResponse(http.StatusNotFound, handler.NotFoundEntity, "not found alert", nil)
+		}
+		return nil, handler.NewInternalErrorResponse(err)
+	}
+	if alert.AccountId != account.MustCurrentUser(c).ID {
+		return nil, handler.NewErrorResponse(http.StatusForbidden, handler.Forbidden, "not allowed to change alert", nil)
+	}
+	return alert, nil
+}
+
+// currentPrice returns the current USD price of a token with given address
+func (h *Handler) currentPrice(ctx context.Context, address string) (float64, error) {
+	_, price, err := h.currentToken(ctx, address)
+	return price, err
+}
+
+// currentToken returns a token with given address and its current USD price
+func (h *Handler) currentToken(ctx context.Context, address string) (*uniswap.Token, float64, error) {
+	ethPrice, err := h.prices.EthPrice(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	t, err := h.prices.Token(ctx, address)
+	if err != nil {
+		return nil, 0, err
+	}
+	price, err := t.PriceUSD(ethPrice)
+	if err != nil {
+		return nil, 0, err
+	}
+	return t, price, nil
 }
 
 // saveAlert handles POST /v1/api/alerts
@@ -33,14 +126,19 @@ func (h *Handler) saveAlert(c *gin.Context) {
 		// bind
 		type RequestBody struct {
 			Alert struct {
-				Title          string    `json:"title" binding:"required,min=5"`
-				Body           string    `json:"body" binding:"required"`
-				PairAddress    string    `json:"pairAddress" binding:"required,min=20"`
-				AlertType      string    `json:"alertType" binding:"required,min=3"`
-				AlertValue     string    `json:"alertValue" binding:"required"`
-				AlertOption    string    `json:"alertOption" binding:"required"`
-				ExpirationTime time.Time `json:"expirationTime" binding:"required"`
-				AlertActions   string    `json:"alertActions" binding:"required"`
+				Title           string             `json:"title" binding:"required,min=5"`
+				Body            string             `json:"body" binding:"required"`
+				PairAddress     string             `json:"pairAddress" binding:"required,min=20"`
+				AlertType       string             `json:"alertType" binding:"required,min=3"`
+				AlertValue      string             `json:"alertValue"`
+				AlertOption     string             `json:"alertOption"`
+				AlertWindow     string             `json:"alertWindow"`
+				AlertExpression *model.Expression  `json:"alertExpression"`
+				ExpirationTime  time.Time          `json:"expirationTime" binding:"required"`
+				AlertActions    model.AlertActions `json:"alertActions" binding:"required"`
+				RearmPolicy     string             `json:"rearmPolicy" binding:"omitempty,oneof=once auto cooldown band"`
+				CooldownMinutes int                `json:"cooldownMinutes"`
+				Hysteresis      float64            `json:"hysteresis"`
 			} `json:"alert"`
 		}
 		var body RequestBody
@@ -52,23 +150,100 @@ func (h *Handler) saveAlert(c *gin.Context) {
 			}
 			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid alert request in body", details)
 		}
+		resolved, err := h.resolver.Resolve(c.Request.Context(), body.Alert.PairAddress)
+		if err != nil {
+			logger.Errorw("alert.handler.saveAlert failed to resolve pair address", "err", err)
+			var message string
+			switch {
+			case errors.Is(err, uniswap.ErrInvalidAddress):
+				message = "pairAddress must be 0x followed by 40 hex characters"
+			case errors.Is(err, token.ErrUnknownToken):
+				message = "pairAddress is not a known token"
+			default:
+				return handler.NewInternalErrorResponse(err)
+			}
+			details := validate.NewValidationErrorDetails("pairAddress", message, body.Alert.PairAddress)
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid alert request in body", details)
+		}
+		cond, err := h.parseCondition(c.Request.Context(), body.Alert.AlertType, body.Alert.AlertOption, body.Alert.AlertValue,
+			body.Alert.AlertWindow, body.Alert.AlertExpression, resolved.ID)
+		if err != nil {
+			logger.Errorw("alert.handler.saveAlert invalid condition", "err", err)
+			cErr, ok := err.(*ConditionError)
+			if !ok {
+				return handler.NewInternalErrorResponse(err)
+			}
+			details := validate.NewValidationErrorDetails(cErr.Field, cErr.Message, cErr.Value)
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid alert condition in body", details)
+		}
+		if err := ValidateTemplates(body.Alert.Title, body.Alert.Body); err != nil {
+			logger.Errorw("alert.handler.saveAlert invalid templates", "err", err)
+			var details []*validate.ValidationErrDetail
+			if tErr, ok := err.(*TemplateError); ok {
+				details = validate.NewValidationErrorDetails(tErr.Field, tErr.Message, tErr.Value)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid alert template in body", details)
+		}
+		if err := ValidateActions(body.Alert.AlertActions); err != nil {
+			logger.Errorw("alert.handler.saveAlert invalid actions", "err", err)
+			var details []*validate.ValidationErrDetail
+			if aErr, ok := err.(*ActionError); ok {
+				details = validate.NewValidationErrorDetails(aErr.Field, aErr.Message, aErr.Value)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid alert actions in body", details)
+		}
+
+		now := time.Now()
+		if !body.Alert.ExpirationTime.After(now) {
+			details := validate.NewValidationErrorDetails("expirationTime", "expirationTime must be in the future", body.Alert.ExpirationTime)
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid alert request in body", details)
+		}
+		rearmPolicy := body.Alert.RearmPolicy
+		if rearmPolicy == "" {
+			rearmPolicy = model.RearmOnce
+		}
+		if err := ValidateRearm(rearmPolicy, body.Alert.CooldownMinutes, body.Alert.Hysteresis); err != nil {
+			logger.Errorw("alert.handler.saveAlert invalid rearm policy", "err", err)
+			var details []*validate.ValidationErrDetail
+			if cErr, ok := err.(*ConditionError); ok {
+				details = validate.NewValidationErrorDetails(cErr.Field, cErr.Message, cErr.Value)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid alert request in body", details)
+		}
+		// the baseline of a since creation condition is the price at this moment
+		var baselinePrice *float64
+		if cond.Type == ConditionPercentChangeSinceCreation {
+			price, err := h.currentPrice(c.Request.Context(), resolved.ID)
+			if err != nil {
+				logger.Errorw("alert.handler.saveAlert failed to get baseline price", "pairAddress", resolved.ID, "err", err)
+				return handler.NewInternalErrorResponse(err)
+			}
+			baselinePrice = &price
+		}
 
 		// save alert
 		currentUser := account.MustCurrentUser(c)
 		alert := model.Alert{
-			Slug:           slug.Make(body.Alert.Title),
-			Title:          body.Alert.Title,
-			Body:           body.Alert.Body,
-			PairAddress:    body.Alert.PairAddress,
-			AlertType:      body.Alert.AlertType,
-			AlertValue:     body.Alert.AlertValue,
-			AlertOption:    body.Alert.AlertOption,
-			ExpirationTime: body.Alert.ExpirationTime,
-			AlertActions:   body.Alert.AlertActions,
-			AlertStatus:    "active",
-			AccountId:      currentUser.ID,
-		}
-		err := h.alertDB.SaveAlert(c.Request.Context(), &alert)
+			Slug:            slug.Make(body.Alert.Title),
+			Title:           body.Alert.Title,
+			Body:            body.Alert.Body,
+			PairAddress:     resolved.ID,
+			AlertType:       body.Alert.AlertType,
+			AlertValue:      body.Alert.AlertValue,
+			AlertOption:     body.Alert.AlertOption,
+			AlertWindow:     strings.TrimSpace(body.Alert.AlertWindow),
+			BaselinePrice:   baselinePrice,
+			AlertExpression: body.Alert.AlertExpression,
+			ExpirationTime:  body.Alert.ExpirationTime,
+			AlertActions:    body.Alert.AlertActions,
+			AlertStatus:     model.AlertStatusActive,
+			RearmPolicy:     rearmPolicy,
+			CooldownMinutes: body.Alert.CooldownMinutes,
+			Hysteresis:      body.Alert.Hysteresis,
+			StatusChangedAt: &now,
+			AccountId:       currentUser.ID,
+		}
+		err = h.alertDB.SaveAlert(c.Request.Context(), &alert)
 		if err != nil {
 			if database.IsKeyConflictErr(err) {
 				return handler.NewErrorResponse(http.StatusConflict, handler.DuplicateEntry, "duplicate alert title", nil)
@@ -105,7 +280,7 @@ func (h *Handler) alertBySlug(c *gin.Context) {
 			}
 			return handler.NewInternalErrorResponse(err)
 		}
-		return handler.NewSuccessResponse(http.StatusOK, NewAlertResponse(alert))
+		return handler.NewSuccessResponse(http.StatusOK, NewPublicAlertResponse(alert))
 	})
 }
 
@@ -151,6 +326,150 @@ func (h *Handler) alerts(c *gin.Context) {
 	})
 }
 
+// updateAlert handles PUT /v1/api/alerts/:slug
+// Only given fields are updated. The alert is re-slugged if the title changes and
+// a triggered alert is re-armed if its condition changes.
+func (h *Handler) updateAlert(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		logger := logging.FromContext(c)
+		// bind
+		type RequestUri struct {
+			Slug string `uri:"slug" binding:"required"`
+		}
+		var uri RequestUri
+		if err := c.ShouldBindUri(&uri); err != nil {
+			logger.Errorw("alert.handler.updateAlert failed to bind", "err", err)
+			var details []*validate.ValidationErrDetail
+			if vErrs, ok := err.(validator.ValidationErrors); ok {
+				details = validate.ValidationErrorDetails(&uri, "uri", vErrs)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidUriValue, "invalid alert request in uri", details)
+		}
+		type RequestBody struct {
+			Alert struct {
+				Title          *string            `json:"title" binding:"omitempty,min=5"`
+				Body           *string            `json:"body" binding:"omitempty,min=1"`
+				AlertValue     *string            `json:"alertValue" binding:"omitempty,min=1"`
+				AlertOption    *string            `json:"alertOption" binding:"omitempty,min=1"`
+				ExpirationTime *time.Time         `json:"expirationTime"`
+				AlertActions   model.AlertActions `json:"alertActions"`
+			} `json:"alert"`
+		}
+		var body RequestBody
+		if err := c.ShouldBindJSON(&body); err != nil {
+			logger.Errorw("alert.handler.updateAlert failed to bind", "err", err)
+			var details []*validate.ValidationErrDetail
+			if vErrs, ok := err.(validator.ValidationErrors); ok {
+				details = validate.ValidationErrorDetails(&body.Alert, "json", vErrs)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid alert request in body", details)
+		}
+
+		// find
+		alert, res := h.findOwnedAlert(c, uri.Slug)
+		if res != nil {
+			return res
+		}
+
+		// apply changes
+		if body.Alert.Title != nil {
+			alert.Title = *body.Alert.Title
+			alert.Slug = slug.Make(alert.Title)
+		}
+		if body.Alert.Body != nil {
+			alert.Body = *body.Alert.Body
+		}
+		if err := ValidateTemplates(alert.Title, alert.Body); err != nil {
+			logger.Errorw("alert.handler.updateAlert invalid templates", "err", err)
+			var details []*validate.ValidationErrDetail
+			if tErr, ok := err.(*TemplateError); ok {
+				details = validate.NewValidationErrorDetails(tErr.Field, tErr.Message, tErr.Value)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid alert template in body", details)
+		}
+		conditionChanged := false
+		if body.Alert.AlertValue != nil && *body.Alert.AlertValue != alert.AlertValue {
+			alert.AlertValue = *body.Alert.AlertValue
+			conditionChanged = true
+		}
+		if body.Alert.AlertOption != nil && *body.Alert.AlertOption != alert.AlertOption {
+			alert.AlertOption = *body.Alert.AlertOption
+			conditionChanged = true
+		}
+		if conditionChanged {
+			_, err := h.parseCondition(c.Request.Context(), alert.AlertType, alert.AlertOption, alert.AlertValue,
+				alert.AlertWindow, alert.AlertExpression, alert.PairAddress)
+			if err != nil {
+				logger.Errorw("alert.handler.updateAlert invalid condition", "err", err)
+				cErr, ok := err.(*ConditionError)
+				if !ok {
+					return handler.NewInternalErrorResponse(err)
+				}
+				details := validate.NewValidationErrorDetails(cErr.Field, cErr.Message, cErr.Value)
+				return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid alert condition in body", details)
+			}
+		}
+		if body.Alert.AlertActions != nil {
+			if err := ValidateActions(body.Alert.AlertActions); err != nil {
+				logger.Errorw("alert.handler.updateAlert invalid actions", "err", err)
+				var details []*validate.ValidationErrDetail
+				if aErr, ok := err.(*ActionError); ok {
+					details = validate.NewValidationErrorDetails(aErr.Field, aErr.Message, aErr.Value)
+				}
+				return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid alert actions in body", details)
+			}
+			alert.AlertActions = body.Alert.AlertActions
+		}
+		if body.Alert.ExpirationTime != nil {
+			if !body.Alert.ExpirationTime.After(time.Now()) {
+				details := validate.NewValidationErrorDetails("expirationTime", "expirationTime must be in the future", *body.Alert.ExpirationTime)
+				return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidBodyValue, "invalid alert request in body", details)
+			}
+			alert.ExpirationTime = *body.Alert.ExpirationTime
+		}
+
+		// update alert in transaction
+		err := h.alertDB.RunInTx(c.Request.Context(), func(ctx context.Context) error {
+			if err := h.alertDB.UpdateAlert(ctx, alert); err != nil {
+				return err
+			}
+			if !conditionChanged {
+				return nil
+			}
+			// crossing conditions start without the observation made for the previous condition
+			if err := h.alertDB.ClearAlertObservation(ctx, alert.ID); err != nil {
+				return err
+			}
+			// alerts fired with the previous condition are re-armed
+			if alert.AlertStatus != model.AlertStatusTriggered && alert.AlertStatus != model.AlertStatusCompleted {
+				return nil
+			}
+			// the alert may have moved on by the cron in the meantime
+			err := h.alertDB.TransitAlertStatus(ctx, alert.ID, alert.AlertStatus, model.AlertStatusActive)
+			if err != nil && !database.IsRecordNotFoundErr(err) {
+				return err
+			}
+			return nil
+		})
+		if err != nil {
+			logger.Errorw("alert.handler.updateAlert failed to update a alert", "err", err)
+			switch {
+			case database.IsRecordNotFoundErr(errors.Cause(err)):
+				return handler.NewErrorResponse(http.StatusNotFound, handler.NotFoundEntity, "not found alert", nil)
+			case database.IsKeyConflictErr(errors.Cause(err)):
+				return handler.NewErrorResponse(http.StatusConflict, handler.DuplicateEntry, "duplicate alert title", nil)
+			}
+			return handler.NewInternalErrorResponse(err)
+		}
+
+		updated, err := h.alertDB.FindAlertBySlug(c.Request.Context(), alert.Slug)
+		if err != nil {
+			return handler.NewInternalErrorResponse(err)
+		}
+		return handler.NewSuccessResponse(http.StatusOK, NewAlertResponse(updated))
+	})
+}
+
 // deleteAlert handles DELETE /v1/api/alerts/:slug
 func (h *Handler) deleteAlert(c *gin.Context) {
 	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
@@ -208,13 +527,40 @@ func RouteV1(cfg *config.Config, h *Handler, r *gin.Engine, auth *jwt.GinJWTMidd
 	alertV1.Use(auth.MiddlewareFunc())
 	{
 		alertV1.POST("", h.saveAlert)
+		alertV1.PUT(":slug", h.updateAlert)
 		alertV1.DELETE(":slug", h.deleteAlert)
+		alertV1.POST(":slug/pause", h.pauseAlert)
+		alertV1.POST(":slug/resume", h.resumeAlert)
+		alertV1.POST(":slug/snooze", h.snoozeAlert)
+		alertV1.POST(":slug/test", h.testAlert)
+		alertV1.GET(":slug/events", h.alertEvents)
+	}
+
+	userV1 := v1.Group("user/alerts")
+	// auth required
+	userV1.Use(auth.MiddlewareFunc())
+	{
+		userV1.POST("pause", h.pauseAccountAlerts)
+		userV1.POST("resume", h.resumeAccountAlerts)
+	}
+
+	adminV1 := v1.Group("admin")
+	// admin only
+	adminV1.Use(auth.MiddlewareFunc(), account.AdminMiddleware(cfg))
+	{
+		adminV1.GET("deliveries", h.deliveries)
+		adminV1.POST("deliveries/:id/replay", h.replayDelivery)
 	}
 }
 
-func NewHandler(alertDB alertDB.AlertDB) *Handler {
-	StartCron(alertDB)
+func NewHandler(cfg *config.Config, alertDB alertDB.AlertDB, resolver *token.Resolver,
+	prices *uniswap.PriceCache, dispatcher *Dispatcher) *Handler {
 	return &Handler{
-		alertDB: alertDB,
+		alertDB:        alertDB,
+		resolver:       resolver,
+		prices:         prices,
+		dispatcher:     dispatcher,
+		testFireLimit:  int64(cfg.AlertConfig.TestFire.Limit),
+		testFireWindow: time.Duration(cfg.AlertConfig.TestFire.WindowSecs) * time.Second,
 	}
 }
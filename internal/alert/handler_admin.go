@@ -0,0 +1,84 @@
+package alert
+
+import (
+	alertDB "kek-backend/internal/alert/database"
+	"kek-backend/internal/database"
+	"kek-backend/internal/middleware/handler"
+	"kek-backend/pkg/logging"
+	"kek-backend/pkg/validate"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// deliveries handles GET /v1/api/admin/deliveries
+func (h *Handler) deliveries(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		logger := logging.FromContext(c)
+		type QueryParameter struct {
+			Status []string `form:"status" binding:"omitempty,dive,oneof=pending sent dead"`
+			Limit  string   `form:"limit,default=20" binding:"numeric"`
+			Offset string   `form:"offset,default=0" binding:"numeric"`
+		}
+		var query QueryParameter
+		if err := c.ShouldBindQuery(&query); err != nil {
+			logger.Errorw("alert.handler.deliveries failed to bind", "err", err)
+			var details []*validate.ValidationErrDetail
+			if vErrs, ok := err.(validator.ValidationErrors); ok {
+				details = validate.ValidationErrorDetails(&query, "form", vErrs)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidQueryValue, "invalid delivery request in query", details)
+		}
+
+		limit, err := strconv.ParseUint(query.Limit, 10, 64)
+		if err != nil || limit > 100 {
+			limit = 20
+		}
+		offset, err := strconv.ParseUint(query.Offset, 10, 64)
+		if err != nil {
+			offset = 0
+		}
+		criteria := alertDB.IterateDeliveryCriteria{
+			Statuses: query.Status,
+			Offset:   uint(offset),
+			Limit:    uint(limit),
+		}
+		deliveries, total, err := h.alertDB.FindDeliveries(c.Request.Context(), criteria)
+		if err != nil {
+			return handler.NewInternalErrorResponse(err)
+		}
+		return handler.NewSuccessResponse(http.StatusOK, NewDeliveriesResponse(deliveries, total))
+	})
+}
+
+// replayDelivery handles POST /v1/api/admin/deliveries/:id/replay
+func (h *Handler) replayDelivery(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		logger := logging.FromContext(c)
+		// bind
+		type RequestUri struct {
+			ID uint `uri:"id" binding:"required"`
+		}
+		var uri RequestUri
+		if err := c.ShouldBindUri(&uri); err != nil {
+			logger.Errorw("alert.handler.replayDelivery failed to bind", "err", err)
+			var details []*validate.ValidationErrDetail
+			if vErrs, ok := err.(validator.ValidationErrors); ok {
+				details = validate.ValidationErrorDetails(&uri, "uri", vErrs)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidUriValue, "invalid delivery request in uri", details)
+		}
+
+		// replay
+		if err := h.alertDB.ReplayDelivery(c.Request.Context(), uri.ID, time.Now()); err != nil {
+			if database.IsRecordNotFoundErr(err) {
+				return handler.NewErrorResponse(http.StatusNotFound, handler.NotFoundEntity, "not found replayable delivery", nil)
+			}
+			return handler.NewInternalErrorResponse(err)
+		}
+		return handler.NewSuccessResponse(http.StatusAccepted, nil)
+	})
+}
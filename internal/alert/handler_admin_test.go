@@ -0,0 +1,106 @@
+package alert
+
+import (
+	"fmt"
+	"kek-backend/internal/alert/database"
+	"kek-backend/internal/alert/model"
+	commonDB "kek-backend/internal/database"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/tidwall/gjson"
+)
+
+var (
+	dDelivery = model.Delivery{
+		ID:           1,
+		AlertEventID: dAlertEvent.ID,
+		AlertID:      dAlert.ID,
+		Channel:      model.ChannelWebhook,
+		Payload: model.DeliveryPayload{
+			Action: model.AlertAction{Type: model.ChannelWebhook, URL: "https://example.com/hook", Secret: "secret"},
+		},
+		Status:        model.DeliveryDead,
+		Attempts:      8,
+		NextAttemptAt: time.Now(),
+		LastError:     "unexpected status code: 500",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+)
+
+func (s *HandlerSuite) TestDeliveries() {
+	// given
+	criteria := database.IterateDeliveryCriteria{
+		Statuses: []string{model.DeliveryDead},
+		Offset:   0,
+		Limit:    10,
+	}
+	s.db.On("FindDeliveries", mock.Anything, criteria).Return([]*model.Delivery{&dDelivery}, int64(1), nil)
+
+	// when
+	url := fmt.Sprintf("/v1/api/admin/deliveries?status=dead&limit=%d", criteria.Limit)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", url, nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerTokenOf(dAdmin.Email))
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertCalled(s.T(), "FindDeliveries", mock.Anything, criteria)
+	s.Equal(http.StatusOK, res.Code)
+	result := gjson.Parse(res.Body.String())
+	s.Equal(int64(1), result.Get("deliveriesCount").Int())
+	deliveries := result.Get("deliveries").Array()
+	s.Equal(1, len(deliveries))
+	s.Equal(model.DeliveryDead, deliveries[0].Get("status").String())
+	s.Equal(dDelivery.LastError, deliveries[0].Get("lastError").String())
+	s.NotContains(res.Body.String(), "secret")
+}
+
+func (s *HandlerSuite) TestDeliveries_FailIfNotAdmin() {
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/admin/deliveries", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "FindDeliveries", mock.Anything, mock.Anything)
+	s.Equal(http.StatusForbidden, res.Code)
+	s.Equal("Forbidden", gjson.Get(res.Body.String(), "code").String())
+}
+
+func (s *HandlerSuite) TestReplayDelivery() {
+	// given
+	s.db.On("ReplayDelivery", mock.Anything, dDelivery.ID, mock.Anything).Return(nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", fmt.Sprintf("/v1/api/admin/deliveries/%d/replay", dDelivery.ID), nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerTokenOf(dAdmin.Email))
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertCalled(s.T(), "ReplayDelivery", mock.Anything, dDelivery.ID, mock.Anything)
+	s.Equal(http.StatusAccepted, res.Code)
+}
+
+func (s *HandlerSuite) TestReplayDelivery_FailIfNotFound() {
+	// given
+	s.db.On("ReplayDelivery", mock.Anything, uint(100), mock.Anything).Return(commonDB.ErrNotFound)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/admin/deliveries/100/replay", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerTokenOf(dAdmin.Email))
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusNotFound, res.Code)
+}
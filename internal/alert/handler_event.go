@@ -0,0 +1,73 @@
+package alert
+
+import (
+	alertDB "kek-backend/internal/alert/database"
+	"kek-backend/internal/middleware/handler"
+	"kek-backend/pkg/logging"
+	"kek-backend/pkg/validate"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// alertEvents handles GET /v1/api/alerts/:slug/events
+func (h *Handler) alertEvents(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		logger := logging.FromContext(c)
+		// bind
+		type RequestUri struct {
+			Slug string `uri:"slug" binding:"required"`
+		}
+		type QueryParameter struct {
+			Limit  string `form:"limit,default=20" binding:"numeric"`
+			Offset string `form:"offset,default=0" binding:"numeric"`
+		}
+		var (
+			uri   RequestUri
+			query QueryParameter
+		)
+		if err := c.ShouldBindUri(&uri); err != nil {
+			logger.Errorw("alert.handler.alertEvents failed to bind", "err", err)
+			var details []*validate.ValidationErrDetail
+			if vErrs, ok := err.(validator.ValidationErrors); ok {
+				details = validate.ValidationErrorDetails(&uri, "uri", vErrs)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidUriValue, "invalid alert event request in uri", details)
+		}
+		if err := c.ShouldBindQuery(&query); err != nil {
+			logger.Errorw("alert.handler.alertEvents failed to bind", "err", err)
+			var details []*validate.ValidationErrDetail
+			if vErrs, ok := err.(validator.ValidationErrors); ok {
+				details = validate.ValidationErrorDetails(&query, "form", vErrs)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidQueryValue, "invalid alert event request in query", details)
+		}
+
+		limit, err := strconv.ParseUint(query.Limit, 10, 64)
+		if err != nil || limit > 100 {
+			limit = 20
+		}
+		offset, err := strconv.ParseUint(query.Offset, 10, 64)
+		if err != nil {
+			offset = 0
+		}
+
+		// find
+		alert, res := h.findOwnedAlert(c, uri.Slug)
+		if res != nil {
+			return res
+		}
+		criteria := alertDB.IterateAlertEventCriteria{
+			AlertID: alert.ID,
+			Offset:  uint(offset),
+			Limit:   uint(limit),
+		}
+		events, total, err := h.alertDB.FindAlertEvents(c.Request.Context(), criteria)
+		if err != nil {
+			return handler.NewInternalErrorResponse(err)
+		}
+		return handler.NewSuccessResponse(http.StatusOK, NewAlertEventsResponse(events, total))
+	})
+}
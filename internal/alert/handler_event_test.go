@@ -0,0 +1,109 @@
+package alert
+
+import (
+	"fmt"
+	"kek-backend/internal/alert/database"
+	"kek-backend/internal/alert/model"
+	commonDB "kek-backend/internal/database"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/tidwall/gjson"
+)
+
+var (
+	dAlertEvent = model.AlertEvent{
+		ID:             1,
+		AlertID:        dAlert.ID,
+		ObservedPrice:  1.1,
+		Threshold:      1.05,
+		DeliveryStatus: model.DeliveryStatus{model.ChannelPush: model.DeliverySent},
+		FiredAt:        time.Now(),
+		CreatedAt:      time.Now(),
+	}
+)
+
+func (s *HandlerSuite) TestAlertEvents() {
+	// given
+	criteria := database.IterateAlertEventCriteria{
+		AlertID: dAlert.ID,
+		Offset:  10,
+		Limit:   10,
+	}
+	alert := dAlert
+	alert.AccountId = dUser.ID
+	s.db.On("FindAlertBySlug", mock.Anything, dAlert.Slug).Return(&alert, nil)
+	s.db.On("FindAlertEvents", mock.Anything, criteria).Return([]*model.AlertEvent{&dAlertEvent}, int64(11), nil)
+
+	// when
+	url := fmt.Sprintf("/v1/api/alerts/%s/events?offset=%d&limit=%d", dAlert.Slug, criteria.Offset, criteria.Limit)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", url, nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	// 1) method called
+	s.db.AssertCalled(s.T(), "FindAlertEvents", mock.Anything, criteria)
+	// 2) status code
+	s.Equal(http.StatusOK, res.Code)
+	// 3) response
+	result := gjson.Parse(res.Body.String())
+	s.Equal(int64(11), result.Get("eventsCount").Int())
+	events := result.Get("events").Array()
+	s.Equal(1, len(events))
+	s.Equal(dAlertEvent.ObservedPrice, events[0].Get("observedPrice").Float())
+	s.Equal(dAlertEvent.Threshold, events[0].Get("threshold").Float())
+	s.Equal(model.DeliverySent, events[0].Get("deliveryStatus.push").String())
+	s.True(events[0].Get("firedAt").Exists())
+}
+
+func (s *HandlerSuite) TestAlertEvents_FailIfNotFoundAlert() {
+	// given
+	s.db.On("FindAlertBySlug", mock.Anything, "not-exist-alert").Return(nil, commonDB.ErrNotFound)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/alerts/not-exist-alert/events", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "FindAlertEvents", mock.Anything, mock.Anything)
+	s.Equal(http.StatusNotFound, res.Code)
+}
+
+func (s *HandlerSuite) TestAlertEvents_FailIfNotOwner() {
+	// given
+	alert := dAlert
+	alert.AccountId = dAdmin.ID
+	s.db.On("FindAlertBySlug", mock.Anything, dAlert.Slug).Return(&alert, nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/alerts/"+dAlert.Slug+"/events", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "FindAlertEvents", mock.Anything, mock.Anything)
+	s.Equal(http.StatusForbidden, res.Code)
+}
+
+func (s *HandlerSuite) TestAlertEvents_FailIfUnauthorized() {
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/alerts/"+dAlert.Slug+"/events", nil)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "FindAlertBySlug", mock.Anything, mock.Anything)
+	s.db.AssertNotCalled(s.T(), "FindAlertEvents", mock.Anything, mock.Anything)
+	s.Equal(http.StatusUnauthorized, res.Code)
+}
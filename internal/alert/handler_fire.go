@@ -0,0 +1,134 @@
+package alert
+
+import (
+	"context"
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/middleware/handler"
+	"kek-backend/pkg/logging"
+	"kek-backend/pkg/validate"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/pkg/errors"
+)
+
+// testTitlePrefix is prepended to titles of test fires so that they are told apart from real ones
+const testTitlePrefix = "[Test] "
+
+// deliveryFailedReason is a reason of a failed test fire returned to the user
+const deliveryFailedReason = "failed to deliver"
+
+// errTooManyTestFires is returned in a transaction of a test fire over the limit
+var errTooManyTestFires = errors.New("too many test fires")
+
+// testAlert handles POST /v1/api/alerts/:slug/test
+// The alert is fired with the current price of its pair through every action immediately
+// and recorded as a test event without changing its status.
+// Test fires are limited to testFireLimit per account in testFireWindow.
+func (h *Handler) testAlert(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		logger := logging.FromContext(c)
+		// bind
+		type RequestUri struct {
+			Slug string `uri:"slug" binding:"required"`
+		}
+		var uri RequestUri
+		if err := c.ShouldBindUri(&uri); err != nil {
+			logger.Errorw("alert.handler.testAlert failed to bind", "err", err)
+			var details []*validate.ValidationErrDetail
+			if vErrs, ok := err.(validator.ValidationErrors); ok {
+				details = validate.ValidationErrorDetails(&uri, "uri", vErrs)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidUriValue, "invalid alert request in uri", details)
+		}
+
+		// find
+		alert, res := h.findOwnedAlert(c, uri.Slug)
+		if res != nil {
+			return res
+		}
+
+		// fire
+		ctx := c.Request.Context()
+		now := time.Now()
+		t, price, err := h.currentToken(ctx, alert.PairAddress)
+		if err != nil {
+			logger.Errorw("alert.handler.testAlert failed to get current price", "pairAddress", alert.PairAddress, "err", err)
+			return handler.NewInternalErrorResponse(err)
+		}
+		var threshold float64
+		if cond, err := ParseAlertCondition(alert); err == nil {
+			threshold = cond.Threshold
+		}
+		pending := model.DeliveryStatus{}
+		for _, t := range alert.AlertActions.Types() {
+			pending[t] = model.DeliveryPending
+		}
+		event := model.AlertEvent{
+			AlertID:        alert.ID,
+			ObservedPrice:  price,
+			Threshold:      threshold,
+			DeliveryStatus: pending,
+			IsTest:         true,
+			FiredAt:        now,
+		}
+
+		// limit. the account is locked so that concurrent test fires are counted one by one
+		err = h.alertDB.RunInTx(ctx, func(ctx context.Context) error {
+			if err := h.alertDB.LockAccount(ctx, alert.AccountId); err != nil {
+				return err
+			}
+			count, err := h.alertDB.CountTestAlertEvents(ctx, alert.AccountId, now.Add(-h.testFireWindow))
+			if err != nil {
+				return err
+			}
+			if count >= h.testFireLimit {
+				logger.Warnw("alert.handler.testAlert too many test fires", "account", alert.AccountId, "count", count)
+				return errTooManyTestFires
+			}
+			return h.alertDB.SaveAlertEvent(ctx, &event)
+		})
+		if err != nil {
+			if errors.Cause(err) == errTooManyTestFires {
+				return handler.NewErrorResponse(http.StatusTooManyRequests, handler.TooManyRequests, "too many test fires. try again later", nil)
+			}
+			return handler.NewInternalErrorResponse(err)
+		}
+
+		o := Observation{Price: price}
+		if alert.BaselinePrice != nil {
+			o.Baseline, o.HasBaseline = *alert.BaselinePrice, true
+		}
+		if alert.LastObservedPrice != nil {
+			o.Previous, o.HasPrevious = *alert.LastObservedPrice, true
+		}
+		title, body := renderAlert(ctx, alert, &TemplateData{
+			Symbol:      t.Symbol,
+			Name:        t.Name,
+			PairAddress: alert.PairAddress,
+			PriceUSD:    price,
+			Threshold:   threshold,
+			ChangePct:   changePct(o),
+			FiredAt:     now,
+		})
+
+		// test fires are sent once without the outbox so that failures are reported to the user
+		deliveryErrors := make(map[string]string)
+		for _, d := range newDeliveries(alert, testTitlePrefix+title, body, &event, now) {
+			status := model.DeliverySent
+			if err := h.dispatcher.Send(ctx, &d.Payload); err != nil {
+				logger.Warnw("alert.handler.testAlert failed to send", "alert", alert.Slug, "channel", d.Channel, "err", err)
+				status = model.DeliveryFailed
+				// the error may hold a response of the destination so that only a generic reason is returned
+				deliveryErrors[d.Channel] = deliveryFailedReason
+			}
+			event.DeliveryStatus[d.Channel] = status
+			if err := h.alertDB.UpdateAlertEventDelivery(ctx, event.ID, d.Channel, status); err != nil {
+				logger.Errorw("alert.handler.testAlert failed to update delivery status", "event", event.ID, "err", err)
+			}
+		}
+		return handler.NewSuccessResponse(http.StatusOK, NewTestAlertResponse(&event, deliveryErrors))
+	})
+}
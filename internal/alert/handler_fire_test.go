@@ -0,0 +1,160 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"kek-backend/internal/alert/model"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/tidwall/gjson"
+)
+
+func (s *HandlerSuite) TestTestAlert() {
+	// given
+	alert := newOwnedAlert(model.AlertStatusActive)
+	alert.Account = dUser
+	alert.Account.Token = "device-token"
+	s.db.On("FindAlertBySlug", mock.Anything, dAlert.Slug).Return(alert, nil)
+	s.mockTestFireTx()
+	s.db.On("CountTestAlertEvents", mock.Anything, dUser.ID, mock.Anything).Return(int64(0), nil)
+	s.db.On("SaveAlertEvent", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*model.AlertEvent).ID = 10
+	}).Return(nil)
+	s.db.On("UpdateAlertEventDelivery", mock.Anything, uint(10), model.ChannelPush, model.DeliverySent).Return(nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts/"+dAlert.Slug+"/test", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusOK, res.Code)
+	s.db.AssertCalled(s.T(), "SaveAlertEvent", mock.Anything, mock.MatchedBy(func(e *model.AlertEvent) bool {
+		return e.AlertID == alert.ID && e.IsTest && e.ObservedPrice == 1.0 && e.Threshold == 1.05
+	}))
+	s.db.AssertCalled(s.T(), "UpdateAlertEventDelivery", mock.Anything, uint(10), model.ChannelPush, model.DeliverySent)
+	messages := s.notifier.Messages()
+	s.Len(messages, 1)
+	s.Equal("device-token", messages[0].Token)
+	s.Equal("[Test] "+dAlert.Title, messages[0].Title)
+	result := gjson.Parse(res.Body.String())
+	s.True(result.Get("event.isTest").Bool())
+	s.Equal(model.DeliverySent, result.Get("event.deliveryStatus.push").String())
+	s.False(result.Get("deliveryErrors").Exists())
+}
+
+func (s *HandlerSuite) TestTestAlert_ReportFailedDelivery() {
+	// given
+	s.notifier.Err = errors.New("invalid device token")
+	s.db.On("FindAlertBySlug", mock.Anything, dAlert.Slug).Return(newOwnedAlert(model.AlertStatusPaused), nil)
+	s.mockTestFireTx()
+	s.db.On("CountTestAlertEvents", mock.Anything, dUser.ID, mock.Anything).Return(int64(0), nil)
+	s.db.On("SaveAlertEvent", mock.Anything, mock.Anything).Return(nil)
+	s.db.On("UpdateAlertEventDelivery", mock.Anything, mock.Anything, model.ChannelPush, model.DeliveryFailed).Return(nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts/"+dAlert.Slug+"/test", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusOK, res.Code)
+	result := gjson.Parse(res.Body.String())
+	s.Equal(model.DeliveryFailed, result.Get("event.deliveryStatus.push").String())
+	s.Equal(deliveryFailedReason, result.Get("deliveryErrors.push").String())
+}
+
+func (s *HandlerSuite) TestTestAlert_FailIfTooManyTestFires() {
+	// given
+	s.db.On("FindAlertBySlug", mock.Anything, dAlert.Slug).Return(newOwnedAlert(model.AlertStatusActive), nil)
+	s.mockTestFireTx()
+	s.db.On("CountTestAlertEvents", mock.Anything, dUser.ID, mock.Anything).Return(int64(5), nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts/"+dAlert.Slug+"/test", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusTooManyRequests, res.Code)
+	s.Equal("TooManyRequests", gjson.Get(res.Body.String(), "code").String())
+	s.db.AssertNotCalled(s.T(), "SaveAlertEvent", mock.Anything, mock.Anything)
+	s.Empty(s.notifier.Messages())
+}
+
+func (s *HandlerSuite) TestTestAlert_LimitConcurrentTestFires() {
+	// given : transactions holding the account lock run one by one and saved events are counted
+	var (
+		lock  sync.Mutex
+		mu    sync.Mutex
+		saved int64
+	)
+	s.db.On("FindAlertBySlug", mock.Anything, dAlert.Slug).Return(newOwnedAlert(model.AlertStatusActive), nil)
+	s.db.On("RunInTx", mock.Anything, mock.Anything).Return(func(ctx context.Context, f func(ctx context.Context) error) error {
+		lock.Lock()
+		defer lock.Unlock()
+		return f(ctx)
+	})
+	s.db.On("LockAccount", mock.Anything, dUser.ID).Return(nil)
+	s.db.On("CountTestAlertEvents", mock.Anything, dUser.ID, mock.Anything).Return(func(context.Context, uint, time.Time) int64 {
+		mu.Lock()
+		defer mu.Unlock()
+		return saved
+	}, nil)
+	s.db.On("SaveAlertEvent", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		saved++
+	}).Return(nil)
+	s.db.On("UpdateAlertEventDelivery", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	token := s.getBearerToken()
+
+	// when
+	codes := make([]int, 10)
+	var wg sync.WaitGroup
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/v1/api/alerts/"+dAlert.Slug+"/test", nil)
+			req.Header.Add("Authorization", "Bearer "+token)
+			s.r.ServeHTTP(res, req)
+			codes[i] = res.Code
+		}(i)
+	}
+	wg.Wait()
+
+	// then
+	var ok, limited int
+	for _, code := range codes {
+		switch code {
+		case http.StatusOK:
+			ok++
+		case http.StatusTooManyRequests:
+			limited++
+		}
+	}
+	s.Equal(5, ok)
+	s.Equal(5, limited)
+	s.Equal(int64(5), saved)
+	s.db.AssertNumberOfCalls(s.T(), "LockAccount", 10)
+}
+
+// mockTestFireTx runs transactions of test fires as is with the account lock
+func (s *HandlerSuite) mockTestFireTx() {
+	s.db.On("RunInTx", mock.Anything, mock.Anything).Return(func(ctx context.Context, f func(ctx context.Context) error) error {
+		return f(ctx)
+	})
+	s.db.On("LockAccount", mock.Anything, dUser.ID).Return(nil)
+}
@@ -0,0 +1,136 @@
+package alert
+
+import (
+	"context"
+	"kek-backend/internal/account"
+	alertDB "kek-backend/internal/alert/database"
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/database"
+	"kek-backend/internal/middleware/handler"
+	"kek-backend/pkg/logging"
+	"kek-backend/pkg/validate"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// changeAlertStatus changes the status of an alert owned by the current user with given change
+// and responds the changed alert. name is a name of the handler used in logs.
+func (h *Handler) changeAlertStatus(c *gin.Context, name string, change func(ctx context.Context, a *model.Alert) error) *handler.Response {
+	logger := logging.FromContext(c)
+	// bind
+	type RequestUri struct {
+		Slug string `uri:"slug" binding:"required"`
+	}
+	var uri RequestUri
+	if err := c.ShouldBindUri(&uri); err != nil {
+		logger.Errorw("alert.handler."+name+" failed to bind", "err", err)
+		var details []*validate.ValidationErrDetail
+		if vErrs, ok := err.(validator.ValidationErrors); ok {
+			details = validate.ValidationErrorDetails(&uri, "uri", vErrs)
+		}
+		return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidUriValue, "invalid alert request in uri", details)
+	}
+
+	// find
+	alert, res := h.findOwnedAlert(c, uri.Slug)
+	if res != nil {
+		return res
+	}
+
+	// change
+	if err := change(c.Request.Context(), alert); err != nil {
+		logger.Errorw("alert.handler."+name+" failed to change alert status", "alert", alert.Slug, "status", alert.AlertStatus, "err", err)
+		// not found means the status was changed by the cron in the meantime
+		if err == alertDB.ErrInvalidStatusTransition || database.IsRecordNotFoundErr(err) {
+			return handler.NewErrorResponse(http.StatusConflict, handler.InvalidState, "not allowed in "+alert.AlertStatus+" status", nil)
+		}
+		return handler.NewInternalErrorResponse(err)
+	}
+
+	changed, err := h.alertDB.FindAlertBySlug(c.Request.Context(), alert.Slug)
+	if err != nil {
+		return handler.NewInternalErrorResponse(err)
+	}
+	return handler.NewSuccessResponse(http.StatusOK, NewAlertResponse(changed))
+}
+
+// pauseAlert handles POST /v1/api/alerts/:slug/pause
+func (h *Handler) pauseAlert(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		return h.changeAlertStatus(c, "pauseAlert", func(ctx context.Context, a *model.Alert) error {
+			return h.alertDB.TransitAlertStatus(ctx, a.ID, a.AlertStatus, model.AlertStatusPaused)
+		})
+	})
+}
+
+// resumeAlert handles POST /v1/api/alerts/:slug/resume
+// Paused and snoozed alerts are resumed to active status.
+func (h *Handler) resumeAlert(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		return h.changeAlertStatus(c, "resumeAlert", func(ctx context.Context, a *model.Alert) error {
+			if a.AlertStatus != model.AlertStatusPaused && a.AlertStatus != model.AlertStatusSnoozed {
+				return alertDB.ErrInvalidStatusTransition
+			}
+			return h.alertDB.TransitAlertStatus(ctx, a.ID, a.AlertStatus, model.AlertStatusActive)
+		})
+	})
+}
+
+// snoozeAlert handles POST /v1/api/alerts/:slug/snooze?until=
+// The alert is resumed by the cron at the until time in RFC3339.
+func (h *Handler) snoozeAlert(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		logger := logging.FromContext(c)
+		// bind
+		type QueryParameter struct {
+			Until string `form:"until" binding:"required"`
+		}
+		var query QueryParameter
+		if err := c.ShouldBindQuery(&query); err != nil {
+			logger.Errorw("alert.handler.snoozeAlert failed to bind", "err", err)
+			var details []*validate.ValidationErrDetail
+			if vErrs, ok := err.(validator.ValidationErrors); ok {
+				details = validate.ValidationErrorDetails(&query, "form", vErrs)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidQueryValue, "invalid alert request in query", details)
+		}
+		until, err := time.Parse(time.RFC3339, query.Until)
+		if err != nil || !until.After(time.Now()) {
+			details := validate.NewValidationErrorDetails("until", "until must be a future time in RFC3339", query.Until)
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidQueryValue, "invalid alert request in query", details)
+		}
+
+		return h.changeAlertStatus(c, "snoozeAlert", func(ctx context.Context, a *model.Alert) error {
+			return h.alertDB.SnoozeAlert(ctx, a.ID, a.AlertStatus, until)
+		})
+	})
+}
+
+// pauseAccountAlerts handles POST /v1/api/user/alerts/pause
+// Active, triggered and snoozed alerts of the current user are paused.
+func (h *Handler) pauseAccountAlerts(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		currentUser := account.MustCurrentUser(c)
+		count, err := h.alertDB.PauseAccountAlerts(c.Request.Context(), currentUser.ID)
+		if err != nil {
+			return handler.NewInternalErrorResponse(err)
+		}
+		return handler.NewSuccessResponse(http.StatusOK, &AlertsCountResponse{AlertsCount: count})
+	})
+}
+
+// resumeAccountAlerts handles POST /v1/api/user/alerts/resume
+// Paused alerts of the current user are resumed to active status.
+func (h *Handler) resumeAccountAlerts(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		currentUser := account.MustCurrentUser(c)
+		count, err := h.alertDB.ResumeAccountAlerts(c.Request.Context(), currentUser.ID)
+		if err != nil {
+			return handler.NewInternalErrorResponse(err)
+		}
+		return handler.NewSuccessResponse(http.StatusOK, &AlertsCountResponse{AlertsCount: count})
+	})
+}
@@ -0,0 +1,189 @@
+package alert
+
+import (
+	"kek-backend/internal/alert/database"
+	"kek-backend/internal/alert/model"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/tidwall/gjson"
+)
+
+func (s *HandlerSuite) TestPauseAlert() {
+	// given
+	alert := newOwnedAlert(model.AlertStatusTriggered)
+	paused := *alert
+	paused.AlertStatus = model.AlertStatusPaused
+	s.db.On("FindAlertBySlug", mock.Anything, dAlert.Slug).Return(alert, nil).Once()
+	s.db.On("FindAlertBySlug", mock.Anything, dAlert.Slug).Return(&paused, nil)
+	s.db.On("TransitAlertStatus", mock.Anything, alert.ID, model.AlertStatusTriggered, model.AlertStatusPaused).Return(nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts/"+dAlert.Slug+"/pause", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusOK, res.Code)
+	s.db.AssertCalled(s.T(), "TransitAlertStatus", mock.Anything, alert.ID, model.AlertStatusTriggered, model.AlertStatusPaused)
+	s.Equal(model.AlertStatusPaused, gjson.Get(res.Body.String(), "alert.alertStatus").String())
+}
+
+func (s *HandlerSuite) TestPauseAlert_FailIfNotAllowed() {
+	// given
+	alert := newOwnedAlert(model.AlertStatusCompleted)
+	s.db.On("FindAlertBySlug", mock.Anything, dAlert.Slug).Return(alert, nil)
+	s.db.On("TransitAlertStatus", mock.Anything, alert.ID, model.AlertStatusCompleted, model.AlertStatusPaused).
+		Return(database.ErrInvalidStatusTransition)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts/"+dAlert.Slug+"/pause", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusConflict, res.Code)
+	s.Equal("InvalidState", gjson.Get(res.Body.String(), "code").String())
+}
+
+func (s *HandlerSuite) TestResumeAlert() {
+	// given
+	alert := newOwnedAlert(model.AlertStatusSnoozed)
+	s.db.On("FindAlertBySlug", mock.Anything, dAlert.Slug).Return(alert, nil)
+	s.db.On("TransitAlertStatus", mock.Anything, alert.ID, model.AlertStatusSnoozed, model.AlertStatusActive).Return(nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts/"+dAlert.Slug+"/resume", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusOK, res.Code)
+	s.db.AssertCalled(s.T(), "TransitAlertStatus", mock.Anything, alert.ID, model.AlertStatusSnoozed, model.AlertStatusActive)
+}
+
+func (s *HandlerSuite) TestResumeAlert_FailIfActive() {
+	// given
+	s.db.On("FindAlertBySlug", mock.Anything, dAlert.Slug).Return(newOwnedAlert(model.AlertStatusActive), nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts/"+dAlert.Slug+"/resume", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusConflict, res.Code)
+	s.db.AssertNotCalled(s.T(), "TransitAlertStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *HandlerSuite) TestSnoozeAlert() {
+	// given
+	alert := newOwnedAlert(model.AlertStatusActive)
+	until := time.Now().Add(time.Hour).Truncate(time.Second)
+	s.db.On("FindAlertBySlug", mock.Anything, dAlert.Slug).Return(alert, nil)
+	s.db.On("SnoozeAlert", mock.Anything, alert.ID, model.AlertStatusActive, mock.Anything).Return(nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts/"+dAlert.Slug+"/snooze?until="+url.QueryEscape(until.Format(time.RFC3339)), nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusOK, res.Code)
+	s.db.AssertCalled(s.T(), "SnoozeAlert", mock.Anything, alert.ID, model.AlertStatusActive, mock.MatchedBy(func(t time.Time) bool {
+		return t.Equal(until)
+	}))
+}
+
+func (s *HandlerSuite) TestSnoozeAlert_FailIfInvalidUntil() {
+	cases := []string{
+		"",
+		"tomorrow",
+		time.Now().Add(-time.Hour).Format(time.RFC3339),
+	}
+
+	for _, until := range cases {
+		// when
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/v1/api/alerts/"+dAlert.Slug+"/snooze?until="+url.QueryEscape(until), nil)
+		req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+		s.r.ServeHTTP(res, req)
+
+		// then
+		s.Equal(http.StatusBadRequest, res.Code)
+		s.Equal("InvalidQueryValue", gjson.Get(res.Body.String(), "code").String())
+		s.db.AssertNotCalled(s.T(), "SnoozeAlert", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	}
+}
+
+func (s *HandlerSuite) TestSnoozeAlert_FailIfNotOwner() {
+	// given
+	alert := newOwnedAlert(model.AlertStatusActive)
+	alert.AccountId = dAdmin.ID
+	s.db.On("FindAlertBySlug", mock.Anything, dAlert.Slug).Return(alert, nil)
+
+	// when
+	until := time.Now().Add(time.Hour).Format(time.RFC3339)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts/"+dAlert.Slug+"/snooze?until="+url.QueryEscape(until), nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusForbidden, res.Code)
+	s.db.AssertNotCalled(s.T(), "SnoozeAlert", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *HandlerSuite) TestPauseAccountAlerts() {
+	// given
+	s.db.On("PauseAccountAlerts", mock.Anything, dUser.ID).Return(int64(3), nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/user/alerts/pause", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusOK, res.Code)
+	s.Equal(int64(3), gjson.Get(res.Body.String(), "alertsCount").Int())
+}
+
+func (s *HandlerSuite) TestResumeAccountAlerts() {
+	// given
+	s.db.On("ResumeAccountAlerts", mock.Anything, dUser.ID).Return(int64(2), nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/user/alerts/resume", nil)
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusOK, res.Code)
+	s.Equal(int64(2), gjson.Get(res.Body.String(), "alertsCount").Int())
+}
+
+func newOwnedAlert(status string) *model.Alert {
+	alert := dAlert
+	alert.AccountId = dUser.ID
+	alert.AlertStatus = status
+	return &alert
+}
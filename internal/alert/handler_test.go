@@ -2,6 +2,7 @@ package alert
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"kek-backend/internal/account"
@@ -11,6 +12,13 @@ import (
 	alertDBMock "kek-backend/internal/alert/database/mocks"
 	"kek-backend/internal/alert/model"
 	"kek-backend/internal/config"
+	coreDB "kek-backend/internal/database"
+	"kek-backend/internal/metric"
+	"kek-backend/internal/notification"
+	"kek-backend/internal/token"
+	tokenDBMock "kek-backend/internal/token/database/mocks"
+	tokenModel "kek-backend/internal/token/model"
+	"kek-backend/internal/uniswap"
 	"kek-backend/pkg/logging"
 	"net/http"
 	"net/http/httptest"
@@ -34,14 +42,28 @@ var (
 		Bio:      "I am working!",
 	}
 	dUserRawPass = "user1"
+	// metrics are registered globally so that the provider is created once
+	testMetricsProvider = metric.NewMetricsProvider(&config.Config{})
+	dAdmin              = accountModel.Account{
+		ID:       2,
+		Username: "admin",
+		Email:    "admin@gmail.com",
+		Password: dUser.Password,
+	}
 
 	dAlert = model.Alert{
-		ID:        1,
-		Slug:      "how-to-train-your-dragon",
-		Title:     "How to train your dragon",
-		Body:      "You have to believe",
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:             1,
+		Slug:           "how-to-train-your-dragon",
+		Title:          "How to train your dragon",
+		Body:           "You have to believe",
+		PairAddress:    "0x6b175474e89094c44da98b954eedeac495271d0f",
+		AlertType:      "price",
+		AlertValue:     "1.05",
+		AlertOption:    "above",
+		ExpirationTime: time.Now().Add(24 * time.Hour),
+		AlertActions:   model.AlertActions{{Type: model.ChannelPush}},
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
 	}
 )
 
@@ -51,6 +73,9 @@ type HandlerSuite struct {
 	handler   *Handler
 	db        *alertDBMock.AlertDB
 	accountDB *accountDBMock.AccountDB
+	tokenDB   *tokenDBMock.TokenDB
+	notifier  *notification.FakeNotifier
+	subgraph  *httptest.Server
 }
 
 func (s *HandlerSuite) SetupSuite() {
@@ -60,13 +85,42 @@ func (s *HandlerSuite) SetupSuite() {
 func (s *HandlerSuite) SetupTest() {
 	cfg, err := config.Load("")
 	s.NoError(err)
+	// stand-in subgraph serving the token of dAlert only
+	s.subgraph = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req uniswap.GraphQLRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch {
+		case req.Variables["id"] == dAlert.PairAddress:
+			_, _ = w.Write([]byte(`{"data":{"tokens":[{"id":"` + dAlert.PairAddress + `","symbol":"DAI","derivedETH":"0.0005"}]}}`))
+		case req.Variables["id"] != nil:
+			_, _ = w.Write([]byte(`{"data":{"tokens":[]}}`))
+		default:
+			_, _ = w.Write([]byte(`{"data":{"bundles":[{"ethPrice":"2000"}]}}`))
+		}
+	}))
+	cfg.UniswapConfig.Endpoint = s.subgraph.URL
+	cfg.UniswapConfig.Retries = 0
 
 	s.db = &alertDBMock.AlertDB{}
-	s.handler = NewHandler(s.db)
+	s.notifier = notification.NewFakeNotifier()
+	dispatcher := NewDispatcher(s.notifier, nil, nil, nil)
+	prices := uniswap.NewPriceCache(cfg, uniswap.NewClient(cfg), testMetricsProvider)
+	s.tokenDB = &tokenDBMock.TokenDB{}
+	s.tokenDB.On("FindTokenByID", mock.Anything, dAlert.PairAddress).Return(&tokenModel.Token{
+		ID:       dAlert.PairAddress,
+		Name:     "Dai Stablecoin",
+		Symbol:   "DAI",
+		Decimals: 18,
+	}, nil)
+	resolver := token.NewResolver(s.tokenDB, prices)
+	s.handler = NewHandler(cfg, s.db, resolver, prices, dispatcher)
 	s.accountDB = &accountDBMock.AccountDB{}
 	s.accountDB.On("FindByEmail", mock.Anything, mock.MatchedBy(func(email string) bool {
 		return email == dUser.Email
 	})).Return(&dUser, nil)
+	s.accountDB.On("FindByEmail", mock.Anything, mock.MatchedBy(func(email string) bool {
+		return email == dAdmin.Email
+	})).Return(&dAdmin, nil)
 
 	jwtMiddleware, err := account.NewAuthMiddleware(cfg, s.accountDB)
 	s.NoError(err)
@@ -74,12 +128,18 @@ func (s *HandlerSuite) SetupTest() {
 	gin.SetMode(gin.TestMode)
 	s.r = gin.Default()
 
+	cfg.AdminConfig.Emails = []string{dAdmin.Email}
+
 	RouteV1(cfg, s.handler, s.r, jwtMiddleware)
 
 	accountHandler := account.NewHandler(s.accountDB)
 	account.RouteV1(cfg, accountHandler, s.r, jwtMiddleware)
 }
 
+func (s *HandlerSuite) TearDownTest() {
+	s.subgraph.Close()
+}
+
 func TestSuite(t *testing.T) {
 	suite.Run(t, new(HandlerSuite))
 }
@@ -91,12 +151,7 @@ func (s *HandlerSuite) TestSaveAlert() {
 	s.db.On("SaveAlert", mock.Anything, mock.Anything).Return(nil)
 
 	// when
-	requestBody := map[string]interface{}{
-		"alert": map[string]interface{}{
-			"title": dAlert.Title,
-			"body":  dAlert.Body,
-		},
-	}
+	requestBody := newAlertRequestBody(&dAlert)
 	b, _ := json.Marshal(&requestBody)
 	res := httptest.NewRecorder()
 	req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
@@ -115,6 +170,315 @@ func (s *HandlerSuite) TestSaveAlert() {
 	s.assertAlertResponse(&dAlert, gjson.Parse(jsonVal).Get("alert"))
 }
 
+func (s *HandlerSuite) TestSaveAlert_FailIfInvalidActions() {
+	// when
+	requestBody := newAlertRequestBody(&dAlert)
+	requestBody["alert"].(map[string]interface{})["alertActions"] = []map[string]string{
+		{"type": "webhook", "url": "http://example.com/hook"},
+	}
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "SaveAlert", mock.Anything, mock.Anything)
+	s.Equal(http.StatusBadRequest, res.Code)
+	result := gjson.Parse(res.Body.String())
+	s.Equal("InvalidBodyValue", result.Get("code").String())
+	s.Equal("alertActions[0].url", result.Get("errors.0.field").String())
+}
+
+func (s *HandlerSuite) TestSaveAlert_FailIfInvalidTemplate() {
+	// when
+	requestBody := newAlertRequestBody(&dAlert)
+	requestBody["alert"].(map[string]interface{})["body"] = "{{symbol}} is now {{price}}"
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "SaveAlert", mock.Anything, mock.Anything)
+	s.Equal(http.StatusBadRequest, res.Code)
+	result := gjson.Parse(res.Body.String())
+	s.Equal("InvalidBodyValue", result.Get("code").String())
+	s.Equal("body", result.Get("errors.0.field").String())
+}
+
+func (s *HandlerSuite) TestSaveAlert_PercentChangeSinceCreation() {
+	// given
+	s.db.On("SaveAlert", mock.Anything, mock.Anything).Return(nil)
+
+	// when
+	requestBody := newAlertRequestBody(&dAlert)
+	requestBody["alert"].(map[string]interface{})["alertType"] = "percent_change_since_creation"
+	requestBody["alert"].(map[string]interface{})["alertOption"] = "moves"
+	requestBody["alert"].(map[string]interface{})["alertValue"] = "10"
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusCreated, res.Code)
+	s.db.AssertCalled(s.T(), "SaveAlert", mock.Anything, mock.MatchedBy(func(a *model.Alert) bool {
+		return a.BaselinePrice != nil && *a.BaselinePrice == 1.0
+	}))
+	s.Equal(1.0, gjson.Get(res.Body.String(), "alert.baselinePrice").Float())
+}
+
+func (s *HandlerSuite) TestSaveAlert_PercentChangeWindow() {
+	// given
+	s.db.On("SaveAlert", mock.Anything, mock.Anything).Return(nil)
+
+	// when
+	requestBody := newAlertRequestBody(&dAlert)
+	requestBody["alert"].(map[string]interface{})["alertType"] = "percent_change_window"
+	requestBody["alert"].(map[string]interface{})["alertOption"] = "above"
+	requestBody["alert"].(map[string]interface{})["alertValue"] = "5"
+	requestBody["alert"].(map[string]interface{})["alertWindow"] = "4h"
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusCreated, res.Code)
+	s.db.AssertCalled(s.T(), "SaveAlert", mock.Anything, mock.MatchedBy(func(a *model.Alert) bool {
+		return a.AlertWindow == "4h" && a.BaselinePrice == nil
+	}))
+	s.Equal("4h", gjson.Get(res.Body.String(), "alert.alertWindow").String())
+}
+
+func (s *HandlerSuite) TestSaveAlert_BandRearm() {
+	// given
+	s.db.On("SaveAlert", mock.Anything, mock.Anything).Return(nil)
+
+	// when
+	requestBody := newAlertRequestBody(&dAlert)
+	requestBody["alert"].(map[string]interface{})["rearmPolicy"] = "band"
+	requestBody["alert"].(map[string]interface{})["hysteresis"] = 2.5
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusCreated, res.Code)
+	s.db.AssertCalled(s.T(), "SaveAlert", mock.Anything, mock.MatchedBy(func(a *model.Alert) bool {
+		return a.RearmPolicy == model.RearmBand && a.Hysteresis == 2.5 && a.CooldownMinutes == 0
+	}))
+	s.Equal("band", gjson.Get(res.Body.String(), "alert.rearmPolicy").String())
+	s.Equal(2.5, gjson.Get(res.Body.String(), "alert.hysteresis").Float())
+}
+
+func (s *HandlerSuite) TestSaveAlert_FailIfCooldownWithoutMinutes() {
+	// when
+	requestBody := newAlertRequestBody(&dAlert)
+	requestBody["alert"].(map[string]interface{})["rearmPolicy"] = "cooldown"
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "SaveAlert", mock.Anything, mock.Anything)
+	s.Equal(http.StatusBadRequest, res.Code)
+	result := gjson.Parse(res.Body.String())
+	s.Equal("InvalidBodyValue", result.Get("code").String())
+	s.Equal("cooldownMinutes", result.Get("errors.0.field").String())
+}
+
+func (s *HandlerSuite) TestSaveAlert_Expression() {
+	// given
+	s.db.On("SaveAlert", mock.Anything, mock.Anything).Return(nil)
+	s.tokenDB.On("FindTokenByID", mock.Anything, dQuote).Return(&tokenModel.Token{ID: dQuote, Symbol: "USDC"}, nil)
+
+	// when
+	requestBody := newAlertRequestBody(&dAlert)
+	alert := requestBody["alert"].(map[string]interface{})
+	alert["alertType"] = "expression"
+	delete(alert, "alertOption")
+	delete(alert, "alertValue")
+	alert["alertExpression"] = map[string]interface{}{
+		"op": "and",
+		"conditions": []map[string]string{
+			{"type": "price", "option": "above", "value": "2"},
+			{"type": "ratio", "option": "above", "value": "1.5", "quoteAddress": dQuote},
+		},
+	}
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusCreated, res.Code)
+	s.db.AssertCalled(s.T(), "SaveAlert", mock.Anything, mock.MatchedBy(func(a *model.Alert) bool {
+		return a.AlertExpression != nil && a.AlertExpression.Op == "and" && len(a.AlertExpression.Conditions) == 2
+	}))
+	expression := gjson.Get(res.Body.String(), "alert.alertExpression")
+	s.Equal("and", expression.Get("op").String())
+	s.Equal(dQuote, expression.Get("conditions.1.quoteAddress").String())
+}
+
+func (s *HandlerSuite) TestSaveAlert_ExpressionIgnoreTypeCase() {
+	// given
+	s.db.On("SaveAlert", mock.Anything, mock.Anything).Return(nil)
+
+	// when
+	requestBody := newAlertRequestBody(&dAlert)
+	alert := requestBody["alert"].(map[string]interface{})
+	alert["alertType"] = "Expression"
+	delete(alert, "alertOption")
+	delete(alert, "alertValue")
+	alert["alertExpression"] = map[string]interface{}{
+		"op": "or",
+		"conditions": []map[string]string{
+			{"type": "price", "option": "above", "value": "2"},
+			{"type": "price", "option": "below", "value": "1"},
+		},
+	}
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusCreated, res.Code)
+	s.db.AssertCalled(s.T(), "SaveAlert", mock.Anything, mock.MatchedBy(func(a *model.Alert) bool {
+		return a.AlertExpression != nil && a.AlertValue == "" && a.AlertOption == ""
+	}))
+}
+
+func (s *HandlerSuite) TestSaveAlert_FailIfInvalidExpression() {
+	cases := []struct {
+		Expression interface{}
+		Field      string
+	}{
+		{Expression: nil, Field: "alertExpression"},
+		{
+			Expression: map[string]interface{}{
+				"op": "or",
+				"conditions": []map[string]string{
+					{"type": "price", "option": "above", "value": "2"},
+					{"type": "liquidity", "option": "crosses_above", "value": "1000"},
+				},
+			},
+			Field: "alertExpression.conditions[1].option",
+		},
+		{
+			Expression: map[string]interface{}{
+				"op": "or",
+				"conditions": []map[string]string{
+					{"type": "price", "option": "above", "value": "2"},
+					{"type": "price", "option": "above", "value": "2", "pairAddress": "0x0000000000000000000000000000000000000001"},
+				},
+			},
+			Field: "alertExpression.conditions[1].pairAddress",
+		},
+	}
+	s.tokenDB.On("FindTokenByID", mock.Anything, "0x0000000000000000000000000000000000000001").Return(nil, coreDB.ErrNotFound)
+
+	for _, tc := range cases {
+		// when
+		requestBody := newAlertRequestBody(&dAlert)
+		alert := requestBody["alert"].(map[string]interface{})
+		alert["alertType"] = "expression"
+		delete(alert, "alertOption")
+		delete(alert, "alertValue")
+		alert["alertExpression"] = tc.Expression
+		b, _ := json.Marshal(&requestBody)
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
+		req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+		s.r.ServeHTTP(res, req)
+
+		// then
+		s.db.AssertNotCalled(s.T(), "SaveAlert", mock.Anything, mock.Anything)
+		s.Equal(http.StatusBadRequest, res.Code, tc.Field)
+		result := gjson.Parse(res.Body.String())
+		s.Equal("InvalidBodyValue", result.Get("code").String())
+		s.Equal(tc.Field, result.Get("errors.0.field").String())
+	}
+}
+
+func (s *HandlerSuite) TestSaveAlert_FailIfUnknownToken() {
+	// given
+	unknown := "0x0000000000000000000000000000000000000001"
+	s.tokenDB.On("FindTokenByID", mock.Anything, unknown).Return(nil, coreDB.ErrNotFound)
+
+	// when
+	requestBody := newAlertRequestBody(&dAlert)
+	requestBody["alert"].(map[string]interface{})["pairAddress"] = unknown
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.db.AssertNotCalled(s.T(), "SaveAlert", mock.Anything, mock.Anything)
+	s.Equal(http.StatusBadRequest, res.Code)
+	result := gjson.Parse(res.Body.String())
+	s.Equal("InvalidBodyValue", result.Get("code").String())
+	s.Equal("pairAddress", result.Get("errors.0.field").String())
+}
+
+func (s *HandlerSuite) TestSaveAlert_FailIfInvalidCondition() {
+	cases := []struct {
+		Field string
+		Value string
+	}{
+		{Field: "pairAddress", Value: "0x6b175474e89094c44da98b954eedeac495271d0z"},
+		{Field: "alertType", Value: "unknown"},
+		{Field: "alertOption", Value: "between"},
+		{Field: "alertOption", Value: ""},
+		{Field: "alertValue", Value: "one"},
+		{Field: "alertValue", Value: ""},
+		{Field: "alertValue", Value: "-1"},
+		{Field: "alertWindow", Value: "1h"},
+	}
+
+	for _, tc := range cases {
+		// when
+		requestBody := newAlertRequestBody(&dAlert)
+		requestBody["alert"].(map[string]interface{})[tc.Field] = tc.Value
+		b, _ := json.Marshal(&requestBody)
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/v1/api/alerts", bytes.NewBuffer(b))
+		req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+		s.r.ServeHTTP(res, req)
+
+		// then
+		s.db.AssertNotCalled(s.T(), "SaveAlert", mock.Anything, mock.Anything)
+		s.Equal(http.StatusBadRequest, res.Code)
+		result := gjson.Parse(res.Body.String())
+		s.Equal("InvalidBodyValue", result.Get("code").String())
+		s.Equal(tc.Field, result.Get("errors.0.field").String())
+	}
+}
+
 func (s *HandlerSuite) TestAlertBySlug() {
 	// given
 	s.db.On("FindAlertBySlug", mock.Anything, dAlert.Slug).Return(&dAlert, nil)
@@ -135,16 +499,42 @@ func (s *HandlerSuite) TestAlertBySlug() {
 	s.assertAlertResponse(&dAlert, gjson.Parse(jsonVal).Get("alert"))
 }
 
+func (s *HandlerSuite) TestAlertBySlug_HideActionDestinations() {
+	// given
+	alert := dAlert
+	alert.AlertActions = model.AlertActions{
+		{Type: model.ChannelEmail, Email: "user1@gmail.com"},
+		{Type: model.ChannelWebhook, URL: "https://example.com/hook", Secret: "secret"},
+		{Type: model.ChannelBot, ChatID: "12345"},
+	}
+	s.db.On("FindAlertBySlug", mock.Anything, alert.Slug).Return(&alert, nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/alerts/"+alert.Slug, nil)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusOK, res.Code)
+	actions := gjson.Get(res.Body.String(), "alert.alertActions").Array()
+	s.Len(actions, 3)
+	for i, action := range actions {
+		s.Equal(alert.AlertActions[i].Type, action.Get("type").String())
+		s.Len(action.Map(), 1)
+	}
+}
+
 func (s *HandlerSuite) TestAlerts() {
 	criteria := database.IterateAlertCriteria{
-		Account: dAlert.Account.Username,
+		Account: dAlert.Account.ID,
 		Offset:  0,
 		Limit:   5,
 	}
 	s.db.On("FindAlerts", mock.Anything, criteria).Return([]*model.Alert{&dAlert}, int64(1), nil)
 
 	// when
-	url := fmt.Sprintf("/v1/api/alerts?tag=%s&account=%s&offset=%d&limit=%d",
+	url := fmt.Sprintf("/v1/api/alerts?account=%d&offset=%d&limit=%d",
 		criteria.Account, criteria.Offset, criteria.Limit)
 
 	res := httptest.NewRecorder()
@@ -165,6 +555,199 @@ func (s *HandlerSuite) TestAlerts() {
 	alertsResult := result.Get("alerts").Array()
 	s.Equal(1, len(alertsResult))
 	s.assertAlertResponse(&dAlert, alertsResult[0])
+	s.Equal(model.ChannelPush, alertsResult[0].Get("alertActions.0.type").String())
+}
+
+func (s *HandlerSuite) TestUpdateAlert() {
+	// given
+	alert := dAlert
+	alert.AccountId = dUser.ID
+	alert.AlertStatus = model.AlertStatusTriggered
+	s.db.On("FindAlertBySlug", mock.Anything, dAlert.Slug).Return(&alert, nil).Once()
+	s.db.On("RunInTx", mock.Anything, mock.Anything).Return(func(ctx context.Context, f func(ctx context.Context) error) error {
+		return f(ctx)
+	})
+	s.db.On("UpdateAlert", mock.Anything, mock.Anything).Return(nil)
+	s.db.On("ClearAlertObservation", mock.Anything, alert.ID).Return(nil)
+	s.db.On("TransitAlertStatus", mock.Anything, alert.ID, model.AlertStatusTriggered, model.AlertStatusActive).Return(nil)
+	updated := alert
+	updated.Slug = "how-to-ride-your-dragon"
+	updated.Title = "How to ride your dragon"
+	updated.AlertValue = "2"
+	updated.AlertStatus = model.AlertStatusActive
+	s.db.On("FindAlertBySlug", mock.Anything, updated.Slug).Return(&updated, nil)
+
+	// when
+	requestBody := map[string]interface{}{
+		"alert": map[string]interface{}{
+			"title":      updated.Title,
+			"alertValue": updated.AlertValue,
+		},
+	}
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/v1/api/alerts/"+dAlert.Slug, bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusOK, res.Code)
+	s.db.AssertCalled(s.T(), "UpdateAlert", mock.Anything, mock.MatchedBy(func(a *model.Alert) bool {
+		return a.Slug == updated.Slug && a.Title == updated.Title && a.AlertValue == "2" &&
+			a.Body == dAlert.Body && a.AlertOption == dAlert.AlertOption
+	}))
+	s.db.AssertCalled(s.T(), "TransitAlertStatus", mock.Anything, alert.ID, model.AlertStatusTriggered, model.AlertStatusActive)
+	result := gjson.Parse(res.Body.String()).Get("alert")
+	s.assertAlertResponse(&updated, result)
+	s.Equal(model.AlertStatusActive, result.Get("alertStatus").String())
+}
+
+func (s *HandlerSuite) TestUpdateAlert_RearmCompletedAlert() {
+	// given
+	observed := 1.2
+	observedAt := time.Now()
+	alert := newOwnedAlert(model.AlertStatusCompleted)
+	alert.LastObservedPrice, alert.LastObservedAt = &observed, &observedAt
+	s.db.On("FindAlertBySlug", mock.Anything, dAlert.Slug).Return(alert, nil).Once()
+	s.db.On("RunInTx", mock.Anything, mock.Anything).Return(func(ctx context.Context, f func(ctx context.Context) error) error {
+		return f(ctx)
+	})
+	s.db.On("UpdateAlert", mock.Anything, mock.Anything).Return(nil)
+	s.db.On("ClearAlertObservation", mock.Anything, alert.ID).Return(nil)
+	s.db.On("TransitAlertStatus", mock.Anything, alert.ID, model.AlertStatusCompleted, model.AlertStatusActive).Return(nil)
+	updated := *alert
+	updated.AlertValue = "2"
+	updated.AlertStatus = model.AlertStatusActive
+	updated.LastObservedPrice, updated.LastObservedAt = nil, nil
+	s.db.On("FindAlertBySlug", mock.Anything, dAlert.Slug).Return(&updated, nil)
+
+	// when
+	b, _ := json.Marshal(map[string]interface{}{
+		"alert": map[string]interface{}{"alertValue": "2"},
+	})
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/v1/api/alerts/"+dAlert.Slug, bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusOK, res.Code)
+	s.db.AssertCalled(s.T(), "UpdateAlert", mock.Anything, mock.MatchedBy(func(a *model.Alert) bool {
+		return a.AlertValue == "2"
+	}))
+	s.db.AssertCalled(s.T(), "ClearAlertObservation", mock.Anything, alert.ID)
+	s.db.AssertCalled(s.T(), "TransitAlertStatus", mock.Anything, alert.ID, model.AlertStatusCompleted, model.AlertStatusActive)
+	s.Equal(model.AlertStatusActive, gjson.Get(res.Body.String(), "alert.alertStatus").String())
+}
+
+func (s *HandlerSuite) TestUpdateAlert_KeepStatusIfConditionNotChanged() {
+	// given
+	alert := dAlert
+	alert.AccountId = dUser.ID
+	alert.AlertStatus = model.AlertStatusTriggered
+	s.db.On("FindAlertBySlug", mock.Anything, dAlert.Slug).Return(&alert, nil)
+	s.db.On("RunInTx", mock.Anything, mock.Anything).Return(func(ctx context.Context, f func(ctx context.Context) error) error {
+		return f(ctx)
+	})
+	s.db.On("UpdateAlert", mock.Anything, mock.Anything).Return(nil)
+
+	// when
+	requestBody := map[string]interface{}{
+		"alert": map[string]interface{}{
+			"body":       "Updated body",
+			"alertValue": dAlert.AlertValue,
+		},
+	}
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/v1/api/alerts/"+dAlert.Slug, bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusOK, res.Code)
+	s.db.AssertCalled(s.T(), "UpdateAlert", mock.Anything, mock.MatchedBy(func(a *model.Alert) bool {
+		return a.Slug == dAlert.Slug && a.Body == "Updated body"
+	}))
+	s.db.AssertNotCalled(s.T(), "ClearAlertObservation", mock.Anything, mock.Anything)
+	s.db.AssertNotCalled(s.T(), "TransitAlertStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *HandlerSuite) TestUpdateAlert_FailIfNotOwner() {
+	// given
+	alert := dAlert
+	alert.AccountId = dAdmin.ID
+	s.db.On("FindAlertBySlug", mock.Anything, dAlert.Slug).Return(&alert, nil)
+
+	// when
+	requestBody := map[string]interface{}{
+		"alert": map[string]interface{}{"body": "Updated body"},
+	}
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/v1/api/alerts/"+dAlert.Slug, bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusForbidden, res.Code)
+	s.db.AssertNotCalled(s.T(), "UpdateAlert", mock.Anything, mock.Anything)
+}
+
+func (s *HandlerSuite) TestUpdateAlert_FailIfDuplicateTitle() {
+	// given
+	alert := dAlert
+	alert.AccountId = dUser.ID
+	s.db.On("FindAlertBySlug", mock.Anything, dAlert.Slug).Return(&alert, nil).Once()
+	s.db.On("RunInTx", mock.Anything, mock.Anything).Return(func(ctx context.Context, f func(ctx context.Context) error) error {
+		return f(ctx)
+	})
+	s.db.On("UpdateAlert", mock.Anything, mock.Anything).Return(coreDB.ErrKeyConflict)
+
+	// when
+	requestBody := map[string]interface{}{
+		"alert": map[string]interface{}{"title": "Other alert"},
+	}
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/v1/api/alerts/"+dAlert.Slug, bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusConflict, res.Code)
+	s.db.AssertCalled(s.T(), "UpdateAlert", mock.Anything, mock.MatchedBy(func(a *model.Alert) bool {
+		return a.Slug == "other-alert"
+	}))
+	s.db.AssertNotCalled(s.T(), "ClearAlertObservation", mock.Anything, mock.Anything)
+}
+
+func (s *HandlerSuite) TestUpdateAlert_FailIfInvalidCondition() {
+	// given
+	alert := dAlert
+	alert.AccountId = dUser.ID
+	s.db.On("FindAlertBySlug", mock.Anything, dAlert.Slug).Return(&alert, nil)
+
+	// when
+	requestBody := map[string]interface{}{
+		"alert": map[string]interface{}{"alertValue": "-1"},
+	}
+	b, _ := json.Marshal(&requestBody)
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/v1/api/alerts/"+dAlert.Slug, bytes.NewBuffer(b))
+	req.Header.Add("Authorization", "Bearer "+s.getBearerToken())
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusBadRequest, res.Code)
+	s.Equal("alertValue", gjson.Get(res.Body.String(), "errors.0.field").String())
+	s.db.AssertNotCalled(s.T(), "UpdateAlert", mock.Anything, mock.Anything)
 }
 
 func (s *HandlerSuite) TestDeleteAlert() {
@@ -200,9 +783,13 @@ func (s *HandlerSuite) assertAlertResponse(alert *model.Alert, result gjson.Resu
 }
 
 func (s *HandlerSuite) getBearerToken() string {
+	return s.getBearerTokenOf(dUser.Email)
+}
+
+func (s *HandlerSuite) getBearerTokenOf(email string) string {
 	body := map[string]interface{}{
 		"user": map[string]interface{}{
-			"email":    dUser.Email,
+			"email":    email,
 			"password": dUserRawPass,
 		},
 	}
@@ -215,12 +802,27 @@ func (s *HandlerSuite) getBearerToken() string {
 	return gjson.Get(res.Body.String(), "token").String()
 }
 
+func newAlertRequestBody(alert *model.Alert) map[string]interface{} {
+	return map[string]interface{}{
+		"alert": map[string]interface{}{
+			"title":          alert.Title,
+			"body":           alert.Body,
+			"pairAddress":    alert.PairAddress,
+			"alertType":      alert.AlertType,
+			"alertValue":     alert.AlertValue,
+			"alertOption":    alert.AlertOption,
+			"expirationTime": alert.ExpirationTime,
+			"alertActions":   alert.AlertActions,
+		},
+	}
+}
+
 func alertMatcher(title, body string, account *accountModel.Account) func(a *model.Alert) bool {
 	return func(a *model.Alert) bool {
 		if a.Slug != slug.Make(title) || a.Title != title || a.Body != body {
 			return false
 		}
-		if a.Account.Username != account.Username || a.Account.Bio != account.Bio || a.Account.Image != account.Image {
+		if a.AccountId != account.ID {
 			return false
 		}
 		return true
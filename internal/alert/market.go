@@ -0,0 +1,87 @@
+package alert
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/uniswap"
+)
+
+const (
+	// volumeSpikeDays is the number of previous days averaged by volume spike conditions
+	volumeSpikeDays = 7
+	// volumeSpikeMinElapsed is the least part of the current day the average is pro-rated to
+	// so that a few trades just after midnight do not look like a spike
+	volumeSpikeMinElapsed = time.Hour
+)
+
+// volumeAddresses returns distinct normalized addresses of tokens watched by volume spike
+// conditions of given alerts including the conditions in expressions
+func volumeAddresses(alerts []*model.Alert) []string {
+	var addresses []string
+	seen := make(map[string]bool)
+	add := func(address string) {
+		if !seen[address] {
+			seen[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+	for _, a := range alerts {
+		if IsExpressionAlert(a) {
+			x, err := ParseAlertExpression(a)
+			if err != nil {
+				continue
+			}
+			for _, leaf := range x.Leaves() {
+				if leaf.Condition.Type == ConditionVolumeSpike {
+					add(leaf.PairAddress)
+				}
+			}
+			continue
+		}
+		if ConditionType(strings.ToLower(strings.TrimSpace(a.AlertType))) != ConditionVolumeSpike {
+			continue
+		}
+		if address, err := uniswap.NormalizeAddress(a.PairAddress); err == nil {
+			add(address)
+		}
+	}
+	return addresses
+}
+
+// volumeStats returns the USD volume of the current UTC day so far and the average daily USD volume
+// of the previous days in given daily data pro-rated to the elapsed part of the current day,
+// which is at least volumeSpikeMinElapsed, so that a partial day is compared with the same part
+// of the previous days. Days without any data are not averaged.
+// ok is false if there is no data of the previous days.
+func volumeStats(dayDatas []uniswap.TokenDayData, now time.Time) (volume, average float64, ok bool) {
+	start := now.UTC().Truncate(24 * time.Hour)
+	today := start.Unix()
+	var (
+		sum  float64
+		days int
+	)
+	for _, d := range dayDatas {
+		v, err := strconv.ParseFloat(d.DailyVolumeUSD, 64)
+		if err != nil {
+			continue
+		}
+		switch {
+		case d.Date == today:
+			volume = v
+		case d.Date < today:
+			sum += v
+			days++
+		}
+	}
+	if days == 0 {
+		return 0, 0, false
+	}
+	elapsed := now.Sub(start)
+	if elapsed < volumeSpikeMinElapsed {
+		elapsed = volumeSpikeMinElapsed
+	}
+	return volume, sum / float64(days) * elapsed.Hours() / 24, true
+}
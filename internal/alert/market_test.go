@@ -0,0 +1,73 @@
+package alert
+
+import (
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/uniswap"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVolumeAddresses(t *testing.T) {
+	alerts := []*model.Alert{
+		{Slug: "a", AlertType: "volume_spike", PairAddress: "0x6b175474e89094c44da98b954eedeac495271d0f"},
+		{Slug: "b", AlertType: "price", PairAddress: "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"},
+		{Slug: "c", AlertType: " Volume_Spike ", PairAddress: "0x6B175474E89094C44Da98b954EedeAC495271d0F"},
+	}
+
+	addresses := volumeAddresses(alerts)
+
+	assert.Equal(t, []string{"0x6b175474e89094c44da98b954eedeac495271d0f"}, addresses)
+}
+
+func TestVolumeStats(t *testing.T) {
+	now := time.Date(2021, 10, 8, 15, 0, 0, 0, time.UTC)
+	day := func(offset int, volume string) uniswap.TokenDayData {
+		return uniswap.TokenDayData{Date: now.Truncate(24*time.Hour).AddDate(0, 0, offset).Unix(), DailyVolumeUSD: volume}
+	}
+
+	volume, average, ok := volumeStats([]uniswap.TokenDayData{
+		day(0, "5000"),
+		day(-1, "1000"),
+		day(-2, "invalid"),
+		day(-3, "2000"),
+	}, now)
+
+	// averaged over 2 days and pro-rated to 15 of 24 hours
+	assert.True(t, ok)
+	assert.Equal(t, 5000.0, volume)
+	assert.Equal(t, 937.5, average)
+
+	// without previous days
+	_, _, ok = volumeStats([]uniswap.TokenDayData{day(0, "5000")}, now)
+	assert.False(t, ok)
+
+	// without the current day
+	volume, average, ok = volumeStats([]uniswap.TokenDayData{day(-1, "1000")}, now)
+	assert.True(t, ok)
+	assert.Equal(t, 0.0, volume)
+	assert.Equal(t, 625.0, average)
+
+	// pro-rated to at least an hour just after midnight
+	_, average, ok = volumeStats([]uniswap.TokenDayData{day(-1, "2400")}, now.Truncate(24*time.Hour).Add(10*time.Minute))
+	assert.True(t, ok)
+	assert.Equal(t, 100.0, average)
+}
+
+func TestVolumeSpike_PartialDay(t *testing.T) {
+	now := time.Date(2021, 10, 8, 6, 0, 0, 0, time.UTC)
+	day := func(offset int, volume string) uniswap.TokenDayData {
+		return uniswap.TokenDayData{Date: now.Truncate(24*time.Hour).AddDate(0, 0, offset).Unix(), DailyVolumeUSD: volume}
+	}
+	condition := &Condition{Type: ConditionVolumeSpike, Operator: OperatorAbove, Threshold: 2}
+
+	// a quarter of the day traded twice the average of a quarter
+	var o Observation
+	o.Volume, o.AverageVolume, o.HasVolume = volumeStats([]uniswap.TokenDayData{day(0, "500"), day(-1, "1000")}, now)
+	assert.True(t, condition.Holds(o))
+
+	// a quarter of the day traded as usual
+	o.Volume, o.AverageVolume, o.HasVolume = volumeStats([]uniswap.TokenDayData{day(0, "250"), day(-1, "1000")}, now)
+	assert.False(t, condition.Holds(o))
+}
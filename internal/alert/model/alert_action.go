@@ -0,0 +1,68 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// AlertAction is a delivery configured for an alert.
+// Type is one of the Channel* constants and decides which of the other fields are used.
+//
+//	push: sends a push notification to the account's device token
+//	webhook: posts a signed json payload to URL. Secret is a HMAC-SHA256 key
+//	email: sends an email to Email
+//	bot: posts a text message to ChatID through the chat-bot endpoint URL
+type AlertAction struct {
+	Type   string `json:"type"`
+	URL    string `json:"url,omitempty"`
+	Secret string `json:"secret,omitempty"`
+	Email  string `json:"email,omitempty"`
+	ChatID string `json:"chatId,omitempty"`
+}
+
+// AlertActions is a list of alert actions stored as json text
+type AlertActions []AlertAction
+
+// Types returns the action types in order
+func (a AlertActions) Types() []string {
+	var types []string
+	for _, action := range a {
+		types = append(types, action.Type)
+	}
+	return types
+}
+
+// Value implements driver.Valuer and stores the actions as json text
+func (a AlertActions) Value() (driver.Value, error) {
+	if a == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner and reads the actions from json text
+func (a *AlertActions) Scan(src interface{}) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*a = AlertActions{}
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return errors.New("unsupported alert actions type")
+	}
+	actions := AlertActions{}
+	if err := json.Unmarshal(b, &actions); err != nil {
+		return err
+	}
+	*a = actions
+	return nil
+}
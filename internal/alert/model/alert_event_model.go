@@ -0,0 +1,71 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	ChannelPush    = "push"
+	ChannelWebhook = "webhook"
+	ChannelEmail   = "email"
+	ChannelBot     = "bot"
+)
+
+const (
+	DeliveryPending = "pending"
+	DeliverySent    = "sent"
+	DeliveryFailed  = "failed"
+)
+
+// DeliveryStatus is a delivery status of an alert event keyed by channel
+type DeliveryStatus map[string]string
+
+// Value implements driver.Valuer and stores the delivery status as json text
+func (d DeliveryStatus) Value() (driver.Value, error) {
+	if d == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner and reads the delivery status from json text
+func (d *DeliveryStatus) Scan(src interface{}) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*d = DeliveryStatus{}
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return errors.New("unsupported delivery status type")
+	}
+	status := DeliveryStatus{}
+	if err := json.Unmarshal(b, &status); err != nil {
+		return err
+	}
+	*d = status
+	return nil
+}
+
+// AlertEvent is a record of an alert being fired
+type AlertEvent struct {
+	ID             uint           `gorm:"column:id"`
+	AlertID        uint           `gorm:"column:alert_id"`
+	ObservedPrice  float64        `gorm:"column:observed_price"`
+	Threshold      float64        `gorm:"column:threshold"`
+	DeliveryStatus DeliveryStatus `gorm:"column:delivery_status"`
+	// IsTest is true for an event fired by a user to test the alert's actions
+	IsTest    bool      `gorm:"column:is_test"`
+	FiredAt   time.Time `gorm:"column:fired_at"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
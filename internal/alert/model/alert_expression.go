@@ -0,0 +1,57 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// Expression is a tree of alert conditions stored as json text.
+//
+// A node is either a logical operator or a leaf condition.
+// An operator node has Op ("and" or "or") and at least two Conditions.
+// A leaf node has Type, Option, Threshold and Window in the same form as an alert's
+// AlertType, AlertOption, AlertValue and AlertWindow and watches PairAddress, or the
+// alert's pair if empty. A ratio leaf compares the price of PairAddress divided by
+// the price of QuoteAddress.
+type Expression struct {
+	Op           string        `json:"op,omitempty"`
+	Conditions   []*Expression `json:"conditions,omitempty"`
+	Type         string        `json:"type,omitempty"`
+	Option       string        `json:"option,omitempty"`
+	Threshold    string        `json:"value,omitempty"`
+	Window       string        `json:"window,omitempty"`
+	PairAddress  string        `json:"pairAddress,omitempty"`
+	QuoteAddress string        `json:"quoteAddress,omitempty"`
+}
+
+// Value implements driver.Valuer and stores the expression as json text
+func (e Expression) Value() (driver.Value, error) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner and reads the expression from json text
+func (e *Expression) Scan(src interface{}) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*e = Expression{}
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return errors.New("unsupported alert expression type")
+	}
+	expression := Expression{}
+	if err := json.Unmarshal(b, &expression); err != nil {
+		return err
+	}
+	*e = expression
+	return nil
+}
@@ -5,21 +5,74 @@ import (
 	"time"
 )
 
+const (
+	AlertStatusActive    = "active"
+	AlertStatusTriggered = "triggered"
+	AlertStatusCompleted = "completed"
+	AlertStatusExpired   = "expired"
+	AlertStatusPaused    = "paused"
+	// AlertStatusSnoozed is a paused status resumed automatically at SnoozedUntil
+	AlertStatusSnoozed = "snoozed"
+)
+
+const (
+	// RearmOnce completes an alert after it was triggered
+	RearmOnce = "once"
+	// RearmAuto re-arms a triggered alert when its condition no longer holds
+	RearmAuto = "auto"
+	// RearmCooldown re-arms a triggered alert when CooldownMinutes passed since it was triggered
+	RearmCooldown = "cooldown"
+	// RearmBand re-arms a triggered alert when the observed value returns past the threshold
+	// by Hysteresis percent of the threshold
+	RearmBand = "band"
+)
+
+// alertStatusTransitions is a set of allowed next statuses keyed by current status.
+// expired is a terminal status and completed alerts are only re-armed when their condition is changed.
+var alertStatusTransitions = map[string][]string{
+	AlertStatusActive:    {AlertStatusTriggered, AlertStatusExpired, AlertStatusPaused, AlertStatusSnoozed},
+	AlertStatusTriggered: {AlertStatusActive, AlertStatusCompleted, AlertStatusExpired, AlertStatusPaused, AlertStatusSnoozed},
+	AlertStatusPaused:    {AlertStatusActive, AlertStatusExpired},
+	AlertStatusSnoozed:   {AlertStatusActive, AlertStatusExpired, AlertStatusPaused},
+	AlertStatusCompleted: {AlertStatusActive},
+}
+
+// CanTransitAlertStatus returns true if an alert is allowed to move from given status to given status
+func CanTransitAlertStatus(from, to string) bool {
+	for _, s := range alertStatusTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
 type Alert struct {
-	ID             uint      `gorm:"column:id"`
-	Slug           string    `gorm:"column:slug"`
-	Title          string    `gorm:"column:title"`
-	Body           string    `gorm:"column:body"`
-	PairAddress    string    `gorm:"column:pair_address"`
-	AlertType      string    `gorm:"column:alert_type"`
-	AlertValue     string    `gorm:"column:alert_value"`
-	AlertOption    string    `gorm:"column:alert_option"`
-	ExpirationTime time.Time `gorm:"column:expiration_time"`
-	AlertActions   string    `gorm:"column:alert_actions"`
-	AlertStatus    string    `gorm:"column:alert_status"`
-	CreatedAt      time.Time `gorm:"column:created_at"`
-	UpdatedAt      time.Time `gorm:"column:updated_at"`
-	DeletedAtUnix  int64     `gorm:"column:deleted_at_unix"`
-	Account        accountModel.Account
-	AccountId      uint
+	ID                uint         `gorm:"column:id"`
+	Slug              string       `gorm:"column:slug"`
+	Title             string       `gorm:"column:title"`
+	Body              string       `gorm:"column:body"`
+	PairAddress       string       `gorm:"column:pair_address"`
+	AlertType         string       `gorm:"column:alert_type"`
+	AlertValue        string       `gorm:"column:alert_value"`
+	AlertOption       string       `gorm:"column:alert_option"`
+	AlertWindow       string       `gorm:"column:alert_window"`
+	BaselinePrice     *float64     `gorm:"column:baseline_price"`
+	AlertExpression   *Expression  `gorm:"column:alert_expression"`
+	ExpirationTime    time.Time    `gorm:"column:expiration_time"`
+	AlertActions      AlertActions `gorm:"column:alert_actions"`
+	AlertStatus       string       `gorm:"column:alert_status"`
+	RearmPolicy       string       `gorm:"column:rearm_policy"`
+	CooldownMinutes   int          `gorm:"column:cooldown_minutes"`
+	Hysteresis        float64      `gorm:"column:hysteresis"`
+	LastObservedPrice *float64     `gorm:"column:last_observed_price"`
+	LastObservedAt    *time.Time   `gorm:"column:last_observed_at"`
+	SnoozedUntil      *time.Time   `gorm:"column:snoozed_until"`
+	StatusChangedAt   *time.Time   `gorm:"column:status_changed_at"`
+	LastTriggeredAt   *time.Time   `gorm:"column:last_triggered_at"`
+	CreatedAt         time.Time    `gorm:"column:created_at"`
+	UpdatedAt         time.Time    `gorm:"column:updated_at"`
+	DeletedAtUnix     int64        `gorm:"column:deleted_at_unix"`
+	Account           accountModel.Account
+	AccountId         uint
 }
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// DeliveryDead is a status of an outbox delivery which exceeded the max attempts
+const DeliveryDead = "dead"
+
+// DeliveryPayload is a snapshot of a fired alert which a delivery sends through its action
+type DeliveryPayload struct {
+	Action        AlertAction `json:"action"`
+	Token         string      `json:"token,omitempty"`
+	Slug          string      `json:"slug"`
+	Title         string      `json:"title"`
+	Body          string      `json:"body"`
+	PairAddress   string      `json:"pairAddress"`
+	AlertType     string      `json:"alertType"`
+	AlertOption   string      `json:"alertOption"`
+	Threshold     float64     `json:"threshold"`
+	ObservedPrice float64     `json:"observedPrice"`
+	FiredAt       time.Time   `json:"firedAt"`
+	IsTest        bool        `json:"isTest,omitempty"`
+}
+
+// Value implements driver.Valuer and stores the payload as json text
+func (p DeliveryPayload) Value() (driver.Value, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner and reads the payload from json text
+func (p *DeliveryPayload) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, p)
+	case string:
+		return json.Unmarshal([]byte(v), p)
+	}
+	return errors.New("unsupported delivery payload type")
+}
+
+// Delivery is an entry of the notification outbox.
+// Each delivery sends an alert event through a single channel and is retried until
+// it is sent or reaches the max attempts, in which case it becomes dead.
+//
+//	pending -> sent
+//	pending -> pending with next attempt time if failed
+//	pending -> dead if failed at the last attempt
+//	dead -> pending if replayed
+type Delivery struct {
+	ID            uint            `gorm:"column:id"`
+	AlertEventID  uint            `gorm:"column:alert_event_id"`
+	AlertID       uint            `gorm:"column:alert_id"`
+	Channel       string          `gorm:"column:channel"`
+	Payload       DeliveryPayload `gorm:"column:payload"`
+	Status        string          `gorm:"column:status"`
+	Attempts      int             `gorm:"column:attempts"`
+	NextAttemptAt time.Time       `gorm:"column:next_attempt_at"`
+	LastError     string          `gorm:"column:last_error"`
+	CreatedAt     time.Time       `gorm:"column:created_at"`
+	UpdatedAt     time.Time       `gorm:"column:updated_at"`
+}
+
+// TableName overrides the table name of Delivery
+func (Delivery) TableName() string {
+	return "notification_outbox"
+}
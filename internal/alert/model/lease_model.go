@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// Lease is a named lock held by one replica until ExpiresAt unless renewed
+type Lease struct {
+	Name      string    `gorm:"column:name;primaryKey"`
+	Holder    string    `gorm:"column:holder"`
+	ExpiresAt time.Time `gorm:"column:expires_at"`
+}
+
+// TableName overrides the table name of Lease
+func (Lease) TableName() string {
+	return "scheduler_leases"
+}
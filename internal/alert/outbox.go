@@ -0,0 +1,119 @@
+package alert
+
+import (
+	"context"
+	"time"
+
+	alertDB "kek-backend/internal/alert/database"
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/config"
+	"kek-backend/pkg/logging"
+)
+
+// recordTimeout is a timeout of recording the result of a delivery
+const recordTimeout = 3 * time.Second
+
+// OutboxWorker sends pending deliveries in the notification outbox.
+// A failed delivery is retried with exponential backoff and becomes dead
+// after maxAttempts attempts.
+type OutboxWorker struct {
+	db          alertDB.AlertDB
+	dispatcher  *Dispatcher
+	interval    time.Duration
+	batchSize   uint
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	now         func() time.Time
+}
+
+// Process sends a batch of due deliveries and returns the number of processed deliveries
+func (w *OutboxWorker) Process(ctx context.Context) int {
+	logger := logging.FromContext(ctx)
+	deliveries, err := w.db.FindDueDeliveries(ctx, w.now(), w.batchSize)
+	if err != nil {
+		logger.Errorw("alert.outbox failed to find due deliveries", "err", err)
+		return 0
+	}
+	for i, d := range deliveries {
+		// remaining deliveries are left pending to be sent by the next run on shutdown
+		if ctx.Err() != nil {
+			logger.Warnw("alert.outbox stopped with deliveries left", "left", len(deliveries)-i, "err", ctx.Err())
+			return i
+		}
+		w.deliver(ctx, d)
+	}
+	return len(deliveries)
+}
+
+// deliver sends given delivery and records the result to the delivery and its alert event
+func (w *OutboxWorker) deliver(ctx context.Context, d *model.Delivery) {
+	logger := logging.FromContext(ctx)
+	err := w.dispatcher.Send(ctx, &d.Payload)
+	if err != nil && ctx.Err() != nil {
+		// a delivery interrupted by shutdown is left pending without counting the attempt
+		logger.Warnw("alert.outbox delivery interrupted", "delivery", d.ID, "err", err)
+		return
+	}
+	d.Attempts++
+	eventStatus := model.DeliverySent
+	if err != nil {
+		d.LastError = err.Error()
+		if d.Attempts >= w.maxAttempts {
+			logger.Warnw("alert.outbox delivery is dead", "delivery", d.ID, "attempts", d.Attempts, "err", err)
+			d.Status = model.DeliveryDead
+			eventStatus = model.DeliveryFailed
+		} else {
+			d.NextAttemptAt = w.now().Add(w.Backoff(d.Attempts))
+			eventStatus = ""
+		}
+	} else {
+		d.Status = model.DeliverySent
+		d.LastError = ""
+	}
+
+	// the result is recorded even if given context is done after sending
+	// so that a sent delivery is not sent again by the next run
+	recordCtx, cancel := context.WithTimeout(logging.WithLogger(context.Background(), logger), recordTimeout)
+	defer cancel()
+	err = w.db.RunInTx(recordCtx, func(ctx context.Context) error {
+		if err := w.db.UpdateDelivery(ctx, d); err != nil {
+			return err
+		}
+		if eventStatus == "" {
+			return nil
+		}
+		return w.db.UpdateAlertEventDelivery(ctx, d.AlertEventID, d.Channel, eventStatus)
+	})
+	if err != nil {
+		logger.Errorw("alert.outbox failed to update delivery", "delivery", d.ID, "err", err)
+	}
+}
+
+// Backoff returns a delay before the next attempt of a delivery failed given times.
+// The delay doubles on each attempt and is capped with maxBackoff.
+func (w *OutboxWorker) Backoff(attempts int) time.Duration {
+	delay := w.backoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= w.maxBackoff {
+			return w.maxBackoff
+		}
+	}
+	return delay
+}
+
+// NewOutboxWorker creates a new outbox worker with given config
+func NewOutboxWorker(cfg *config.Config, db alertDB.AlertDB, dispatcher *Dispatcher) *OutboxWorker {
+	c := cfg.AlertConfig.Outbox
+	return &OutboxWorker{
+		db:          db,
+		dispatcher:  dispatcher,
+		interval:    time.Duration(c.IntervalSecs) * time.Second,
+		batchSize:   uint(c.BatchSize),
+		maxAttempts: c.MaxAttempts,
+		backoff:     time.Duration(c.BackoffSecs) * time.Second,
+		maxBackoff:  time.Duration(c.MaxBackoffSecs) * time.Second,
+		now:         time.Now,
+	}
+}
@@ -0,0 +1,190 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	alertDB "kek-backend/internal/alert/database"
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/config"
+	"kek-backend/internal/notification"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOutboxWorker_Process(t *testing.T) {
+	now := time.Now()
+	cases := []struct {
+		Name     string
+		Attempts int
+		Err      error
+		// expected
+		Status        string
+		EventStatus   string
+		NextAttemptAt time.Time
+	}{
+		{
+			Name:        "Sent",
+			Status:      model.DeliverySent,
+			EventStatus: model.DeliverySent,
+		}, {
+			Name:          "Retry with backoff",
+			Attempts:      2,
+			Err:           errors.New("fcm unavailable"),
+			Status:        model.DeliveryPending,
+			NextAttemptAt: now.Add(40 * time.Second),
+		}, {
+			Name:        "Dead at max attempts",
+			Attempts:    7,
+			Err:         errors.New("fcm unavailable"),
+			Status:      model.DeliveryDead,
+			EventStatus: model.DeliveryFailed,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// given
+			db := newTxAlertDB()
+			notifier := notification.NewFakeNotifier()
+			notifier.Err = tc.Err
+			worker := newTestOutboxWorker(db, notifier, now)
+			delivery := &model.Delivery{
+				ID:           1,
+				AlertEventID: 10,
+				Channel:      model.ChannelPush,
+				Payload:      model.DeliveryPayload{Action: model.AlertAction{Type: model.ChannelPush}, Token: "device-token"},
+				Status:       model.DeliveryPending,
+				Attempts:     tc.Attempts,
+			}
+			db.On("FindDueDeliveries", mock.Anything, now, uint(50)).Return([]*model.Delivery{delivery}, nil)
+			db.On("UpdateDelivery", mock.Anything, mock.Anything).Return(nil)
+			db.On("UpdateAlertEventDelivery", mock.Anything, uint(10), model.ChannelPush, mock.Anything).Return(nil)
+
+			// when
+			processed := worker.Process(context.Background())
+
+			// then
+			assert.Equal(t, 1, processed)
+			assert.Equal(t, 1, len(notifier.Messages()))
+			db.AssertCalled(t, "UpdateDelivery", mock.Anything, mock.MatchedBy(func(d *model.Delivery) bool {
+				return d.Status == tc.Status && d.Attempts == tc.Attempts+1
+			}))
+			if tc.Err != nil {
+				assert.Equal(t, tc.Err.Error(), delivery.LastError)
+			}
+			if !tc.NextAttemptAt.IsZero() {
+				assert.Equal(t, tc.NextAttemptAt, delivery.NextAttemptAt)
+			}
+			if tc.EventStatus == "" {
+				db.AssertNotCalled(t, "UpdateAlertEventDelivery", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				db.AssertCalled(t, "UpdateAlertEventDelivery", mock.Anything, uint(10), model.ChannelPush, tc.EventStatus)
+			}
+		})
+	}
+}
+
+func TestOutboxWorker_ProcessStopsIfCanceled(t *testing.T) {
+	// given
+	now := time.Now()
+	db := newTxAlertDB()
+	notifier := notification.NewFakeNotifier()
+	worker := newTestOutboxWorker(db, notifier, now)
+	delivery := &model.Delivery{ID: 1, Channel: model.ChannelPush, Status: model.DeliveryPending}
+	db.On("FindDueDeliveries", mock.Anything, now, uint(50)).Return([]*model.Delivery{delivery}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// when
+	processed := worker.Process(ctx)
+
+	// then
+	assert.Equal(t, 0, processed)
+	assert.Equal(t, 0, len(notifier.Messages()))
+	db.AssertNotCalled(t, "UpdateDelivery", mock.Anything, mock.Anything)
+}
+
+func TestOutboxWorker_DeliverLeavesInterruptedDeliveryPending(t *testing.T) {
+	// given
+	db := newTxAlertDB()
+	notifier := notification.NewFakeNotifier()
+	notifier.Err = context.Canceled
+	worker := newTestOutboxWorker(db, notifier, time.Now())
+	delivery := &model.Delivery{
+		ID:       1,
+		Channel:  model.ChannelPush,
+		Payload:  model.DeliveryPayload{Action: model.AlertAction{Type: model.ChannelPush}, Token: "device-token"},
+		Status:   model.DeliveryPending,
+		Attempts: 2,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// when
+	worker.deliver(ctx, delivery)
+
+	// then
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Equal(t, model.DeliveryPending, delivery.Status)
+	db.AssertNotCalled(t, "UpdateDelivery", mock.Anything, mock.Anything)
+}
+
+// cancelingNotifier cancels a context after sending a message
+type cancelingNotifier struct {
+	cancel context.CancelFunc
+}
+
+func (n *cancelingNotifier) Notify(_ context.Context, _ *notification.Message) error {
+	n.cancel()
+	return nil
+}
+
+func TestOutboxWorker_DeliverRecordsSentDeliveryIfCanceledAfterSend(t *testing.T) {
+	// given
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db := newTxAlertDB()
+	worker := newTestOutboxWorker(db, &cancelingNotifier{cancel: cancel}, time.Now())
+	delivery := &model.Delivery{
+		ID:           1,
+		AlertEventID: 10,
+		Channel:      model.ChannelPush,
+		Payload:      model.DeliveryPayload{Action: model.AlertAction{Type: model.ChannelPush}, Token: "device-token"},
+		Status:       model.DeliveryPending,
+	}
+	db.On("UpdateDelivery", mock.MatchedBy(func(ctx context.Context) bool {
+		return ctx.Err() == nil
+	}), mock.Anything).Return(nil)
+	db.On("UpdateAlertEventDelivery", mock.Anything, uint(10), model.ChannelPush, model.DeliverySent).Return(nil)
+
+	// when
+	worker.deliver(ctx, delivery)
+
+	// then
+	assert.Error(t, ctx.Err())
+	assert.Equal(t, model.DeliverySent, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	db.AssertCalled(t, "UpdateDelivery", mock.Anything, delivery)
+	db.AssertCalled(t, "UpdateAlertEventDelivery", mock.Anything, uint(10), model.ChannelPush, model.DeliverySent)
+}
+
+func TestOutboxWorker_Backoff(t *testing.T) {
+	worker := newTestOutboxWorker(newTxAlertDB(), notification.NewFakeNotifier(), time.Now())
+
+	assert.Equal(t, 10*time.Second, worker.Backoff(1))
+	assert.Equal(t, 20*time.Second, worker.Backoff(2))
+	assert.Equal(t, 80*time.Second, worker.Backoff(4))
+	assert.Equal(t, time.Hour, worker.Backoff(20))
+}
+
+func newTestOutboxWorker(db alertDB.AlertDB, notifier notification.Notifier, now time.Time) *OutboxWorker {
+	cfg, _ := config.Load("")
+	worker := NewOutboxWorker(cfg, db, NewDispatcher(notifier, nil, nil, nil))
+	worker.now = func() time.Time {
+		return now
+	}
+	return worker
+}
@@ -0,0 +1,64 @@
+package alert
+
+import (
+	"fmt"
+	"time"
+
+	"kek-backend/internal/alert/model"
+)
+
+const (
+	// maxCooldownMinutes is the longest cooldown of a cooldown re-arm policy, a week
+	maxCooldownMinutes = 7 * 24 * 60
+	// maxHysteresis is the widest band in percent of a band re-arm policy
+	maxHysteresis = 50
+)
+
+// ValidateRearm checks given re-arm policy has the fields it requires.
+// A cooldown policy requires cooldownMinutes and a band policy requires hysteresis,
+// the other policies must not have them.
+func ValidateRearm(policy string, cooldownMinutes int, hysteresis float64) error {
+	if policy != model.RearmCooldown && cooldownMinutes != 0 {
+		return &ConditionError{Field: "cooldownMinutes", Value: fmt.Sprint(cooldownMinutes), Message: "cooldownMinutes is only allowed for cooldown rearmPolicy"}
+	}
+	if policy != model.RearmBand && hysteresis != 0 {
+		return &ConditionError{Field: "hysteresis", Value: fmt.Sprint(hysteresis), Message: "hysteresis is only allowed for band rearmPolicy"}
+	}
+	switch policy {
+	case model.RearmCooldown:
+		if cooldownMinutes < 1 || cooldownMinutes > maxCooldownMinutes {
+			return &ConditionError{Field: "cooldownMinutes", Value: fmt.Sprint(cooldownMinutes),
+				Message: fmt.Sprintf("cooldownMinutes must be between 1 and %d", maxCooldownMinutes)}
+		}
+	case model.RearmBand:
+		if hysteresis <= 0 || hysteresis > maxHysteresis {
+			return &ConditionError{Field: "hysteresis", Value: fmt.Sprint(hysteresis),
+				Message: fmt.Sprintf("hysteresis must be greater than 0 and at most %d percent", maxHysteresis)}
+		}
+	}
+	return nil
+}
+
+// rearmed returns true if given triggered alert re-arms by its re-arm policy
+//
+//	auto: the condition no longer holds
+//	cooldown: CooldownMinutes passed since the alert was triggered
+//	band: the observed value returned past the threshold by Hysteresis percent
+func rearmed(a *model.Alert, result *Result) bool {
+	switch a.RearmPolicy {
+	case model.RearmAuto:
+		return !result.Holds
+	case model.RearmCooldown:
+		if a.LastTriggeredAt == nil {
+			return true
+		}
+		cooldown := time.Duration(a.CooldownMinutes) * time.Minute
+		return result.ObservedAt.Sub(*a.LastTriggeredAt) >= cooldown
+	case model.RearmBand:
+		if !result.Observed {
+			return !result.Holds
+		}
+		return result.Condition.PastBand(result.Observation, a.Hysteresis)
+	}
+	return false
+}
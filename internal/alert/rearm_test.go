@@ -0,0 +1,41 @@
+package alert
+
+import (
+	"kek-backend/internal/alert/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRearm(t *testing.T) {
+	cases := []struct {
+		Name       string
+		Policy     string
+		Cooldown   int
+		Hysteresis float64
+		// expected
+		Field string
+	}{
+		{Name: "Once", Policy: model.RearmOnce},
+		{Name: "Cooldown", Policy: model.RearmCooldown, Cooldown: 60},
+		{Name: "Band", Policy: model.RearmBand, Hysteresis: 2.5},
+		{Name: "Cooldown without minutes", Policy: model.RearmCooldown, Field: "cooldownMinutes"},
+		{Name: "Cooldown longer than a week", Policy: model.RearmCooldown, Cooldown: 10081, Field: "cooldownMinutes"},
+		{Name: "Band without hysteresis", Policy: model.RearmBand, Field: "hysteresis"},
+		{Name: "Band too wide", Policy: model.RearmBand, Hysteresis: 51, Field: "hysteresis"},
+		{Name: "Minutes without cooldown", Policy: model.RearmAuto, Cooldown: 5, Field: "cooldownMinutes"},
+		{Name: "Hysteresis without band", Policy: model.RearmOnce, Hysteresis: 5, Field: "hysteresis"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := ValidateRearm(tc.Policy, tc.Cooldown, tc.Hysteresis)
+			if tc.Field == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Equal(t, tc.Field, err.(*ConditionError).Field)
+		})
+	}
+}
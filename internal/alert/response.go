@@ -15,27 +15,90 @@ type AlertsResponse struct {
 	AlertsCount int64   `json:"alertsCount"`
 }
 
+// AlertsCountResponse is a number of alerts changed by a request
+type AlertsCountResponse struct {
+	AlertsCount int64 `json:"alertsCount"`
+}
+
 type Alert struct {
-	Slug           string    `json:"slug"`
-	Title          string    `json:"title"`
-	Body           string    `json:"body"`
-	PairAddress    string    `json:"pairAddress"`
-	AlertType      string    `json:"alertType"`
-	AlertValue     string    `json:"alertValue"`
-	AlertOption    string    `json:"alertOption"`
-	ExpirationTime time.Time `json:"expirationTime"`
-	AlertActions   string    `json:"alertActions"`
-	AlertStatus    string    `json:"alertStatus"`
-	CreatedAt      time.Time `json:"createdAt"`
-	UpdatedAt      time.Time `json:"updatedAt"`
-	Account        accountModel.Account
-}
-
-// NewAlertsResponse converts alert models and total count to AlertsResponse
+	Slug              string            `json:"slug"`
+	Title             string            `json:"title"`
+	Body              string            `json:"body"`
+	PairAddress       string            `json:"pairAddress"`
+	AlertType         string            `json:"alertType"`
+	AlertValue        string            `json:"alertValue"`
+	AlertOption       string            `json:"alertOption"`
+	AlertWindow       string            `json:"alertWindow,omitempty"`
+	BaselinePrice     *float64          `json:"baselinePrice,omitempty"`
+	AlertExpression   *model.Expression `json:"alertExpression,omitempty"`
+	ExpirationTime    time.Time         `json:"expirationTime"`
+	AlertActions      []AlertAction     `json:"alertActions"`
+	AlertStatus       string            `json:"alertStatus"`
+	RearmPolicy       string            `json:"rearmPolicy"`
+	CooldownMinutes   int               `json:"cooldownMinutes,omitempty"`
+	Hysteresis        float64           `json:"hysteresis,omitempty"`
+	LastObservedPrice *float64          `json:"lastObservedPrice"`
+	LastObservedAt    *time.Time        `json:"lastObservedAt"`
+	SnoozedUntil      *time.Time        `json:"snoozedUntil,omitempty"`
+	StatusChangedAt   *time.Time        `json:"statusChangedAt"`
+	LastTriggeredAt   *time.Time        `json:"lastTriggeredAt"`
+	CreatedAt         time.Time         `json:"createdAt"`
+	UpdatedAt         time.Time         `json:"updatedAt"`
+	Account           accountModel.Account
+}
+
+// AlertAction is an alert action without its webhook secret.
+// Destinations are empty unless the alert is served to its owner
+type AlertAction struct {
+	Type   string `json:"type"`
+	URL    string `json:"url,omitempty"`
+	Email  string `json:"email,omitempty"`
+	ChatID string `json:"chatId,omitempty"`
+}
+
+type AlertEventsResponse struct {
+	Events      []AlertEvent `json:"events"`
+	EventsCount int64        `json:"eventsCount"`
+}
+
+type AlertEvent struct {
+	ID             uint              `json:"id"`
+	ObservedPrice  float64           `json:"observedPrice"`
+	Threshold      float64           `json:"threshold"`
+	DeliveryStatus map[string]string `json:"deliveryStatus"`
+	IsTest         bool              `json:"isTest"`
+	FiredAt        time.Time         `json:"firedAt"`
+}
+
+type TestAlertResponse struct {
+	Event          AlertEvent        `json:"event"`
+	DeliveryErrors map[string]string `json:"deliveryErrors,omitempty"`
+}
+
+type DeliveriesResponse struct {
+	Deliveries      []Delivery `json:"deliveries"`
+	DeliveriesCount int64      `json:"deliveriesCount"`
+}
+
+type Delivery struct {
+	ID            uint      `json:"id"`
+	AlertEventID  uint      `json:"alertEventId"`
+	AlertID       uint      `json:"alertId"`
+	Channel       string    `json:"channel"`
+	Status        string    `json:"status"`
+	Attempts      int       `json:"attempts"`
+	NextAttemptAt time.Time `json:"nextAttemptAt"`
+	LastError     string    `json:"lastError"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// NewAlertsResponse converts alert models and total count to AlertsResponse.
+// Alerts are listed to anyone so that destinations of their actions are hidden
 func NewAlertsResponse(alerts []*model.Alert, total int64) *AlertsResponse {
 	var a []Alert
 	for _, alert := range alerts {
-		a = append(a, NewAlertResponse(alert).Alert)
+		a = append(a, NewPublicAlertResponse(alert).Alert)
 	}
 
 	return &AlertsResponse{
@@ -48,19 +111,124 @@ func NewAlertsResponse(alerts []*model.Alert, total int64) *AlertsResponse {
 func NewAlertResponse(a *model.Alert) *AlertResponse {
 	return &AlertResponse{
 		Alert: Alert{
-			Slug:           a.Slug,
-			Title:          a.Title,
-			Body:           a.Body,
-			PairAddress:    a.PairAddress,
-			AlertType:      a.AlertType,
-			AlertValue:     a.AlertValue,
-			AlertOption:    a.AlertOption,
-			ExpirationTime: a.ExpirationTime,
-			AlertActions:   a.AlertActions,
-			AlertStatus:    a.AlertStatus,
-			CreatedAt:      a.CreatedAt,
-			UpdatedAt:      a.UpdatedAt,
-			Account:        a.Account,
+			Slug:              a.Slug,
+			Title:             a.Title,
+			Body:              a.Body,
+			PairAddress:       a.PairAddress,
+			AlertType:         a.AlertType,
+			AlertValue:        a.AlertValue,
+			AlertOption:       a.AlertOption,
+			AlertWindow:       a.AlertWindow,
+			BaselinePrice:     a.BaselinePrice,
+			AlertExpression:   alertExpression(a),
+			ExpirationTime:    a.ExpirationTime,
+			AlertActions:      newAlertActions(a.AlertActions),
+			AlertStatus:       a.AlertStatus,
+			RearmPolicy:       a.RearmPolicy,
+			CooldownMinutes:   a.CooldownMinutes,
+			Hysteresis:        a.Hysteresis,
+			LastObservedPrice: a.LastObservedPrice,
+			LastObservedAt:    a.LastObservedAt,
+			SnoozedUntil:      a.SnoozedUntil,
+			StatusChangedAt:   a.StatusChangedAt,
+			LastTriggeredAt:   a.LastTriggeredAt,
+			CreatedAt:         a.CreatedAt,
+			UpdatedAt:         a.UpdatedAt,
+			Account:           a.Account,
 		},
 	}
 }
+
+// NewPublicAlertResponse converts alert model to AlertResponse for users other than the owner
+// keeping only types of the alert actions
+func NewPublicAlertResponse(a *model.Alert) *AlertResponse {
+	res := NewAlertResponse(a)
+	res.Alert.AlertActions = newPublicAlertActions(a.AlertActions)
+	return res
+}
+
+// NewAlertEventsResponse converts alert event models and total count to AlertEventsResponse
+func NewAlertEventsResponse(events []*model.AlertEvent, total int64) *AlertEventsResponse {
+	e := []AlertEvent{}
+	for _, event := range events {
+		e = append(e, newAlertEvent(event))
+	}
+	return &AlertEventsResponse{
+		Events:      e,
+		EventsCount: total,
+	}
+}
+
+// NewTestAlertResponse converts a test event and errors of its failed deliveries keyed by channel to TestAlertResponse
+func NewTestAlertResponse(event *model.AlertEvent, deliveryErrors map[string]string) *TestAlertResponse {
+	return &TestAlertResponse{
+		Event:          newAlertEvent(event),
+		DeliveryErrors: deliveryErrors,
+	}
+}
+
+func newAlertEvent(event *model.AlertEvent) AlertEvent {
+	return AlertEvent{
+		ID:             event.ID,
+		ObservedPrice:  event.ObservedPrice,
+		Threshold:      event.Threshold,
+		DeliveryStatus: event.DeliveryStatus,
+		IsTest:         event.IsTest,
+		FiredAt:        event.FiredAt,
+	}
+}
+
+// newAlertActions converts alert actions to responses hiding webhook secrets
+func newAlertActions(actions model.AlertActions) []AlertAction {
+	res := make([]AlertAction, 0, len(actions))
+	for _, action := range actions {
+		res = append(res, AlertAction{
+			Type:   action.Type,
+			URL:    action.URL,
+			Email:  action.Email,
+			ChatID: action.ChatID,
+		})
+	}
+	return res
+}
+
+// newPublicAlertActions converts alert actions to responses hiding their destinations
+func newPublicAlertActions(actions model.AlertActions) []AlertAction {
+	res := make([]AlertAction, 0, len(actions))
+	for _, action := range actions {
+		res = append(res, AlertAction{Type: action.Type})
+	}
+	return res
+}
+
+// NewDeliveriesResponse converts outbox deliveries and total count to DeliveriesResponse.
+// Payloads are not included since they hold device tokens and webhook secrets.
+func NewDeliveriesResponse(deliveries []*model.Delivery, total int64) *DeliveriesResponse {
+	d := []Delivery{}
+	for _, delivery := range deliveries {
+		d = append(d, Delivery{
+			ID:            delivery.ID,
+			AlertEventID:  delivery.AlertEventID,
+			AlertID:       delivery.AlertID,
+			Channel:       delivery.Channel,
+			Status:        delivery.Status,
+			Attempts:      delivery.Attempts,
+			NextAttemptAt: delivery.NextAttemptAt,
+			LastError:     delivery.LastError,
+			CreatedAt:     delivery.CreatedAt,
+			UpdatedAt:     delivery.UpdatedAt,
+		})
+	}
+	return &DeliveriesResponse{
+		Deliveries:      d,
+		DeliveriesCount: total,
+	}
+}
+
+// alertExpression returns the expression of given alert or nil if the alert has a single condition
+func alertExpression(a *model.Alert) *model.Expression {
+	if !IsExpressionAlert(a) {
+		return nil
+	}
+	return a.AlertExpression
+}
@@ -0,0 +1,340 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	alertDB "kek-backend/internal/alert/database"
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/config"
+	"kek-backend/internal/metric"
+	tokenDB "kek-backend/internal/token/database"
+	"kek-backend/internal/uniswap"
+	"kek-backend/pkg/logging"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/fx"
+)
+
+// schedulerLease is the name of the lease held by the replica running the scheduler
+const schedulerLease = "alert-scheduler"
+
+const (
+	// cancelGracePeriod is how long canceled jobs are waited for after the shutdown deadline
+	cancelGracePeriod = time.Second
+	// releaseTimeout is a timeout of releasing the lease and reporting deliveries left on shutdown
+	releaseTimeout = 3 * time.Second
+)
+
+// Scheduler evaluates alerts and sends the notification outbox periodically.
+// Jobs run only on the replica holding the scheduler lease so that
+// alerts are evaluated and notified once across replicas.
+type Scheduler struct {
+	db        alertDB.AlertDB
+	tokenDB   tokenDB.TokenDB
+	prices    *uniswap.PriceCache
+	outbox    *OutboxWorker
+	evaluator *Evaluator
+	mp        *metric.MetricsProvider
+	schedule  string
+	batchSize uint
+	// workers is the number of goroutines evaluating a page of alerts
+	workers int
+	// tickTimeout is a deadline of evaluating all alerts in a tick
+	tickTimeout time.Duration
+	lease       time.Duration
+	holder      string
+	cron        *cron.Cron
+	// shutdown is how long running jobs are waited for on stop
+	shutdown time.Duration
+	// ctx is passed to jobs and canceled if they are still running at the shutdown deadline
+	ctx    context.Context
+	cancel context.CancelFunc
+	now    func() time.Time
+}
+
+// leading acquires or renews the scheduler lease and returns true if this replica holds it
+func (s *Scheduler) leading(ctx context.Context) bool {
+	logger := logging.FromContext(ctx)
+	acquired, err := s.db.AcquireLease(ctx, schedulerLease, s.holder, s.now(), s.lease)
+	if err != nil {
+		logger.Errorw("alert.scheduler failed to acquire lease", "holder", s.holder, "err", err)
+		return false
+	}
+	if !acquired {
+		logger.Debugw("alert.scheduler lease held by another replica", "holder", s.holder)
+	}
+	return acquired
+}
+
+// evaluatedStatuses are statuses of alerts evaluated on every tick
+var evaluatedStatuses = []string{model.AlertStatusActive, model.AlertStatusTriggered}
+
+// evaluate expires and resumes alerts and evaluates active and triggered alerts of all accounts
+// page by page until all of them are evaluated or the tick deadline is exceeded
+func (s *Scheduler) evaluate(ctx context.Context) {
+	logger := logging.FromContext(ctx)
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, s.tickTimeout)
+	defer cancel()
+	var evaluated, fired int
+	defer func() {
+		s.mp.RecordAlertTick(time.Since(start), ctx.Err() == context.DeadlineExceeded)
+		s.mp.RecordAlertsEvaluated(evaluated)
+		s.mp.RecordAlertsFired(fired)
+	}()
+
+	expired, err := s.db.ExpireAlerts(ctx, s.now())
+	if err != nil {
+		logger.Errorw("alert.cron failed to expire alerts", "err", err)
+	} else if expired > 0 {
+		logger.Infow("alert.cron expired alerts", "count", expired)
+	}
+	// alerts are expired first so that snoozed alerts past their expiration time never resume
+	resumed, err := s.db.ResumeSnoozedAlerts(ctx, s.now())
+	if err != nil {
+		logger.Errorw("alert.cron failed to resume snoozed alerts", "err", err)
+	} else if resumed > 0 {
+		logger.Infow("alert.cron resumed snoozed alerts", "count", resumed)
+	}
+
+	ethPrice, err := s.prices.EthPrice(ctx)
+	if err != nil {
+		logger.Errorw("alert.cron failed to get eth price", "err", err)
+		return
+	}
+
+	// alerts are paged by id so that alerts changed during the tick are neither skipped nor evaluated twice
+	var afterID uint
+	for {
+		alerts, err := s.db.FindAlertsAfter(ctx, evaluatedStatuses, afterID, s.batchSize)
+		if err != nil {
+			logger.Errorw("alert.cron failed to find alerts", "afterID", afterID, "err", err)
+			return
+		}
+		if len(alerts) == 0 {
+			return
+		}
+		pageEvaluated, pageFired := s.evaluatePage(ctx, alerts, ethPrice)
+		evaluated += pageEvaluated
+		fired += pageFired
+		if ctx.Err() != nil {
+			logger.Warnw("alert.cron tick stopped before evaluating all alerts", "afterID", afterID, "evaluated", evaluated, "err", ctx.Err())
+			return
+		}
+		if uint(len(alerts)) < s.batchSize {
+			return
+		}
+		afterID = alerts[len(alerts)-1].ID
+	}
+}
+
+// evaluatePage fetches market data of tokens watched by given alerts and evaluates the alerts
+// in a pool of workers. It returns the number of evaluated and fired alerts.
+func (s *Scheduler) evaluatePage(ctx context.Context, alerts []*model.Alert, ethPrice float64) (int, int) {
+	logger := logging.FromContext(ctx)
+	addresses := pairAddresses(alerts)
+	tokens, err := s.prices.Tokens(ctx, addresses)
+	if err != nil {
+		// evaluate alerts with tokens fetched before the error
+		logger.Errorw("alert.cron failed to get tokens", "addresses", len(addresses), "fetched", len(tokens), "err", err)
+	}
+	usdPrices := make(map[string]float64, len(tokens))
+	markets := make(map[string]*Market, len(tokens))
+	for address, token := range tokens {
+		price, err := token.PriceUSD(ethPrice)
+		if err != nil {
+			logger.Warnw("alert.cron invalid token price", "pairAddress", address, "derivedETH", token.DerivedETH, "err", err)
+			continue
+		}
+		usdPrices[address] = price
+		m := &Market{Symbol: token.Symbol, Name: token.Name, Price: price}
+		if liquidity, err := token.LiquidityUSD(ethPrice); err == nil {
+			m.Liquidity, m.HasLiquidity = liquidity, true
+		}
+		markets[address] = m
+	}
+	now := s.now()
+	recordPrices(ctx, s.tokenDB, usdPrices, now)
+
+	// daily data are only fetched for tokens watched by volume spike alerts
+	if volume := volumeAddresses(alerts); len(volume) != 0 {
+		dayDatas, err := s.prices.TokenDayDatas(ctx, volume, volumeSpikeDays+1)
+		if err != nil {
+			logger.Errorw("alert.cron failed to get token day datas", "addresses", len(volume), "err", err)
+		}
+		for address, d := range dayDatas {
+			if m, ok := markets[address]; ok {
+				m.Volume, m.AverageVolume, m.HasVolume = volumeStats(d, now)
+			}
+		}
+	}
+
+	// markets are only read by the workers
+	var evaluated, fired int64
+	jobs := make(chan *model.Alert)
+	var wg sync.WaitGroup
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for alert := range jobs {
+				ok, f := s.evaluateAlert(ctx, alert, markets, now)
+				if ok {
+					atomic.AddInt64(&evaluated, 1)
+				}
+				if f {
+					atomic.AddInt64(&fired, 1)
+				}
+			}
+		}()
+	}
+feed:
+	for _, alert := range alerts {
+		select {
+		case jobs <- alert:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return int(evaluated), int(fired)
+}
+
+// evaluateAlert evaluates given alert with given market data observed at now and moves it through its lifecycle.
+// It returns whether the alert is evaluated and whether it fired.
+func (s *Scheduler) evaluateAlert(ctx context.Context, alert *model.Alert, markets map[string]*Market, now time.Time) (bool, bool) {
+	logger := logging.FromContext(ctx)
+	result, err := s.evaluator.Evaluate(ctx, alert, markets, now)
+	if err != nil {
+		logger.Warnw("alert.cron failed to evaluate alert", "alert", alert.Slug, "pairAddress", alert.PairAddress, "err", err)
+		return false, false
+	}
+	logger.Debugw("alert.cron evaluated alert", "alert", alert.Slug, "price", result.Observation.Price, "holds", result.Holds)
+	if result.Observed {
+		if err := s.db.UpdateAlertObservation(ctx, alert.ID, result.Observation.Price, now); err != nil {
+			logger.Warnw("alert.cron failed to save observation", "alert", alert.Slug, "err", err)
+		}
+	}
+	return true, handleEvaluation(ctx, s.db, alert, result)
+}
+
+// Start schedules the evaluation and outbox jobs
+func (s *Scheduler) Start() error {
+	if _, err := s.cron.AddFunc(s.schedule, func() {
+		if s.leading(s.ctx) {
+			s.evaluate(s.ctx)
+		}
+	}); err != nil {
+		return fmt.Errorf("invalid alert schedule %q: %w", s.schedule, err)
+	}
+	if _, err := s.cron.AddFunc(fmt.Sprintf("@every %s", s.outbox.interval), func() {
+		if s.leading(s.ctx) {
+			s.outbox.Process(s.ctx)
+		}
+	}); err != nil {
+		return err
+	}
+	s.cron.Start()
+	return nil
+}
+
+// Stop stops scheduling new jobs and waits for the running evaluation and deliveries
+// until the shutdown deadline or given context is done. Jobs still running at the deadline are canceled
+// so that interrupted deliveries are left pending in the outbox.
+// Then the lease is released so that another replica takes over without waiting for its expiration.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	logger := logging.FromContext(ctx)
+	stopped := s.cron.Stop().Done()
+	deadline, cancel := context.WithTimeout(ctx, s.shutdown)
+	defer cancel()
+	select {
+	case <-stopped:
+	case <-deadline.Done():
+		logger.Warnw("alert.scheduler jobs are still running at the shutdown deadline", "holder", s.holder, "shutdown", s.shutdown)
+		s.cancel()
+		select {
+		case <-stopped:
+		case <-time.After(cancelGracePeriod):
+			logger.Errorw("alert.scheduler jobs did not stop after canceled", "holder", s.holder)
+		}
+	}
+	s.cancel()
+
+	// given context may be done already so that the lease is released with a new one
+	releaseCtx, cancelRelease := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancelRelease()
+	if left, err := s.db.CountDueDeliveries(releaseCtx, s.now()); err != nil {
+		logger.Errorw("alert.scheduler failed to count deliveries left", "err", err)
+	} else if left > 0 {
+		logger.Warnw("alert.scheduler stopped with deliveries left in the outbox", "left", left)
+	}
+	return s.db.ReleaseLease(releaseCtx, schedulerLease, s.holder)
+}
+
+// NewScheduler creates a new scheduler with given config.
+// An error is returned if the batch size, the number of workers, the lease or the tick timeout
+// is not positive, or if the tick timeout is not less than the lease
+func NewScheduler(cfg *config.Config, db alertDB.AlertDB, tokenDB tokenDB.TokenDB, prices *uniswap.PriceCache,
+	outbox *OutboxWorker, mp *metric.MetricsProvider) (*Scheduler, error) {
+	c := cfg.AlertConfig.Scheduler
+	if c.BatchSize <= 0 {
+		return nil, fmt.Errorf("alert.scheduler.batchSize must be positive: %d", c.BatchSize)
+	}
+	if c.Workers <= 0 {
+		return nil, fmt.Errorf("alert.scheduler.workers must be positive: %d", c.Workers)
+	}
+	if c.LeaseSecs <= 0 {
+		return nil, fmt.Errorf("alert.scheduler.leaseSecs must be positive: %d", c.LeaseSecs)
+	}
+	if c.TickTimeoutSecs <= 0 {
+		return nil, fmt.Errorf("alert.scheduler.tickTimeoutSecs must be positive: %d", c.TickTimeoutSecs)
+	}
+	// a tick outliving the lease would run on two replicas at once
+	if c.TickTimeoutSecs >= c.LeaseSecs {
+		return nil, fmt.Errorf("alert.scheduler.tickTimeoutSecs must be less than leaseSecs: %d >= %d",
+			c.TickTimeoutSecs, c.LeaseSecs)
+	}
+	hostname, _ := os.Hostname()
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		db:          db,
+		tokenDB:     tokenDB,
+		prices:      prices,
+		outbox:      outbox,
+		evaluator:   NewEvaluator(tokenDB),
+		mp:          mp,
+		schedule:    c.Schedule,
+		batchSize:   uint(c.BatchSize),
+		workers:     c.Workers,
+		tickTimeout: time.Duration(c.TickTimeoutSecs) * time.Second,
+		lease:       time.Duration(c.LeaseSecs) * time.Second,
+		holder:      hostname + "-" + uuid.NewString(),
+		// a tick is skipped if the previous one is still running
+		cron:     cron.New(cron.WithSeconds(), cron.WithChain(cron.SkipIfStillRunning(cron.DefaultLogger))),
+		shutdown: time.Duration(c.ShutdownSecs) * time.Second,
+		ctx:      ctx,
+		cancel:   cancel,
+		now:      time.Now,
+	}, nil
+}
+
+// StartScheduler starts given scheduler with the application and stops it on shutdown
+func StartScheduler(lc fx.Lifecycle, s *Scheduler) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			logging.FromContext(ctx).Infow("Start alert scheduler", "holder", s.holder, "schedule", s.schedule)
+			return s.Start()
+		},
+		OnStop: func(ctx context.Context) error {
+			logging.FromContext(ctx).Infow("Stopped alert scheduler", "holder", s.holder)
+			return s.Stop(ctx)
+		},
+	})
+}
@@ -0,0 +1,247 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	alertDBMock "kek-backend/internal/alert/database/mocks"
+	"kek-backend/internal/alert/model"
+	"kek-backend/internal/config"
+	tokenDBMock "kek-backend/internal/token/database/mocks"
+	"kek-backend/internal/uniswap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestScheduler_Leading(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Acquired bool
+		Err      error
+		// expected
+		Leading bool
+	}{
+		{
+			Name:     "Lease acquired",
+			Acquired: true,
+			Leading:  true,
+		}, {
+			Name: "Lease held by another replica",
+		}, {
+			Name: "Failed to acquire lease",
+			Err:  errors.New("connection refused"),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			// given
+			now := time.Now()
+			db := &alertDBMock.AlertDB{}
+			s := newTestScheduler(t, db, now)
+			db.On("AcquireLease", mock.Anything, schedulerLease, s.holder, now, 30*time.Second).Return(tc.Acquired, tc.Err)
+
+			// when
+			leading := s.leading(context.Background())
+
+			// then
+			assert.Equal(t, tc.Leading, leading)
+		})
+	}
+}
+
+func TestScheduler_StopReleasesLease(t *testing.T) {
+	// given
+	db := &alertDBMock.AlertDB{}
+	s := newTestScheduler(t, db, time.Now())
+	db.On("CountDueDeliveries", mock.Anything, mock.Anything).Return(int64(0), nil)
+	db.On("ReleaseLease", mock.Anything, schedulerLease, s.holder).Return(nil)
+	assert.NoError(t, s.Start())
+
+	// when
+	err := s.Stop(context.Background())
+
+	// then
+	assert.NoError(t, err)
+	db.AssertCalled(t, "ReleaseLease", mock.Anything, schedulerLease, s.holder)
+}
+
+func TestScheduler_StopCancelsRunningJobsAtDeadline(t *testing.T) {
+	// given
+	db := &alertDBMock.AlertDB{}
+	s := newTestScheduler(t, db, time.Now())
+	s.shutdown = 10 * time.Millisecond
+	db.On("CountDueDeliveries", mock.Anything, mock.Anything).Return(int64(3), nil)
+	db.On("ReleaseLease", mock.Anything, schedulerLease, s.holder).Return(nil)
+	running := make(chan struct{})
+	canceled := make(chan struct{})
+	_, err := s.cron.AddFunc("@every 1s", func() {
+		close(running)
+		<-s.ctx.Done()
+		close(canceled)
+	})
+	assert.NoError(t, err)
+	s.cron.Start()
+	<-running
+
+	// when
+	err = s.Stop(context.Background())
+
+	// then
+	assert.NoError(t, err)
+	select {
+	case <-canceled:
+	default:
+		t.Fatal("running job is not canceled")
+	}
+	db.AssertCalled(t, "CountDueDeliveries", mock.Anything, mock.Anything)
+	db.AssertCalled(t, "ReleaseLease", mock.Anything, schedulerLease, s.holder)
+}
+
+func TestScheduler_StartFailIfInvalidSchedule(t *testing.T) {
+	// given
+	s := newTestScheduler(t, &alertDBMock.AlertDB{}, time.Now())
+	s.schedule = "every five seconds"
+
+	// when
+	err := s.Start()
+
+	// then
+	assert.Error(t, err)
+}
+
+func TestScheduler_EvaluatePagesAllAlerts(t *testing.T) {
+	// given
+	subgraph := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req uniswap.GraphQLRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if strings.Contains(req.Query, "bundles") {
+			_, _ = w.Write([]byte(`{"data":{"bundles":[{"ethPrice":"2000"}]}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"tokens":[{"id":"` + dAlert.PairAddress + `","symbol":"DAI","derivedETH":"0.001"}]}}`))
+	}))
+	defer subgraph.Close()
+	cfg, err := config.Load("")
+	assert.NoError(t, err)
+	cfg.UniswapConfig.Endpoint = subgraph.URL
+	cfg.UniswapConfig.Retries = 0
+
+	now := time.Now()
+	db := newTxAlertDB()
+	tokenDB := &tokenDBMock.TokenDB{}
+	tokenDB.On("SavePriceSnapshots", mock.Anything, mock.Anything).Return(nil)
+	prices := uniswap.NewPriceCache(cfg, uniswap.NewClient(cfg), testMetricsProvider)
+	s, err := NewScheduler(cfg, db, tokenDB, prices, NewOutboxWorker(cfg, db, NewDispatcher(nil, nil, nil, nil)), testMetricsProvider)
+	assert.NoError(t, err)
+	s.now = func() time.Time { return now }
+	s.batchSize = 2
+
+	var alerts []*model.Alert
+	for id := uint(1); id <= 3; id++ {
+		alert := newCronAlert(model.AlertStatusActive, model.RearmAuto)
+		alert.ID = id
+		alerts = append(alerts, alert)
+	}
+	db.On("ExpireAlerts", mock.Anything, now).Return(int64(0), nil)
+	db.On("ResumeSnoozedAlerts", mock.Anything, now).Return(int64(0), nil)
+	db.On("FindAlertsAfter", mock.Anything, evaluatedStatuses, uint(0), uint(2)).Return(alerts[:2], nil)
+	db.On("FindAlertsAfter", mock.Anything, evaluatedStatuses, uint(2), uint(2)).Return(alerts[2:], nil)
+	db.On("UpdateAlertObservation", mock.Anything, mock.Anything, mock.Anything, now).Return(nil)
+	db.On("TransitAlertStatus", mock.Anything, mock.Anything, model.AlertStatusActive, model.AlertStatusTriggered).Return(nil)
+	db.On("SaveAlertEvent", mock.Anything, mock.Anything).Return(nil)
+	db.On("SaveDeliveries", mock.Anything, mock.Anything).Return(nil)
+
+	// when
+	s.evaluate(context.Background())
+
+	// then
+	db.AssertNumberOfCalls(t, "FindAlertsAfter", 2)
+	for _, alert := range alerts {
+		db.AssertCalled(t, "TransitAlertStatus", mock.Anything, alert.ID, model.AlertStatusActive, model.AlertStatusTriggered)
+	}
+}
+
+func newTestScheduler(t *testing.T, db *alertDBMock.AlertDB, now time.Time) *Scheduler {
+	cfg, err := config.Load("")
+	assert.NoError(t, err)
+	s, err := NewScheduler(cfg, db, nil, nil, NewOutboxWorker(cfg, db, NewDispatcher(nil, nil, nil, nil)), testMetricsProvider)
+	assert.NoError(t, err)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestNewScheduler_FailIfInvalidConfig(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Configure func(cfg *config.Config)
+		Err       string
+	}{
+		{
+			Name:      "Zero batch size",
+			Configure: func(cfg *config.Config) { cfg.AlertConfig.Scheduler.BatchSize = 0 },
+			Err:       "alert.scheduler.batchSize must be positive: 0",
+		}, {
+			Name:      "Negative batch size",
+			Configure: func(cfg *config.Config) { cfg.AlertConfig.Scheduler.BatchSize = -1 },
+			Err:       "alert.scheduler.batchSize must be positive: -1",
+		}, {
+			Name:      "Zero workers",
+			Configure: func(cfg *config.Config) { cfg.AlertConfig.Scheduler.Workers = 0 },
+			Err:       "alert.scheduler.workers must be positive: 0",
+		}, {
+			Name:      "Negative workers",
+			Configure: func(cfg *config.Config) { cfg.AlertConfig.Scheduler.Workers = -2 },
+			Err:       "alert.scheduler.workers must be positive: -2",
+		}, {
+			Name:      "Zero lease",
+			Configure: func(cfg *config.Config) { cfg.AlertConfig.Scheduler.LeaseSecs = 0 },
+			Err:       "alert.scheduler.leaseSecs must be positive: 0",
+		}, {
+			Name:      "Negative lease",
+			Configure: func(cfg *config.Config) { cfg.AlertConfig.Scheduler.LeaseSecs = -1 },
+			Err:       "alert.scheduler.leaseSecs must be positive: -1",
+		}, {
+			Name:      "Zero tick timeout",
+			Configure: func(cfg *config.Config) { cfg.AlertConfig.Scheduler.TickTimeoutSecs = 0 },
+			Err:       "alert.scheduler.tickTimeoutSecs must be positive: 0",
+		}, {
+			Name:      "Negative tick timeout",
+			Configure: func(cfg *config.Config) { cfg.AlertConfig.Scheduler.TickTimeoutSecs = -5 },
+			Err:       "alert.scheduler.tickTimeoutSecs must be positive: -5",
+		}, {
+			Name: "Tick timeout equal to lease",
+			Configure: func(cfg *config.Config) {
+				cfg.AlertConfig.Scheduler.TickTimeoutSecs = 30
+				cfg.AlertConfig.Scheduler.LeaseSecs = 30
+			},
+			Err: "alert.scheduler.tickTimeoutSecs must be less than leaseSecs: 30 >= 30",
+		}, {
+			Name: "Tick timeout over lease",
+			Configure: func(cfg *config.Config) {
+				cfg.AlertConfig.Scheduler.TickTimeoutSecs = 40
+				cfg.AlertConfig.Scheduler.LeaseSecs = 30
+			},
+			Err: "alert.scheduler.tickTimeoutSecs must be less than leaseSecs: 40 >= 30",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			cfg, err := config.Load("")
+			assert.NoError(t, err)
+			tc.Configure(cfg)
+
+			s, err := NewScheduler(cfg, nil, nil, nil, nil, testMetricsProvider)
+
+			assert.Nil(t, s)
+			assert.EqualError(t, err, tc.Err)
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package alert
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// maxRenderedLength is the maximum length in bytes of a rendered title or body
+const maxRenderedLength = 4096
+
+// TemplateData is market data an alert's title and body are rendered with when the alert fires
+type TemplateData struct {
+	Symbol      string
+	Name        string
+	PairAddress string
+	PriceUSD    float64
+	Threshold   float64
+	// ChangePct is a percent change of the price from the baseline or the previous observation
+	ChangePct float64
+	FiredAt   time.Time
+}
+
+// TemplateError is returned if an alert's title or body is an invalid template
+type TemplateError struct {
+	Field   string
+	Value   string
+	Message string
+}
+
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("invalid template. field:%s, value:%s, message:%s", e.Field, e.Value, e.Message)
+}
+
+// number is a float placeholder printed without an exponent
+type number float64
+
+func (n number) String() string {
+	return strconv.FormatFloat(float64(n), 'f', -1, 64)
+}
+
+// templateFuncs returns placeholders bound to given data and formatting helpers.
+// These, printf and comparisons are the only functions allowed in templates.
+func templateFuncs(d *TemplateData) template.FuncMap {
+	return template.FuncMap{
+		"symbol":       func() string { return d.Symbol },
+		"name":         func() string { return d.Name },
+		"pair_address": func() string { return d.PairAddress },
+		"price_usd":    func() number { return number(d.PriceUSD) },
+		"threshold":    func() number { return number(d.Threshold) },
+		"change_pct":   func() number { return number(d.ChangePct) },
+		"fired_at":     func() string { return d.FiredAt.UTC().Format(time.RFC3339) },
+		"upper":        strings.ToUpper,
+		"lower":        strings.ToLower,
+		// round takes the number last so that it is used in pipelines like {{price_usd | round 2}}
+		"round": func(places int, n number) number {
+			p := math.Pow(10, float64(places))
+			return number(math.Round(float64(n)*p) / p)
+		},
+	}
+}
+
+// ValidateTemplates checks given title and body of an alert are valid templates
+func ValidateTemplates(title, body string) error {
+	if err := ValidateTemplate("title", title); err != nil {
+		return err
+	}
+	return ValidateTemplate("body", body)
+}
+
+// ValidateTemplate checks given text of given field is a valid template.
+// The template is rendered with sample data so that invalid arguments of functions are rejected.
+func ValidateTemplate(field, text string) error {
+	sample := TemplateData{
+		Symbol:      "DAI",
+		Name:        "Dai Stablecoin",
+		PairAddress: "0x6b175474e89094c44da98b954eedeac495271d0f",
+		PriceUSD:    1.0001,
+		Threshold:   1,
+		ChangePct:   0.01,
+		FiredAt:     time.Now(),
+	}
+	if _, err := RenderTemplate(text, &sample); err != nil {
+		return &TemplateError{Field: field, Value: text, Message: err.Error()}
+	}
+	return nil
+}
+
+// RenderTemplate renders given text with given data.
+// Only placeholders, formatting helpers, printf, comparisons and if actions are allowed.
+func RenderTemplate(text string, d *TemplateData) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	t, err := template.New("alert").Funcs(templateFuncs(d)).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	if len(t.Templates()) > 1 {
+		return "", errors.New("define and block are not allowed")
+	}
+	if err := checkTemplateNode(t.Root); err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&limitedWriter{w: &buf, n: maxRenderedLength}, nil); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// allowedTemplateIdents are names of functions allowed in templates in addition to templateFuncs
+var allowedTemplateIdents = map[string]bool{
+	"printf": true,
+	"eq":     true,
+	"ne":     true,
+	"lt":     true,
+	"le":     true,
+	"gt":     true,
+	"ge":     true,
+	"and":    true,
+	"or":     true,
+	"not":    true,
+}
+
+// checkTemplateNode returns an error if given node uses an action or a function not allowed in templates
+func checkTemplateNode(node parse.Node) error {
+	switch n := node.(type) {
+	case nil, *parse.TextNode, *parse.NumberNode, *parse.StringNode, *parse.BoolNode, *parse.NilNode:
+		return nil
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkTemplateNode(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *parse.ActionNode:
+		return checkTemplateNode(n.Pipe)
+	case *parse.IfNode:
+		if err := checkTemplateNode(n.Pipe); err != nil {
+			return err
+		}
+		if err := checkTemplateNode(n.List); err != nil {
+			return err
+		}
+		return checkTemplateNode(n.ElseList)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		if len(n.Decl) != 0 {
+			return errors.New("variables are not allowed")
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				if err := checkTemplateNode(arg); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	case *parse.IdentifierNode:
+		if _, ok := templateFuncs(&TemplateData{})[n.Ident]; ok || allowedTemplateIdents[n.Ident] {
+			return nil
+		}
+		return fmt.Errorf("function %q is not allowed", n.Ident)
+	}
+	return fmt.Errorf("%q is not allowed", node.String())
+}
+
+// limitedWriter fails writes beyond n bytes
+type limitedWriter struct {
+	w *bytes.Buffer
+	n int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if l.w.Len()+len(p) > l.n {
+		return 0, fmt.Errorf("rendered text is longer than %d bytes", l.n)
+	}
+	return l.w.Write(p)
+}
+
+// changePct returns the percent change of given observation's price from its baseline,
+// or from its previous price if it has no baseline
+func changePct(o Observation) float64 {
+	var base float64
+	switch {
+	case o.HasBaseline:
+		base = o.Baseline
+	case o.HasPrevious:
+		base = o.Previous
+	}
+	if base == 0 {
+		return 0
+	}
+	return (o.Price - base) / base * 100
+}
@@ -0,0 +1,86 @@
+package alert
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	data := TemplateData{
+		Symbol:      "DAI",
+		Name:        "Dai Stablecoin",
+		PairAddress: "0x6b175474e89094c44da98b954eedeac495271d0f",
+		PriceUSD:    0.00001234,
+		Threshold:   1.05,
+		ChangePct:   -12.3456,
+		FiredAt:     time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+	cases := []struct {
+		Name     string
+		Text     string
+		Expected string
+	}{
+		{
+			Name:     "Verbatim",
+			Text:     "DAI is above 1.05",
+			Expected: "DAI is above 1.05",
+		}, {
+			Name:     "Placeholders",
+			Text:     "{{symbol}} is now {{price_usd}} ({{change_pct | round 2}}%)",
+			Expected: "DAI is now 0.00001234 (-12.35%)",
+		}, {
+			Name:     "Helpers",
+			Text:     "{{upper name}} crossed {{printf \"%.1f\" threshold}} at {{fired_at}}",
+			Expected: "DAI STABLECOIN crossed 1.1 at 2021-05-01T12:00:00Z",
+		}, {
+			Name:     "If",
+			Text:     "{{symbol}} {{if lt change_pct 0.0}}fell{{else}}rose{{end}}",
+			Expected: "DAI fell",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			rendered, err := RenderTemplate(tc.Text, &data)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Expected, rendered)
+		})
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	cases := []struct {
+		Name  string
+		Text  string
+		Valid bool
+	}{
+		{Name: "Verbatim", Text: "You have to believe", Valid: true},
+		{Name: "Placeholders", Text: "{{symbol}} is now {{price_usd}}", Valid: true},
+		{Name: "Unclosed action", Text: "{{symbol is now"},
+		{Name: "Unknown placeholder", Text: "{{volume}}"},
+		{Name: "Field", Text: "{{.Symbol}}"},
+		{Name: "Variable", Text: "{{$p := price_usd}}{{$p}}"},
+		{Name: "Range", Text: "{{range 1000000000}}{{end}}"},
+		{Name: "Builtin call", Text: "{{call symbol}}"},
+		{Name: "Define", Text: `{{define "t"}}x{{end}}`},
+		{Name: "Invalid argument", Text: "{{round symbol price_usd}}"},
+		{Name: "Too long", Text: strings.Repeat("{{pair_address}}", 200)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := ValidateTemplate("body", tc.Text)
+
+			if tc.Valid {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Equal(t, "body", err.(*TemplateError).Field)
+		})
+	}
+}
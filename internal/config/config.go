@@ -14,10 +14,16 @@ import (
 )
 
 type Config struct {
-	ServerConfig  ServerConfig  `json:"server"`
-	JwtConfig     JWTConfig     `json:"jwt"`
-	DBConfig      DBConfig      `json:"db"`
-	MetricsConfig MetricsConfig `json:"metrics"`
+	ServerConfig       ServerConfig       `json:"server"`
+	WorkerConfig       WorkerConfig       `json:"worker"`
+	JwtConfig          JWTConfig          `json:"jwt"`
+	DBConfig           DBConfig           `json:"db"`
+	MetricsConfig      MetricsConfig      `json:"metrics"`
+	NotificationConfig NotificationConfig `json:"notification"`
+	AdminConfig        AdminConfig        `json:"admin"`
+	AlertConfig        AlertConfig        `json:"alert"`
+	UniswapConfig      UniswapConfig      `json:"uniswap"`
+	TokenConfig        TokenConfig        `json:"token"`
 }
 
 type ServerConfig struct {
@@ -27,6 +33,12 @@ type ServerConfig struct {
 	WriteTimeoutSecs int `json:"writeTimeoutSecs"`
 }
 
+// WorkerConfig is a config of the worker running background jobs
+type WorkerConfig struct {
+	// Port serves health and metrics endpoints of the worker
+	Port int `json:"port"`
+}
+
 type JWTConfig struct {
 	Secret      string `json:"secret"`
 	SessionTime int    `json:"sessionTime"`
@@ -50,6 +62,114 @@ type MetricsConfig struct {
 	Subsystem string `json:"subsystem"`
 }
 
+type NotificationConfig struct {
+	FCM     FCMConfig   `json:"fcm"`
+	Email   EmailConfig `json:"email"`
+	Webhook struct {
+		TimeoutSecs int `json:"timeoutSecs"`
+	} `json:"webhook"`
+	Bot struct {
+		TimeoutSecs int `json:"timeoutSecs"`
+	} `json:"bot"`
+}
+
+type AdminConfig struct {
+	// Emails is a list of account emails allowed to use admin apis
+	Emails []string `json:"emails"`
+}
+
+type AlertConfig struct {
+	Outbox struct {
+		IntervalSecs   int `json:"intervalSecs"`
+		BatchSize      int `json:"batchSize"`
+		MaxAttempts    int `json:"maxAttempts"`
+		BackoffSecs    int `json:"backoffSecs"`
+		MaxBackoffSecs int `json:"maxBackoffSecs"`
+	} `json:"outbox"`
+	// Scheduler runs on the replica holding a lease of LeaseSecs renewed on every job
+	Scheduler struct {
+		Schedule  string `json:"schedule"`
+		BatchSize int    `json:"batchSize"`
+		LeaseSecs int    `json:"leaseSecs"`
+		// Workers is the number of goroutines evaluating a page of BatchSize alerts
+		Workers int `json:"workers"`
+		// TickTimeoutSecs is a deadline of evaluating all alerts in a tick. It must be less than LeaseSecs
+		TickTimeoutSecs int `json:"tickTimeoutSecs"`
+		// ShutdownSecs is how long running jobs are waited for on shutdown
+		ShutdownSecs int `json:"shutdownSecs"`
+	} `json:"scheduler"`
+	// TestFire limits test fires of alerts to Limit per account in WindowSecs
+	TestFire struct {
+		Limit      int `json:"limit"`
+		WindowSecs int `json:"windowSecs"`
+	} `json:"testFire"`
+}
+
+type UniswapConfig struct {
+	Endpoint         string `json:"endpoint"`
+	TimeoutSecs      int    `json:"timeoutSecs"`
+	Retries          int    `json:"retries"`
+	RetryDelayMillis int    `json:"retryDelayMillis"`
+	UserAgent        string `json:"userAgent"`
+	BatchSize        int    `json:"batchSize"`
+	Cache            struct {
+		TTLSecs   int `json:"ttlSecs"`
+		StaleSecs int `json:"staleSecs"`
+	} `json:"cache"`
+}
+
+type TokenConfig struct {
+	History struct {
+		RollupIntervalSecs int `json:"rollupIntervalSecs"`
+		// RetentionHours is how long price snapshots are kept after rolled up to candles
+		RetentionHours int `json:"retentionHours"`
+		// CandleRetentionHours is how long candles are kept by interval. Candles are kept forever if 0
+		CandleRetentionHours map[string]int `json:"candleRetentionHours"`
+	} `json:"history"`
+	// Sync saves Limit most traded tokens of the subgraph every IntervalSecs
+	Sync struct {
+		IntervalSecs int `json:"intervalSecs"`
+		Limit        int `json:"limit"`
+	} `json:"sync"`
+}
+
+type FCMConfig struct {
+	ServerKey   string `json:"serverKey"`
+	Endpoint    string `json:"endpoint"`
+	TimeoutSecs int    `json:"timeoutSecs"`
+}
+
+func (c *FCMConfig) MarshalJSON() ([]byte, error) {
+	m := map[string]interface{}{
+		"serverKey":   "[PROTECTED]",
+		"endpoint":    c.Endpoint,
+		"timeoutSecs": c.TimeoutSecs,
+	}
+	return json.Marshal(m)
+}
+
+type EmailConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	From     string `json:"from"`
+	// TimeoutSecs bounds the whole SMTP session of sending an email
+	TimeoutSecs int `json:"timeoutSecs"`
+}
+
+func (c *EmailConfig) MarshalJSON() ([]byte, error) {
+	m := map[string]interface{}{
+		"host":        c.Host,
+		"port":        c.Port,
+		"username":    c.Username,
+		"password":    "[PROTECTED]",
+		"from":        c.From,
+		"timeoutSecs": c.TimeoutSecs,
+	}
+	return json.Marshal(m)
+}
+
 func (c *DBConfig) MarshalJSON() ([]byte, error) {
 	m := map[string]interface{}{
 		"dataSourceName": "[PROTECTED]", // TODO : masking
@@ -6,6 +6,8 @@ var defaultConfig = map[string]interface{}{
 	"server.readTimeoutSecs":  20,
 	"server.writeTimeoutSecs": 40,
 
+	"worker.port": 9091,
+
 	"jwt.secret":      "secret-key",
 	"jwt.sessionTime": 864000,
 
@@ -18,4 +20,49 @@ var defaultConfig = map[string]interface{}{
 
 	"metrics.namespace": "kek_server",
 	"metrics.subsystem": "",
+
+	"notification.fcm.serverKey":       "",
+	"notification.fcm.endpoint":        "",
+	"notification.fcm.timeoutSecs":     10,
+	"notification.email.host":          "",
+	"notification.email.port":          25,
+	"notification.email.username":      "",
+	"notification.email.password":      "",
+	"notification.email.from":          "noreply@kek.local",
+	"notification.email.timeoutSecs":   10,
+	"notification.webhook.timeoutSecs": 10,
+	"notification.bot.timeoutSecs":     10,
+
+	"admin.emails": []string{},
+
+	"alert.outbox.intervalSecs":       5,
+	"alert.outbox.batchSize":          50,
+	"alert.outbox.maxAttempts":        8,
+	"alert.outbox.backoffSecs":        10,
+	"alert.outbox.maxBackoffSecs":     3600,
+	"alert.scheduler.schedule":        "@every 5s",
+	"alert.scheduler.batchSize":       100,
+	"alert.scheduler.leaseSecs":       30,
+	"alert.scheduler.workers":         8,
+	"alert.scheduler.tickTimeoutSecs": 20,
+	"alert.scheduler.shutdownSecs":    10,
+	"alert.testFire.limit":            5,
+	"alert.testFire.windowSecs":       3600,
+
+	"uniswap.endpoint":         "https://api.thegraph.com/subgraphs/name/uniswap/uniswap-v2",
+	"uniswap.timeoutSecs":      10,
+	"uniswap.retries":          2,
+	"uniswap.retryDelayMillis": 500,
+	"uniswap.userAgent":        "kek-backend",
+	"uniswap.batchSize":        100,
+	"uniswap.cache.ttlSecs":    4,
+	"uniswap.cache.staleSecs":  20,
+
+	"token.history.rollupIntervalSecs":      60,
+	"token.history.retentionHours":          48,
+	"token.history.candleRetentionHours.1m": 168,
+	"token.history.candleRetentionHours.1h": 2160,
+	"token.history.candleRetentionHours.1d": 0,
+	"token.sync.intervalSecs":               3600,
+	"token.sync.limit":                      1000,
 }
@@ -13,7 +13,9 @@ type MetricsProvider struct {
 	Namespace string
 	Subsystem string
 
-	apiMetricsProvider apiMetricsProvider
+	apiMetricsProvider   apiMetricsProvider
+	cacheMetricsProvider cacheMetricsProvider
+	alertMetricsProvider alertMetricsProvider
 }
 
 type apiMetricsProvider struct {
@@ -21,6 +23,43 @@ type apiMetricsProvider struct {
 	requestLatency *prometheus.SummaryVec
 }
 
+type cacheMetricsProvider struct {
+	hitCounter  *prometheus.CounterVec
+	missCounter *prometheus.CounterVec
+}
+
+type alertMetricsProvider struct {
+	tickLatency      *prometheus.SummaryVec
+	evaluatedCounter prometheus.Counter
+	firedCounter     prometheus.Counter
+}
+
+// RecordAlertTick observes given elapsed mills of an alert evaluation tick with whether it timed out
+func (mp *MetricsProvider) RecordAlertTick(elapsed time.Duration, timeout bool) {
+	mills := float64(elapsed.Milliseconds())
+	mp.alertMetricsProvider.tickLatency.WithLabelValues(strconv.FormatBool(timeout)).Observe(mills)
+}
+
+// RecordAlertsEvaluated increases count of evaluated alerts with given count
+func (mp *MetricsProvider) RecordAlertsEvaluated(count int) {
+	mp.alertMetricsProvider.evaluatedCounter.Add(float64(count))
+}
+
+// RecordAlertsFired increases count of fired alerts with given count
+func (mp *MetricsProvider) RecordAlertsFired(count int) {
+	mp.alertMetricsProvider.firedCounter.Add(float64(count))
+}
+
+// RecordCacheHit increases count of cache hit with given cache name and whether the value was stale
+func (mp *MetricsProvider) RecordCacheHit(cache string, stale bool) {
+	mp.cacheMetricsProvider.hitCounter.WithLabelValues(cache, strconv.FormatBool(stale)).Inc()
+}
+
+// RecordCacheMiss increases count of cache miss with given cache name
+func (mp *MetricsProvider) RecordCacheMiss(cache string) {
+	mp.cacheMetricsProvider.missCounter.WithLabelValues(cache).Inc()
+}
+
 // RecordApiCount increases count of api request with given code, method, path labels
 func (mp *MetricsProvider) RecordApiCount(code int, method, path string) {
 	mp.apiMetricsProvider.requestCounter.WithLabelValues(strconv.Itoa(code), method, path).Inc()
@@ -61,6 +100,53 @@ func NewMetricsProvider(cfg *config.Config) *MetricsProvider {
 				[]string{"code", "method", "path"},
 			),
 		},
+		cacheMetricsProvider: cacheMetricsProvider{
+			hitCounter: promauto.NewCounterVec(
+				prometheus.CounterOpts{
+					Namespace: ns,
+					Subsystem: ss,
+					Name:      "cache_hit_count",
+					Help:      "Total count of cache hit",
+				},
+				[]string{"cache", "stale"},
+			),
+			missCounter: promauto.NewCounterVec(
+				prometheus.CounterOpts{
+					Namespace: ns,
+					Subsystem: ss,
+					Name:      "cache_miss_count",
+					Help:      "Total count of cache miss",
+				},
+				[]string{"cache"},
+			),
+		},
+		alertMetricsProvider: alertMetricsProvider{
+			tickLatency: promauto.NewSummaryVec(
+				prometheus.SummaryOpts{
+					Namespace: ns,
+					Subsystem: ss,
+					Name:      "alert_tick_latency",
+					Help:      "Elapsed time of alert evaluation tick",
+				},
+				[]string{"timeout"},
+			),
+			evaluatedCounter: promauto.NewCounter(
+				prometheus.CounterOpts{
+					Namespace: ns,
+					Subsystem: ss,
+					Name:      "alert_evaluated_count",
+					Help:      "Total count of evaluated alerts",
+				},
+			),
+			firedCounter: promauto.NewCounter(
+				prometheus.CounterOpts{
+					Namespace: ns,
+					Subsystem: ss,
+					Name:      "alert_fired_count",
+					Help:      "Total count of fired alerts",
+				},
+			),
+		},
 	}
 	return &mp
 }
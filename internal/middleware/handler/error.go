@@ -13,11 +13,19 @@ const (
 	InvalidUriValue   = ErrorCode("InvalidUriValue")
 	InvalidBodyValue  = ErrorCode("InvalidBodyValue")
 
+	// 403 forbidden
+	Forbidden = ErrorCode("Forbidden")
+
 	// 404 not found
 	NotFoundEntity = ErrorCode("NotFoundEntity")
 
 	// 409 duplicate
 	DuplicateEntry = ErrorCode("DuplicateEntry")
+	// 409 conflict with the current state
+	InvalidState = ErrorCode("InvalidState")
+
+	// 429 too many requests
+	TooManyRequests = ErrorCode("TooManyRequests")
 
 	// 500
 	InternalServerError = ErrorCode("InternalServerError")
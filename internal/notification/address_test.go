@@ -0,0 +1,65 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPublicHost(t *testing.T) {
+	cases := []struct {
+		Host   string
+		Public bool
+	}{
+		{Host: "example.com", Public: true},
+		{Host: "93.184.216.34", Public: true},
+		{Host: "2606:2800:220:1:248:1893:25c8:1946", Public: true},
+		{Host: "localhost"},
+		{Host: "db.internal"},
+		{Host: "127.0.0.1"},
+		{Host: "10.1.2.3"},
+		{Host: "172.16.0.1"},
+		{Host: "192.168.0.1"},
+		{Host: "169.254.169.254"},
+		{Host: "100.64.0.1"},
+		{Host: "0.0.0.0"},
+		{Host: "::1"},
+		{Host: "fe80::1"},
+		{Host: "fd00::1"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Host, func(t *testing.T) {
+			assert.Equal(t, tc.Public, IsPublicHost(tc.Host))
+		})
+	}
+}
+
+func TestNewPublicClient_FailIfPrivateAddress(t *testing.T) {
+	// given
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+	sender := NewBotSenderWithClient(NewPublicClient(time.Second))
+
+	// when
+	err := sender.Send(context.Background(), server.URL, "1", "hello", false)
+
+	// then
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrPrivateAddress))
+	assert.False(t, called)
+}
+
+func TestPublicControl(t *testing.T) {
+	assert.NoError(t, publicControl("tcp", net.JoinHostPort("93.184.216.34", "443"), nil))
+	assert.Equal(t, ErrPrivateAddress, publicControl("tcp", net.JoinHostPort("169.254.169.254", "80"), nil))
+}
@@ -0,0 +1,56 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"kek-backend/internal/config"
+	"kek-backend/pkg/logging"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// BotSender posts text messages to chat-bot http endpoints.
+// The request body follows the Telegram sendMessage api, e.g. {"chat_id": "1", "text": "hello", "is_test": false}.
+// is_test is true for messages of test fires and ignored by Telegram
+type BotSender struct {
+	client *http.Client
+}
+
+// Send posts given text to a chat with given id through given bot endpoint.
+// isTest marks the message as a test fire
+func (s *BotSender) Send(ctx context.Context, url, chatID, text string, isTest bool) error {
+	logger := logging.FromContext(ctx)
+	body, err := json.Marshal(map[string]interface{}{
+		"chat_id": chatID,
+		"text":    text,
+		"is_test": isTest,
+	})
+	if err != nil {
+		return errors.Wrap(err, "marshal bot message")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "create bot request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if err := doRequest(s.client, req); err != nil {
+		logger.Errorw("notification.bot failed to send", "chatId", chatID, "err", err)
+		return err
+	}
+	return nil
+}
+
+// NewBotSender creates a new chat-bot sender with given config which only posts to public addresses
+func NewBotSender(cfg *config.Config) *BotSender {
+	return NewBotSenderWithClient(NewPublicClient(time.Duration(cfg.NotificationConfig.Bot.TimeoutSecs) * time.Second))
+}
+
+// NewBotSenderWithClient creates a new chat-bot sender with given http client
+func NewBotSenderWithClient(client *http.Client) *BotSender {
+	return &BotSender{client: client}
+}
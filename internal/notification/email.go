@@ -0,0 +1,120 @@
+package notification
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"kek-backend/internal/config"
+	"kek-backend/pkg/logging"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// EmailSender sends plain text emails through a SMTP server
+type EmailSender struct {
+	cfg     config.EmailConfig
+	timeout time.Duration
+}
+
+// Send sends an email with given subject and body to given address
+func (s *EmailSender) Send(ctx context.Context, to, subject, body string) error {
+	logger := logging.FromContext(ctx)
+	if s.cfg.Host == "" {
+		return ErrNotConfigured
+	}
+	if strings.ContainsAny(to, "\r\n") || strings.ContainsAny(subject, "\r\n") {
+		return errors.New("invalid email header value")
+	}
+
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		s.cfg.From, to, subject, body)
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	if err := s.sendMail(ctx, addr, auth, to, []byte(msg)); err != nil {
+		logger.Errorw("notification.email failed to send", "to", to, "err", err)
+		return errors.Wrap(err, "send email")
+	}
+	return nil
+}
+
+// sendMail sends given message like smtp.SendMail but gives up when given context is done
+// or the configured timeout is elapsed. No timeout is applied if it is not configured
+func (s *EmailSender) sendMail(ctx context.Context, addr string, auth smtp.Auth, to string, msg []byte) error {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+	// unblock reads and writes in progress if the context is canceled before the deadline
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.SetDeadline(time.Now())
+		case <-done:
+		}
+	}()
+
+	c, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: s.cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(s.cfg.From); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// NewEmailSender creates a new email sender with given config
+func NewEmailSender(cfg *config.Config) *EmailSender {
+	return &EmailSender{
+		cfg:     cfg.NotificationConfig.Email,
+		timeout: time.Duration(cfg.NotificationConfig.Email.TimeoutSecs) * time.Second,
+	}
+}
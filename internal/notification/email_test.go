@@ -0,0 +1,180 @@
+package notification
+
+import (
+	"bufio"
+	"context"
+	"kek-backend/internal/config"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// smtpSink is a minimal SMTP server which accepts a single mail and records it
+type smtpSink struct {
+	listener net.Listener
+	rcpt     []string
+	data     chan string
+}
+
+func newSMTPSink(t *testing.T) *smtpSink {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	s := &smtpSink{listener: l, data: make(chan string, 1)}
+	go s.serve()
+	return s
+}
+
+func (s *smtpSink) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) {
+		_, _ = conn.Write([]byte(line + "\r\n"))
+	}
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			s.rcpt = append(s.rcpt, strings.Trim(strings.TrimSpace(line)[len("RCPT TO:"):], "<>"))
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "DATA"):
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var sb strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				sb.WriteString(l)
+			}
+			s.data <- sb.String()
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "QUIT"):
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func (s *smtpSink) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestEmailSender_Send(t *testing.T) {
+	// given
+	sink := newSMTPSink(t)
+	defer sink.listener.Close()
+	cfg := config.Config{}
+	cfg.NotificationConfig.Email = config.EmailConfig{
+		Host: "127.0.0.1",
+		Port: sink.port(),
+		From: "noreply@kek.local",
+	}
+	sender := NewEmailSender(&cfg)
+
+	// when
+	err := sender.Send(context.Background(), "user1@gmail.com", "price alert", "price is above 1.05")
+
+	// then
+	assert.NoError(t, err)
+	data := <-sink.data
+	assert.Equal(t, []string{"user1@gmail.com"}, sink.rcpt)
+	assert.Contains(t, data, "Subject: price alert")
+	assert.Contains(t, data, "price is above 1.05")
+}
+
+func TestEmailSender_FailIfNotConfigured(t *testing.T) {
+	sender := NewEmailSender(&config.Config{})
+
+	err := sender.Send(context.Background(), "user1@gmail.com", "subject", "body")
+
+	assert.Equal(t, ErrNotConfigured, err)
+}
+
+func TestEmailSender_FailIfHeaderInjection(t *testing.T) {
+	cfg := config.Config{}
+	cfg.NotificationConfig.Email = config.EmailConfig{Host: "127.0.0.1", Port: 25}
+	sender := NewEmailSender(&cfg)
+
+	err := sender.Send(context.Background(), "user1@gmail.com\r\nBcc: user2@gmail.com", "subject", "body")
+
+	assert.EqualError(t, err, "invalid email header value")
+}
+
+// newSilentSMTPServer returns a listener which accepts connections but never greets
+func newSilentSMTPServer(t *testing.T) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+	return l
+}
+
+func TestEmailSender_FailIfContextDone(t *testing.T) {
+	// given
+	l := newSilentSMTPServer(t)
+	defer l.Close()
+	cfg := config.Config{}
+	cfg.NotificationConfig.Email = config.EmailConfig{
+		Host:        "127.0.0.1",
+		Port:        l.Addr().(*net.TCPAddr).Port,
+		TimeoutSecs: 10,
+	}
+	sender := NewEmailSender(&cfg)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// when
+	start := time.Now()
+	err := sender.Send(ctx, "user1@gmail.com", "subject", "body")
+
+	// then
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestEmailSender_FailIfTimeout(t *testing.T) {
+	// given
+	l := newSilentSMTPServer(t)
+	defer l.Close()
+	cfg := config.Config{}
+	cfg.NotificationConfig.Email = config.EmailConfig{
+		Host:        "127.0.0.1",
+		Port:        l.Addr().(*net.TCPAddr).Port,
+		TimeoutSecs: 1,
+	}
+	sender := NewEmailSender(&cfg)
+
+	// when
+	start := time.Now()
+	err := sender.Send(context.Background(), "user1@gmail.com", "subject", "body")
+
+	// then
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
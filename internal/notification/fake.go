@@ -0,0 +1,33 @@
+package notification
+
+import (
+	"context"
+	"sync"
+)
+
+// FakeNotifier is a Notifier which records messages instead of sending them.
+// Notify returns Err for every message if it is set.
+type FakeNotifier struct {
+	mu       sync.Mutex
+	messages []*Message
+	Err      error
+}
+
+func (n *FakeNotifier) Notify(_ context.Context, msg *Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.messages = append(n.messages, msg)
+	return n.Err
+}
+
+// Messages returns messages passed to Notify in order
+func (n *FakeNotifier) Messages() []*Message {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]*Message{}, n.messages...)
+}
+
+// NewFakeNotifier creates a new fake notifier without any messages
+func NewFakeNotifier() *FakeNotifier {
+	return &FakeNotifier{}
+}
@@ -0,0 +1,74 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"kek-backend/internal/config"
+	"kek-backend/pkg/logging"
+	"time"
+
+	"github.com/appleboy/go-fcm"
+	"github.com/pkg/errors"
+)
+
+type fcmNotifier struct {
+	client  *fcm.Client
+	timeout time.Duration
+}
+
+func (n *fcmNotifier) Notify(ctx context.Context, msg *Message) error {
+	logger := logging.FromContext(ctx)
+	if msg.Token == "" {
+		return ErrEmptyToken
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+
+	data := make(map[string]interface{}, len(msg.Data))
+	for k, v := range msg.Data {
+		data[k] = v
+	}
+	res, err := n.client.SendWithContext(ctx, &fcm.Message{
+		To:   msg.Token,
+		Data: data,
+		Notification: &fcm.Notification{
+			Title: msg.Title,
+			Body:  msg.Body,
+		},
+	})
+	if err != nil {
+		logger.Errorw("notification.fcm failed to send message", "err", err)
+		return errors.Wrap(err, "send fcm message")
+	}
+	if res.Failure > 0 {
+		for _, r := range res.Results {
+			if r.Error != nil {
+				return errors.Wrap(r.Error, "deliver fcm message")
+			}
+		}
+		return fmt.Errorf("deliver fcm message. failure: %d", res.Failure)
+	}
+	logger.Debugw("notification.fcm sent message", "multicastId", res.MulticastID)
+	return nil
+}
+
+// NewFCMNotifier creates a new notifier which sends messages through FCM with given config
+func NewFCMNotifier(cfg config.FCMConfig) (Notifier, error) {
+	var opts []fcm.Option
+	if cfg.Endpoint != "" {
+		opts = append(opts, fcm.WithEndpoint(cfg.Endpoint))
+	}
+	client, err := fcm.NewClient(cfg.ServerKey, opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "create fcm client")
+	}
+	timeout := time.Duration(cfg.TimeoutSecs) * time.Second
+	if timeout <= 0 {
+		timeout = fcm.DefaultTimeout
+	}
+	return &fcmNotifier{
+		client:  client,
+		timeout: timeout,
+	}, nil
+}
@@ -0,0 +1,97 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"kek-backend/internal/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFCMNotifier_Notify(t *testing.T) {
+	// given
+	var (
+		authorization string
+		body          map[string]interface{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"multicast_id":1,"success":1,"failure":0,"results":[{"message_id":"m1"}]}`))
+	}))
+	defer server.Close()
+
+	notifier, err := NewFCMNotifier(config.FCMConfig{ServerKey: "server-key", Endpoint: server.URL, TimeoutSecs: 1})
+	assert.NoError(t, err)
+
+	// when
+	err = notifier.Notify(context.Background(), &Message{
+		Token: "device-token",
+		Title: "title",
+		Body:  "body",
+		Data:  map[string]string{"slug": "alert1"},
+	})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "key=server-key", authorization)
+	assert.Equal(t, "device-token", body["to"])
+	assert.Equal(t, "title", body["notification"].(map[string]interface{})["title"])
+	assert.Equal(t, "alert1", body["data"].(map[string]interface{})["slug"])
+}
+
+func TestFCMNotifier_NotifyFailure(t *testing.T) {
+	cases := []struct {
+		Name    string
+		Token   string
+		Status  int
+		Payload string
+	}{
+		{
+			Name:  "Empty token",
+			Token: "",
+		}, {
+			Name:    "Server error",
+			Token:   "device-token",
+			Status:  http.StatusInternalServerError,
+			Payload: `{}`,
+		}, {
+			Name:    "Delivery failure",
+			Token:   "device-token",
+			Status:  http.StatusOK,
+			Payload: `{"multicast_id":1,"success":0,"failure":1,"results":[{"error":"NotRegistered"}]}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tc.Status)
+				_, _ = w.Write([]byte(tc.Payload))
+			}))
+			defer server.Close()
+			notifier, err := NewFCMNotifier(config.FCMConfig{ServerKey: "server-key", Endpoint: server.URL, TimeoutSecs: 1})
+			assert.NoError(t, err)
+
+			err = notifier.Notify(context.Background(), &Message{Token: tc.Token, Title: "title", Body: "body"})
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewNotifier_WithoutServerKey(t *testing.T) {
+	cfg, err := config.Load("")
+	assert.NoError(t, err)
+	cfg.NotificationConfig.FCM.ServerKey = ""
+
+	notifier, err := NewNotifier(cfg)
+
+	assert.NoError(t, err)
+	assert.Equal(t, ErrNotConfigured, notifier.Notify(context.Background(), &Message{Token: "device-token"}))
+}
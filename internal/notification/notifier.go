@@ -0,0 +1,45 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"kek-backend/internal/config"
+	"kek-backend/pkg/logging"
+)
+
+var (
+	// ErrNotConfigured is returned if a notifier is used without required configs
+	ErrNotConfigured = errors.New("notifier is not configured")
+	// ErrEmptyToken is returned if a message has no device token
+	ErrEmptyToken = errors.New("empty device token")
+)
+
+// Message is a push notification delivered to a device
+type Message struct {
+	Token string
+	Title string
+	Body  string
+	Data  map[string]string
+}
+
+// Notifier delivers push notifications to devices
+type Notifier interface {
+	// Notify sends a given message and returns an error if failed to deliver
+	Notify(ctx context.Context, msg *Message) error
+}
+
+type disabledNotifier struct{}
+
+func (n *disabledNotifier) Notify(_ context.Context, _ *Message) error {
+	return ErrNotConfigured
+}
+
+// NewNotifier creates a new FCM notifier with given config.
+// A notifier which always returns ErrNotConfigured is returned if FCM server key is empty.
+func NewNotifier(cfg *config.Config) (Notifier, error) {
+	if cfg.NotificationConfig.FCM.ServerKey == "" {
+		logging.DefaultLogger().Warn("notification.fcm.serverKey is empty. push notifications are disabled")
+		return &disabledNotifier{}, nil
+	}
+	return NewFCMNotifier(cfg.NotificationConfig.FCM)
+}
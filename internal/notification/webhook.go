@@ -0,0 +1,97 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"kek-backend/internal/config"
+	"kek-backend/pkg/logging"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Webhook requests are signed if the action has a secret. The signature is
+//
+//	"sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body))
+//
+// where timestamp is the value of TimestampHeader. Receivers should verify the signature
+// and reject requests whose timestamp is too old so that a captured request is not replayed.
+const (
+	// SignatureHeader is a header of webhook requests holding the signature of the timestamp and the body
+	SignatureHeader = "X-Kek-Signature"
+	// TimestampHeader is a header of webhook requests holding the unix time when the request was sent
+	TimestampHeader = "X-Kek-Timestamp"
+)
+
+// WebhookSender posts json payloads to webhook urls
+type WebhookSender struct {
+	client *http.Client
+}
+
+// Send posts given payload as json to given url.
+// The timestamp and the body are signed with given secret and the signature is set to SignatureHeader
+// if secret is not empty.
+func (s *WebhookSender) Send(ctx context.Context, url, secret string, payload interface{}) error {
+	logger := logging.FromContext(ctx)
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "marshal webhook payload")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "create webhook request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	timestamp := fmt.Sprintf("%d", time.Now().Unix())
+	req.Header.Set(TimestampHeader, timestamp)
+	if secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(secret, timestamp, body))
+	}
+
+	if err := doRequest(s.client, req); err != nil {
+		logger.Errorw("notification.webhook failed to send", "url", url, "err", err)
+		return err
+	}
+	return nil
+}
+
+// Sign returns a hex encoded HMAC-SHA256 of given timestamp and body joined with "." with given secret
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// doRequest executes given request and returns an error if the response status is not 2xx
+func doRequest(client *http.Client, req *http.Request) error {
+	res, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "send request")
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		b, _ := ioutil.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("unexpected status code: %d, body: %s", res.StatusCode, string(b))
+	}
+	return nil
+}
+
+// NewWebhookSender creates a new webhook sender with given config which only posts to public addresses
+func NewWebhookSender(cfg *config.Config) *WebhookSender {
+	return NewWebhookSenderWithClient(NewPublicClient(time.Duration(cfg.NotificationConfig.Webhook.TimeoutSecs) * time.Second))
+}
+
+// NewWebhookSenderWithClient creates a new webhook sender with given http client
+func NewWebhookSenderWithClient(client *http.Client) *WebhookSender {
+	return &WebhookSender{client: client}
+}
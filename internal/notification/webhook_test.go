@@ -0,0 +1,62 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookSender_Send(t *testing.T) {
+	// given
+	var (
+		signature string
+		timestamp string
+		raw       []byte
+	)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(SignatureHeader)
+		timestamp = r.Header.Get(TimestampHeader)
+		raw, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	sender := NewWebhookSenderWithClient(server.Client())
+
+	// when
+	err := sender.Send(context.Background(), server.URL, "secret", map[string]string{"slug": "alert1"})
+
+	// then
+	assert.NoError(t, err)
+	assert.NotEmpty(t, timestamp)
+	assert.Equal(t, "sha256="+Sign("secret", timestamp, raw), signature)
+	var body map[string]string
+	assert.NoError(t, json.Unmarshal(raw, &body))
+	assert.Equal(t, "alert1", body["slug"])
+}
+
+func TestWebhookSender_FailIfErrorStatus(t *testing.T) {
+	// given
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	sender := NewWebhookSenderWithClient(server.Client())
+
+	// when
+	err := sender.Send(context.Background(), server.URL, "", map[string]string{})
+
+	// then
+	assert.Error(t, err)
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '1633046400.hello' | openssl dgst -sha256 -hmac key
+	assert.Equal(t, "e78a3e214f8aed607503195b203e5fd418b9bca5b9980d5dd4665f0c3cfe61fd", Sign("key", "1633046400", []byte("hello")))
+	// the signature changes with the timestamp so that a replayed request with a new timestamp is rejected
+	assert.NotEqual(t, Sign("key", "1633046400", []byte("hello")), Sign("key", "1633046401", []byte("hello")))
+}
@@ -0,0 +1,161 @@
+package database
+
+import (
+	"context"
+	"kek-backend/internal/database"
+	"kek-backend/internal/token/model"
+	"kek-backend/pkg/logging"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+type FindCandlesCriteria struct {
+	// TokenID is an address of the token. Candles of all tokens are returned if empty
+	TokenID  string
+	Interval string
+	// From and To are the range of the open time [From, To)
+	From  time.Time
+	To    time.Time
+	Limit uint
+}
+
+func (t *tokenDB) SavePriceSnapshots(ctx context.Context, snapshots []*model.PriceSnapshot) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, t.db)
+	logger.Debugw("token.db.SavePriceSnapshots", "count", len(snapshots))
+
+	if len(snapshots) == 0 {
+		return nil
+	}
+	if err := db.WithContext(ctx).Create(&snapshots).Error; err != nil {
+		logger.Errorw("token.db.SavePriceSnapshots failed to save snapshots", "err", err)
+		return err
+	}
+	return nil
+}
+
+func (t *tokenDB) FindPriceSnapshots(ctx context.Context, from, to time.Time) ([]*model.PriceSnapshot, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, t.db)
+	logger.Debugw("token.db.FindPriceSnapshots", "from", from, "to", to)
+
+	ret := []*model.PriceSnapshot{}
+	err := db.WithContext(ctx).
+		Where("observed_at >= ? AND observed_at < ?", from, to).
+		Order("token_id ASC, observed_at ASC, id ASC").
+		Find(&ret).Error
+	if err != nil {
+		logger.Errorw("token.db.FindPriceSnapshots failed to find snapshots", "err", err)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (t *tokenDB) FindFirstPriceSnapshot(ctx context.Context, tokenID string, since time.Time) (*model.PriceSnapshot, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, t.db)
+	logger.Debugw("token.db.FindFirstPriceSnapshot", "tokenID", tokenID, "since", since)
+
+	var ret model.PriceSnapshot
+	err := db.WithContext(ctx).
+		Where("token_id = ? AND observed_at >= ?", tokenID, since).
+		Order("observed_at ASC, id ASC").
+		First(&ret).Error
+	if err != nil {
+		if database.IsRecordNotFoundErr(err) {
+			return nil, database.ErrNotFound
+		}
+		logger.Errorw("token.db.FindFirstPriceSnapshot failed to find snapshot", "err", err)
+		return nil, err
+	}
+	return &ret, nil
+}
+
+func (t *tokenDB) DeletePriceSnapshotsBefore(ctx context.Context, before time.Time) (int64, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, t.db)
+	logger.Debugw("token.db.DeletePriceSnapshotsBefore", "before", before)
+
+	result := db.WithContext(ctx).Where("observed_at < ?", before).Delete(&model.PriceSnapshot{})
+	if result.Error != nil {
+		logger.Errorw("token.db.DeletePriceSnapshotsBefore failed to delete snapshots", "err", result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+func (t *tokenDB) SaveCandles(ctx context.Context, candles []*model.Candle) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, t.db)
+	logger.Debugw("token.db.SaveCandles", "count", len(candles))
+
+	if len(candles) == 0 {
+		return nil
+	}
+	err := db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token_id"}, {Name: "candle_interval"}, {Name: "open_time"}},
+		DoUpdates: clause.AssignmentColumns([]string{"open", "high", "low", "close", "samples", "updated_at"}),
+	}).Create(&candles).Error
+	if err != nil {
+		logger.Errorw("token.db.SaveCandles failed to save candles", "err", err)
+		return err
+	}
+	return nil
+}
+
+func (t *tokenDB) FindCandles(ctx context.Context, criteria FindCandlesCriteria) ([]*model.Candle, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, t.db)
+	logger.Debugw("token.db.FindCandles", "criteria", criteria)
+
+	chain := db.WithContext(ctx).
+		Where("candle_interval = ? AND open_time >= ? AND open_time < ?", criteria.Interval, criteria.From, criteria.To)
+	if criteria.TokenID != "" {
+		chain = chain.Where("token_id = ?", criteria.TokenID)
+	}
+	if criteria.Limit != 0 {
+		chain = chain.Limit(int(criteria.Limit))
+	}
+	ret := []*model.Candle{}
+	if err := chain.Order("token_id ASC, open_time ASC").Find(&ret).Error; err != nil {
+		logger.Errorw("token.db.FindCandles failed to find candles", "err", err)
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (t *tokenDB) FindLastCandle(ctx context.Context, interval string) (*model.Candle, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, t.db)
+	logger.Debugw("token.db.FindLastCandle", "interval", interval)
+
+	var ret model.Candle
+	err := db.WithContext(ctx).
+		Where("candle_interval = ?", interval).
+		Order("open_time DESC").
+		First(&ret).Error
+	if err != nil {
+		if database.IsRecordNotFoundErr(err) {
+			return nil, database.ErrNotFound
+		}
+		logger.Errorw("token.db.FindLastCandle failed to find candle", "err", err)
+		return nil, err
+	}
+	return &ret, nil
+}
+
+func (t *tokenDB) DeleteCandlesBefore(ctx context.Context, interval string, before time.Time) (int64, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, t.db)
+	logger.Debugw("token.db.DeleteCandlesBefore", "interval", interval, "before", before)
+
+	result := db.WithContext(ctx).
+		Where("candle_interval = ? AND open_time < ?", interval, before).
+		Delete(&model.Candle{})
+	if result.Error != nil {
+		logger.Errorw("token.db.DeleteCandlesBefore failed to delete candles", "err", result.Error)
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
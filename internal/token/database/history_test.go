@@ -0,0 +1,186 @@
+package database
+
+import (
+	"kek-backend/internal/database"
+	"kek-backend/internal/token/model"
+	"time"
+)
+
+func (s *DBSuite) TestFindPriceSnapshots() {
+	// given
+	now := time.Now().UTC().Truncate(time.Second)
+	s.NoError(s.db.SavePriceSnapshots(nil, []*model.PriceSnapshot{
+		{TokenID: "0xb", PriceUSD: 2, ObservedAt: now.Add(-10 * time.Second)},
+		{TokenID: "0xa", PriceUSD: 1.5, ObservedAt: now.Add(-5 * time.Second)},
+		{TokenID: "0xa", PriceUSD: 1, ObservedAt: now.Add(-10 * time.Second)},
+		{TokenID: "0xa", PriceUSD: 0.5, ObservedAt: now.Add(-2 * time.Minute)},
+	}))
+
+	// when
+	snapshots, err := s.db.FindPriceSnapshots(nil, now.Add(-time.Minute), now)
+
+	// then
+	s.NoError(err)
+	s.Len(snapshots, 3)
+	s.Equal("0xa", snapshots[0].TokenID)
+	s.Equal(1.0, snapshots[0].PriceUSD)
+	s.Equal(1.5, snapshots[1].PriceUSD)
+	s.Equal("0xb", snapshots[2].TokenID)
+}
+
+func (s *DBSuite) TestDeletePriceSnapshotsBefore() {
+	// given
+	now := time.Now().UTC().Truncate(time.Second)
+	s.NoError(s.db.SavePriceSnapshots(nil, []*model.PriceSnapshot{
+		{TokenID: "0xa", PriceUSD: 1, ObservedAt: now.Add(-time.Hour)},
+		{TokenID: "0xa", PriceUSD: 2, ObservedAt: now},
+	}))
+
+	// when
+	deleted, err := s.db.DeletePriceSnapshotsBefore(nil, now.Add(-time.Minute))
+
+	// then
+	s.NoError(err)
+	s.Equal(int64(1), deleted)
+	snapshots, err := s.db.FindPriceSnapshots(nil, now.Add(-2*time.Hour), now.Add(time.Second))
+	s.NoError(err)
+	s.Len(snapshots, 1)
+}
+
+func (s *DBSuite) TestSaveCandles_ReplaceIfExist() {
+	// given
+	openTime := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	candle := &model.Candle{TokenID: "0xa", Interval: model.Interval1m, OpenTime: openTime, Open: 1, High: 1, Low: 1, Close: 1, Samples: 1, UpdatedAt: openTime}
+	s.NoError(s.db.SaveCandles(nil, []*model.Candle{candle}))
+
+	// when
+	updated := *candle
+	updated.High, updated.Close, updated.Samples = 2, 2, 2
+	err := s.db.SaveCandles(nil, []*model.Candle{&updated})
+
+	// then
+	s.NoError(err)
+	candles, err := s.db.FindCandles(nil, FindCandlesCriteria{
+		TokenID:  "0xa",
+		Interval: model.Interval1m,
+		From:     openTime,
+		To:       openTime.Add(time.Minute),
+	})
+	s.NoError(err)
+	s.Len(candles, 1)
+	s.Equal(1.0, candles[0].Open)
+	s.Equal(2.0, candles[0].High)
+	s.Equal(2.0, candles[0].Close)
+	s.Equal(2, candles[0].Samples)
+}
+
+func (s *DBSuite) TestFindCandles() {
+	// given
+	openTime := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	var candles []*model.Candle
+	for i := 0; i < 3; i++ {
+		for _, tokenID := range []string{"0xa", "0xb"} {
+			candles = append(candles, &model.Candle{
+				TokenID:   tokenID,
+				Interval:  model.Interval1h,
+				OpenTime:  openTime.Add(time.Duration(i) * time.Hour),
+				Close:     float64(i),
+				UpdatedAt: openTime,
+			})
+		}
+	}
+	s.NoError(s.db.SaveCandles(nil, candles))
+
+	// when
+	find, err := s.db.FindCandles(nil, FindCandlesCriteria{
+		TokenID:  "0xa",
+		Interval: model.Interval1h,
+		From:     openTime.Add(time.Hour),
+		To:       openTime.Add(3 * time.Hour),
+	})
+
+	// then
+	s.NoError(err)
+	s.Len(find, 2)
+	s.Equal(openTime.Add(time.Hour), find[0].OpenTime.UTC())
+	s.Equal(openTime.Add(2*time.Hour), find[1].OpenTime.UTC())
+
+	// when
+	all, err := s.db.FindCandles(nil, FindCandlesCriteria{
+		Interval: model.Interval1h,
+		From:     openTime,
+		To:       openTime.Add(3 * time.Hour),
+	})
+
+	// then
+	s.NoError(err)
+	s.Len(all, 6)
+}
+
+func (s *DBSuite) TestFindFirstPriceSnapshot() {
+	// given
+	now := time.Now().UTC().Truncate(time.Second)
+	s.NoError(s.db.SavePriceSnapshots(nil, []*model.PriceSnapshot{
+		{TokenID: "0xa", PriceUSD: 1, ObservedAt: now.Add(-2 * time.Hour)},
+		{TokenID: "0xa", PriceUSD: 2, ObservedAt: now.Add(-30 * time.Minute)},
+		{TokenID: "0xa", PriceUSD: 3, ObservedAt: now},
+		{TokenID: "0xb", PriceUSD: 4, ObservedAt: now.Add(-50 * time.Minute)},
+	}))
+
+	// when
+	find, err := s.db.FindFirstPriceSnapshot(nil, "0xa", now.Add(-time.Hour))
+
+	// then
+	s.NoError(err)
+	s.Equal(2.0, find.PriceUSD)
+
+	// when
+	find, err = s.db.FindFirstPriceSnapshot(nil, "0xc", now.Add(-time.Hour))
+
+	// then
+	s.Nil(find)
+	s.True(database.IsRecordNotFoundErr(err))
+}
+
+func (s *DBSuite) TestFindLastCandle() {
+	// given
+	openTime := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	s.NoError(s.db.SaveCandles(nil, []*model.Candle{
+		{TokenID: "0xa", Interval: model.Interval1h, OpenTime: openTime, UpdatedAt: openTime},
+		{TokenID: "0xb", Interval: model.Interval1h, OpenTime: openTime.Add(time.Hour), UpdatedAt: openTime},
+		{TokenID: "0xa", Interval: model.Interval1m, OpenTime: openTime.Add(2 * time.Hour), UpdatedAt: openTime},
+	}))
+
+	// when
+	find, err := s.db.FindLastCandle(nil, model.Interval1h)
+
+	// then
+	s.NoError(err)
+	s.Equal("0xb", find.TokenID)
+	s.Equal(openTime.Add(time.Hour), find.OpenTime.UTC())
+	_, err = s.db.FindLastCandle(nil, model.Interval1d)
+	s.Equal(database.ErrNotFound, err)
+}
+
+func (s *DBSuite) TestDeleteCandlesBefore() {
+	// given
+	openTime := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	s.NoError(s.db.SaveCandles(nil, []*model.Candle{
+		{TokenID: "0xa", Interval: model.Interval1h, OpenTime: openTime, UpdatedAt: openTime},
+		{TokenID: "0xa", Interval: model.Interval1h, OpenTime: openTime.Add(time.Hour), UpdatedAt: openTime},
+		{TokenID: "0xa", Interval: model.Interval1d, OpenTime: openTime.Truncate(24 * time.Hour), UpdatedAt: openTime},
+	}))
+
+	// when
+	deleted, err := s.db.DeleteCandlesBefore(nil, model.Interval1h, openTime.Add(time.Hour))
+
+	// then
+	s.NoError(err)
+	s.Equal(int64(1), deleted)
+	hours, err := s.db.FindCandles(nil, FindCandlesCriteria{Interval: model.Interval1h, From: openTime, To: openTime.Add(2 * time.Hour)})
+	s.NoError(err)
+	s.Len(hours, 1)
+	days, err := s.db.FindCandles(nil, FindCandlesCriteria{Interval: model.Interval1d, From: openTime.Add(-24 * time.Hour), To: openTime})
+	s.NoError(err)
+	s.Len(days, 1)
+}
@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"kek-backend/internal/database"
+	"kek-backend/internal/token/model"
+	"kek-backend/pkg/logging"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SearchTokenCriteria struct {
+	// Query is a prefix of symbol or name matched case insensitively
+	Query string
+	Limit uint
+}
+
+//go:generate mockery --name TokenDB --filename token_mock.go
+type TokenDB interface {
+	// SaveToken saves a given token or updates the metadata if exists
+	SaveToken(ctx context.Context, token *model.Token) error
+
+	// FindTokenByID returns a token with given address
+	// database.ErrNotFound error is returned if not exist
+	FindTokenByID(ctx context.Context, id string) (*model.Token, error)
+
+	// SearchTokens returns tokens whose symbol or name starts with given query ordered by symbol
+	SearchTokens(ctx context.Context, criteria SearchTokenCriteria) ([]*model.Token, error)
+
+	// SavePriceSnapshots saves given price snapshots
+	SavePriceSnapshots(ctx context.Context, snapshots []*model.PriceSnapshot) error
+
+	// FindPriceSnapshots returns snapshots observed in [from, to) ordered by token and observed time
+	FindPriceSnapshots(ctx context.Context, from, to time.Time) ([]*model.PriceSnapshot, error)
+
+	// FindFirstPriceSnapshot returns the first snapshot of given token observed at or after given time
+	// database.ErrNotFound error is returned if not exist
+	FindFirstPriceSnapshot(ctx context.Context, tokenID string, since time.Time) (*model.PriceSnapshot, error)
+
+	// DeletePriceSnapshotsBefore deletes snapshots observed before given time and returns the number of them
+	DeletePriceSnapshotsBefore(ctx context.Context, before time.Time) (int64, error)
+
+	// SaveCandles saves given candles or replaces them if exist
+	SaveCandles(ctx context.Context, candles []*model.Candle) error
+
+	// FindCandles returns candles matched with given criteria ordered by token and open time
+	FindCandles(ctx context.Context, criteria FindCandlesCriteria) ([]*model.Candle, error)
+
+	// FindLastCandle returns a candle of given interval opened last among all tokens
+	// database.ErrNotFound error is returned if not exist
+	FindLastCandle(ctx context.Context, interval string) (*model.Candle, error)
+
+	// DeleteCandlesBefore deletes candles of given interval opened before given time and returns the number of them
+	DeleteCandlesBefore(ctx context.Context, interval string, before time.Time) (int64, error)
+}
+
+type tokenDB struct {
+	db *gorm.DB
+}
+
+func (t *tokenDB) SaveToken(ctx context.Context, token *model.Token) error {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, t.db)
+	logger.Debugw("token.db.SaveToken", "token", token)
+
+	err := db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "symbol", "decimals", "updated_at"}),
+	}).Create(token).Error
+	if err != nil {
+		logger.Errorw("token.db.SaveToken failed to save token", "err", err)
+		return err
+	}
+	return nil
+}
+
+func (t *tokenDB) FindTokenByID(ctx context.Context, id string) (*model.Token, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, t.db)
+	logger.Debugw("token.db.FindTokenByID", "id", id)
+
+	var ret model.Token
+	if err := db.WithContext(ctx).First(&ret, "id = ?", id).Error; err != nil {
+		logger.Errorw("token.db.FindTokenByID failed to find token", "err", err)
+		if database.IsRecordNotFoundErr(err) {
+			return nil, database.ErrNotFound
+		}
+		return nil, err
+	}
+	return &ret, nil
+}
+
+func (t *tokenDB) SearchTokens(ctx context.Context, criteria SearchTokenCriteria) ([]*model.Token, error) {
+	logger := logging.FromContext(ctx)
+	db := database.FromContext(ctx, t.db)
+	logger.Debugw("token.db.SearchTokens", "criteria", criteria)
+
+	prefix := escapeLike(strings.ToLower(criteria.Query)) + "%"
+	ret := []*model.Token{}
+	err := db.WithContext(ctx).
+		Where("LOWER(symbol) LIKE ? OR LOWER(name) LIKE ?", prefix, prefix).
+		Order("symbol ASC, id ASC").
+		Limit(int(criteria.Limit)).
+		Find(&ret).Error
+	if err != nil {
+		logger.Errorw("token.db.SearchTokens failed to search tokens", "err", err)
+		return nil, err
+	}
+	return ret, nil
+}
+
+// escapeLike escapes wildcard characters of LIKE patterns in given value
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+func NewTokenDB(db *gorm.DB) TokenDB {
+	return &tokenDB{
+		db: db,
+	}
+}
@@ -0,0 +1,119 @@
+package database
+
+import (
+	"kek-backend/internal/database"
+	"kek-backend/internal/token/model"
+	"kek-backend/pkg/logging"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap/zapcore"
+	"gorm.io/gorm"
+)
+
+type DBSuite struct {
+	suite.Suite
+	db       TokenDB
+	originDB *gorm.DB
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(DBSuite))
+}
+
+func (s *DBSuite) SetupSuite() {
+	logging.SetLevel(zapcore.FatalLevel)
+	s.originDB = database.NewTestDatabase(s.T(), true)
+	s.db = &tokenDB{db: s.originDB}
+}
+
+func (s *DBSuite) SetupTest() {
+	s.NoError(database.DeleteRecordAll(s.T(), s.originDB, []string{
+		"candles", "token_id <> ''",
+		"price_snapshots", "id > 0",
+		"tokens", "id <> ''",
+	}))
+}
+
+func (s *DBSuite) TestSaveToken() {
+	// given
+	token := newToken("0x6b175474e89094c44da98b954eedeac495271d0f", "Dai Stablecoin", "DAI")
+
+	// when
+	err := s.db.SaveToken(nil, token)
+
+	// then
+	s.NoError(err)
+	find, err := s.db.FindTokenByID(nil, token.ID)
+	s.NoError(err)
+	s.Equal(token.Name, find.Name)
+	s.Equal(token.Symbol, find.Symbol)
+	s.Equal(token.Decimals, find.Decimals)
+}
+
+func (s *DBSuite) TestSaveToken_UpdateIfExist() {
+	// given
+	token := newToken("0x6b175474e89094c44da98b954eedeac495271d0f", "Dai Stablecoin", "DAI")
+	s.NoError(s.db.SaveToken(nil, token))
+
+	// when
+	err := s.db.SaveToken(nil, newToken(token.ID, "Dai", "DAI2"))
+
+	// then
+	s.NoError(err)
+	find, err := s.db.FindTokenByID(nil, token.ID)
+	s.NoError(err)
+	s.Equal("Dai", find.Name)
+	s.Equal("DAI2", find.Symbol)
+}
+
+func (s *DBSuite) TestFindTokenByID_FailIfNotExist() {
+	// when
+	find, err := s.db.FindTokenByID(nil, "0x0000000000000000000000000000000000000001")
+
+	// then
+	s.Nil(find)
+	s.Error(err)
+	s.True(database.IsRecordNotFoundErr(err))
+}
+
+func (s *DBSuite) TestSearchTokens() {
+	// given
+	s.NoError(s.db.SaveToken(nil, newToken("0x6b175474e89094c44da98b954eedeac495271d0f", "Dai Stablecoin", "DAI")))
+	s.NoError(s.db.SaveToken(nil, newToken("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", "USD Coin", "USDC")))
+	s.NoError(s.db.SaveToken(nil, newToken("0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2", "Wrapped Ether", "WETH")))
+
+	cases := []struct {
+		Query    string
+		Limit    uint
+		Expected []string
+	}{
+		{Query: "d", Limit: 10, Expected: []string{"DAI"}},
+		{Query: "usd", Limit: 10, Expected: []string{"USDC"}},
+		{Query: "WRAPPED", Limit: 10, Expected: []string{"WETH"}},
+		{Query: "%", Limit: 10, Expected: []string{}},
+		{Query: "", Limit: 2, Expected: []string{"DAI", "USDC"}},
+	}
+
+	for _, tc := range cases {
+		// when
+		results, err := s.db.SearchTokens(nil, SearchTokenCriteria{Query: tc.Query, Limit: tc.Limit})
+
+		// then
+		s.NoError(err)
+		symbols := []string{}
+		for _, r := range results {
+			symbols = append(symbols, r.Symbol)
+		}
+		s.Equal(tc.Expected, symbols)
+	}
+}
+
+func newToken(id, name, symbol string) *model.Token {
+	return &model.Token{
+		ID:       id,
+		Name:     name,
+		Symbol:   symbol,
+		Decimals: 18,
+	}
+}
@@ -0,0 +1,232 @@
+package token
+
+import (
+	"fmt"
+	"kek-backend/internal/config"
+	"kek-backend/internal/middleware"
+	"kek-backend/internal/middleware/handler"
+	tokenDB "kek-backend/internal/token/database"
+	"kek-backend/internal/token/model"
+	"kek-backend/internal/uniswap"
+	"kek-backend/pkg/logging"
+	"kek-backend/pkg/validate"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/pkg/errors"
+)
+
+type Handler struct {
+	tokenDB tokenDB.TokenDB
+	prices  *uniswap.PriceCache
+}
+
+// tokens handles GET /v1/api/tokens
+func (h *Handler) tokens(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		logger := logging.FromContext(c)
+		type QueryParameter struct {
+			Query string `form:"q" binding:"required,max=64"`
+			Limit string `form:"limit,default=10" binding:"numeric"`
+		}
+		var query QueryParameter
+		if err := c.ShouldBindQuery(&query); err != nil {
+			logger.Errorw("token.handler.tokens failed to bind", "err", err)
+			var details []*validate.ValidationErrDetail
+			if vErrs, ok := err.(validator.ValidationErrors); ok {
+				details = validate.ValidationErrorDetails(&query, "form", vErrs)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidQueryValue, "invalid token request in query", details)
+		}
+
+		limit, err := strconv.ParseUint(query.Limit, 10, 64)
+		if err != nil || limit > 50 {
+			limit = 10
+		}
+		criteria := tokenDB.SearchTokenCriteria{
+			Query: strings.TrimSpace(query.Query),
+			Limit: uint(limit),
+		}
+		tokens, err := h.tokenDB.SearchTokens(c.Request.Context(), criteria)
+		if err != nil {
+			return handler.NewInternalErrorResponse(err)
+		}
+		return handler.NewSuccessResponse(http.StatusOK, NewTokensResponse(tokens))
+	})
+}
+
+// tokenByAddress handles GET /v1/api/tokens/:address
+func (h *Handler) tokenByAddress(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		token, ethPrice, res := h.findToken(c)
+		if res != nil {
+			return res
+		}
+		tokenRes, err := NewTokenResponse(token, ethPrice)
+		if err != nil {
+			return handler.NewInternalErrorResponse(err)
+		}
+		return handler.NewSuccessResponse(http.StatusOK, tokenRes)
+	})
+}
+
+// tokenPrice handles GET /v1/api/tokens/:address/price
+func (h *Handler) tokenPrice(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		token, ethPrice, res := h.findToken(c)
+		if res != nil {
+			return res
+		}
+		priceRes, err := NewPriceResponse(token, ethPrice)
+		if err != nil {
+			return handler.NewInternalErrorResponse(err)
+		}
+		return handler.NewSuccessResponse(http.StatusOK, priceRes)
+	})
+}
+
+// maxCandles is the maximum number of candles returned by a request
+const maxCandles = 1000
+
+// candles handles GET /v1/api/tokens/:address/candles
+func (h *Handler) candles(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		logger := logging.FromContext(c)
+		// bind
+		type RequestUri struct {
+			Address string `uri:"address" binding:"required"`
+		}
+		var uri RequestUri
+		if err := c.ShouldBindUri(&uri); err != nil {
+			logger.Errorw("token.handler.candles failed to bind uri", "err", err)
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidUriValue, "invalid candle request in uri", nil)
+		}
+		address, err := uniswap.NormalizeAddress(uri.Address)
+		if err != nil {
+			details := validate.NewValidationErrorDetails("address", "address must be 0x followed by 40 hex characters", uri.Address)
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidUriValue, "invalid candle request in uri", details)
+		}
+		type QueryParameter struct {
+			Interval string    `form:"interval" binding:"required,oneof=1m 1h 1d"`
+			From     time.Time `form:"from"`
+			To       time.Time `form:"to"`
+		}
+		var query QueryParameter
+		if err := c.ShouldBindQuery(&query); err != nil {
+			logger.Errorw("token.handler.candles failed to bind query", "err", err)
+			var details []*validate.ValidationErrDetail
+			if vErrs, ok := err.(validator.ValidationErrors); ok {
+				details = validate.ValidationErrorDetails(&query, "form", vErrs)
+			}
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidQueryValue, "invalid candle request in query", details)
+		}
+
+		// the latest candles are returned if the range is not given
+		d, _ := model.IntervalDuration(query.Interval)
+		to := query.To
+		if to.IsZero() {
+			to = time.Now()
+		}
+		from := query.From
+		if from.IsZero() {
+			from = to.Truncate(d).Add(-100 * d)
+		}
+		if !from.Before(to) {
+			details := validate.NewValidationErrorDetails("from", "from must be before to", query.From.String())
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidQueryValue, "invalid candle request in query", details)
+		}
+		if to.Sub(from) > maxCandles*d {
+			details := validate.NewValidationErrorDetails("from", fmt.Sprintf("range must not exceed %d candles", maxCandles), query.From.String())
+			return handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidQueryValue, "invalid candle request in query", details)
+		}
+
+		// find
+		candles, err := h.tokenDB.FindCandles(c.Request.Context(), tokenDB.FindCandlesCriteria{
+			TokenID:  address,
+			Interval: query.Interval,
+			From:     from,
+			To:       to,
+			Limit:    maxCandles,
+		})
+		if err != nil {
+			return handler.NewInternalErrorResponse(err)
+		}
+		return handler.NewSuccessResponse(http.StatusOK, NewCandlesResponse(address, query.Interval, candles))
+	})
+}
+
+// ethPrice handles GET /v1/api/eth-price
+func (h *Handler) ethPrice(c *gin.Context) {
+	handler.HandleRequest(c, func(c *gin.Context) *handler.Response {
+		ethPrice, err := h.prices.EthPrice(c.Request.Context())
+		if err != nil {
+			logging.FromContext(c).Errorw("token.handler.ethPrice failed to get eth price", "err", err)
+			return handler.NewInternalErrorResponse(err)
+		}
+		return handler.NewSuccessResponse(http.StatusOK, &EthPriceResponse{EthPrice: ethPrice})
+	})
+}
+
+// findToken returns a token with the address in uri and the USD price of ETH.
+// An error response is returned if failed to find them.
+func (h *Handler) findToken(c *gin.Context) (*uniswap.Token, float64, *handler.Response) {
+	logger := logging.FromContext(c)
+	// bind
+	type RequestUri struct {
+		Address string `uri:"address" binding:"required"`
+	}
+	var uri RequestUri
+	if err := c.ShouldBindUri(&uri); err != nil {
+		logger.Errorw("token.handler failed to bind", "err", err)
+		return nil, 0, handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidUriValue, "invalid token request in uri", nil)
+	}
+	address, err := uniswap.NormalizeAddress(uri.Address)
+	if err != nil {
+		details := validate.NewValidationErrorDetails("address", "address must be 0x followed by 40 hex characters", uri.Address)
+		return nil, 0, handler.NewErrorResponse(http.StatusBadRequest, handler.InvalidUriValue, "invalid token request in uri", details)
+	}
+
+	// find
+	ctx := c.Request.Context()
+	token, err := h.prices.Token(ctx, address)
+	if err != nil {
+		if errors.Is(err, uniswap.ErrNotFound) {
+			return nil, 0, handler.NewErrorResponse(http.StatusNotFound, handler.NotFoundEntity, "not found token", nil)
+		}
+		logger.Errorw("token.handler failed to get token", "address", address, "err", err)
+		return nil, 0, handler.NewInternalErrorResponse(err)
+	}
+	ethPrice, err := h.prices.EthPrice(ctx)
+	if err != nil {
+		logger.Errorw("token.handler failed to get eth price", "err", err)
+		return nil, 0, handler.NewInternalErrorResponse(err)
+	}
+	return token, ethPrice, nil
+}
+
+func RouteV1(cfg *config.Config, h *Handler, r *gin.Engine) {
+	v1 := r.Group("v1/api")
+	timeout := time.Duration(cfg.ServerConfig.WriteTimeoutSecs) * time.Second
+	v1.Use(middleware.RequestIDMiddleware(), middleware.TimeoutMiddleware(timeout))
+
+	// anonymous
+	tokenV1 := v1.Group("tokens")
+	{
+		tokenV1.GET("", h.tokens)
+		tokenV1.GET(":address", h.tokenByAddress)
+		tokenV1.GET(":address/price", h.tokenPrice)
+		tokenV1.GET(":address/candles", h.candles)
+	}
+	v1.GET("eth-price", h.ethPrice)
+}
+
+func NewHandler(tokenDB tokenDB.TokenDB, prices *uniswap.PriceCache) *Handler {
+	return &Handler{
+		tokenDB: tokenDB,
+		prices:  prices,
+	}
+}
@@ -0,0 +1,288 @@
+package token
+
+import (
+	"context"
+	"encoding/json"
+	"kek-backend/internal/config"
+	"kek-backend/internal/metric"
+	"kek-backend/internal/token/database"
+	"kek-backend/internal/token/database/mocks"
+	"kek-backend/internal/token/model"
+	"kek-backend/internal/uniswap"
+	"kek-backend/pkg/logging"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/tidwall/gjson"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	dAddress    = "0x6b175474e89094c44da98b954eedeac495271d0f"
+	usdcAddress = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+)
+
+// metrics are registered globally so that the provider is created once
+var testMetricsProvider = metric.NewMetricsProvider(&config.Config{})
+
+type HandlerSuite struct {
+	suite.Suite
+	r        *gin.Engine
+	db       *mocks.TokenDB
+	subgraph *httptest.Server
+	sync     *Sync
+}
+
+func (s *HandlerSuite) SetupSuite() {
+	logging.SetLevel(zapcore.FatalLevel)
+}
+
+func (s *HandlerSuite) SetupTest() {
+	// stand-in subgraph serving DAI and USDC as the only top token
+	s.subgraph = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req uniswap.GraphQLRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch {
+		case req.Variables["id"] == dAddress:
+			_, _ = w.Write([]byte(`{"data":{"tokens":[{"id":"` + dAddress + `","name":"Dai Stablecoin","symbol":"DAI","derivedETH":"0.0005","totalLiquidity":"1000.5"}]}}`))
+		case req.Variables["id"] != nil:
+			_, _ = w.Write([]byte(`{"data":{"tokens":[]}}`))
+		case req.Variables["skip"] != nil:
+			_, _ = w.Write([]byte(`{"data":{"tokens":[{"id":"` + usdcAddress + `","name":"USD Coin","symbol":"USDC","decimals":"6"}]}}`))
+		default:
+			_, _ = w.Write([]byte(`{"data":{"bundles":[{"ethPrice":"2000"}]}}`))
+		}
+	}))
+
+	cfg, err := config.Load("")
+	s.NoError(err)
+	cfg.UniswapConfig.Endpoint = s.subgraph.URL
+	cfg.UniswapConfig.Retries = 0
+	prices := uniswap.NewPriceCache(cfg, uniswap.NewClient(cfg), testMetricsProvider)
+
+	gin.SetMode(gin.TestMode)
+	s.r = gin.Default()
+	s.db = &mocks.TokenDB{}
+	RouteV1(cfg, NewHandler(s.db, prices), s.r)
+	cfg.TokenConfig.Sync.Limit = 10
+	s.sync = NewSync(cfg, s.db, uniswap.NewClient(cfg), nil)
+}
+
+func (s *HandlerSuite) TearDownTest() {
+	s.subgraph.Close()
+}
+
+func TestSuite(t *testing.T) {
+	suite.Run(t, new(HandlerSuite))
+}
+
+func (s *HandlerSuite) TestTokenByAddress() {
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/tokens/0x6B175474E89094C44Da98b954EedeAC495271d0F", nil)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusOK, res.Code)
+	token := gjson.Get(res.Body.String(), "token")
+	s.Equal(dAddress, token.Get("address").String())
+	s.Equal("Dai Stablecoin", token.Get("name").String())
+	s.Equal("DAI", token.Get("symbol").String())
+	s.Equal(0.0005, token.Get("derivedETH").Float())
+	s.Equal(float64(1), token.Get("priceUSD").Float())
+	s.Equal(1000.5, token.Get("totalLiquidity").Float())
+}
+
+func (s *HandlerSuite) TestTokenByAddress_FailIfInvalidAddress() {
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/tokens/0x6b17", nil)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusBadRequest, res.Code)
+	s.Equal("InvalidUriValue", gjson.Get(res.Body.String(), "code").String())
+}
+
+func (s *HandlerSuite) TestTokenByAddress_FailIfNotFound() {
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/tokens/0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", nil)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusNotFound, res.Code)
+	s.Equal("NotFoundEntity", gjson.Get(res.Body.String(), "code").String())
+}
+
+func (s *HandlerSuite) TestTokenPrice() {
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/tokens/"+dAddress+"/price", nil)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusOK, res.Code)
+	price := gjson.Get(res.Body.String(), "price")
+	s.Equal(dAddress, price.Get("address").String())
+	s.Equal(float64(2000), price.Get("ethPrice").Float())
+	s.Equal(float64(1), price.Get("priceUSD").Float())
+}
+
+func (s *HandlerSuite) TestEthPrice() {
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/eth-price", nil)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusOK, res.Code)
+	s.Equal(float64(2000), gjson.Get(res.Body.String(), "ethPrice").Float())
+}
+
+func (s *HandlerSuite) TestEthPrice_FailIfSubgraphDown() {
+	// given
+	s.subgraph.Close()
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/eth-price", nil)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusInternalServerError, res.Code)
+	s.Equal("InternalServerError", gjson.Get(res.Body.String(), "code").String())
+}
+
+func (s *HandlerSuite) TestSearchTokens() {
+	// given
+	s.db.On("SearchTokens", mock.Anything, mock.MatchedBy(func(criteria database.SearchTokenCriteria) bool {
+		return criteria.Query == "da" && criteria.Limit == 10
+	})).Return([]*model.Token{
+		{ID: dAddress, Name: "Dai Stablecoin", Symbol: "DAI", Decimals: 18},
+	}, nil)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/tokens?q=da", nil)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusOK, res.Code)
+	tokens := gjson.Get(res.Body.String(), "tokens").Array()
+	s.Len(tokens, 1)
+	s.Equal(dAddress, tokens[0].Get("address").String())
+	s.Equal("DAI", tokens[0].Get("symbol").String())
+	s.Equal(int64(18), tokens[0].Get("decimals").Int())
+}
+
+func (s *HandlerSuite) TestSearchTokens_FindSyncedToken() {
+	// given
+	var synced []*model.Token
+	s.db.On("SaveToken", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		synced = append(synced, args.Get(1).(*model.Token))
+	}).Return(nil)
+	s.db.On("SearchTokens", mock.Anything, mock.MatchedBy(func(criteria database.SearchTokenCriteria) bool {
+		return criteria.Query == "usd"
+	})).Return(func(_ context.Context, _ database.SearchTokenCriteria) []*model.Token {
+		return synced
+	}, nil)
+	_, err := s.sync.Run(context.Background())
+	s.NoError(err)
+
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/tokens?q=usd", nil)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusOK, res.Code)
+	tokens := gjson.Get(res.Body.String(), "tokens").Array()
+	s.Len(tokens, 1)
+	s.Equal(usdcAddress, tokens[0].Get("address").String())
+	s.Equal("USDC", tokens[0].Get("symbol").String())
+	s.Equal(int64(6), tokens[0].Get("decimals").Int())
+}
+
+func (s *HandlerSuite) TestSearchTokens_FailIfQueryMissing() {
+	// when
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v1/api/tokens", nil)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusBadRequest, res.Code)
+	s.db.AssertNotCalled(s.T(), "SearchTokens", mock.Anything, mock.Anything)
+}
+
+func (s *HandlerSuite) TestCandles() {
+	// given
+	from := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(3 * time.Hour)
+	s.db.On("FindCandles", mock.Anything, mock.MatchedBy(func(criteria database.FindCandlesCriteria) bool {
+		return criteria.TokenID == dAddress && criteria.Interval == model.Interval1h &&
+			criteria.From.Equal(from) && criteria.To.Equal(to)
+	})).Return([]*model.Candle{
+		{TokenID: dAddress, Interval: model.Interval1h, OpenTime: from, Open: 1, High: 1.2, Low: 0.9, Close: 1.1},
+		{TokenID: dAddress, Interval: model.Interval1h, OpenTime: from.Add(time.Hour), Open: 1.1, High: 1.1, Low: 1, Close: 1},
+	}, nil)
+
+	// when
+	res := httptest.NewRecorder()
+	url := "/v1/api/tokens/" + dAddress + "/candles?interval=1h&from=" + from.Format(time.RFC3339) + "&to=" + to.Format(time.RFC3339)
+	req, _ := http.NewRequest("GET", url, nil)
+
+	s.r.ServeHTTP(res, req)
+
+	// then
+	s.Equal(http.StatusOK, res.Code)
+	result := gjson.Parse(res.Body.String())
+	s.Equal(dAddress, result.Get("address").String())
+	s.Equal("1h", result.Get("interval").String())
+	candles := result.Get("candles").Array()
+	s.Len(candles, 2)
+	s.Equal(from.Format(time.RFC3339), candles[0].Get("openTime").String())
+	s.Equal(1.2, candles[0].Get("high").Float())
+	s.Equal(0.9, candles[0].Get("low").Float())
+	s.Equal(1.0, candles[1].Get("close").Float())
+}
+
+func (s *HandlerSuite) TestCandles_FailIfInvalidRequest() {
+	cases := []struct {
+		Name  string
+		Query string
+	}{
+		{Name: "missing interval", Query: ""},
+		{Name: "unknown interval", Query: "interval=5m"},
+		{Name: "invalid time", Query: "interval=1m&from=yesterday"},
+		{Name: "reversed range", Query: "interval=1m&from=2021-10-02T00:00:00Z&to=2021-10-01T00:00:00Z"},
+		{Name: "too long range", Query: "interval=1m&from=2021-10-01T00:00:00Z&to=2021-10-02T00:00:00Z"},
+	}
+
+	for _, tc := range cases {
+		// when
+		res := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/v1/api/tokens/"+dAddress+"/candles?"+tc.Query, nil)
+
+		s.r.ServeHTTP(res, req)
+
+		// then
+		s.Equal(http.StatusBadRequest, res.Code, tc.Name)
+		s.Equal("InvalidQueryValue", gjson.Get(res.Body.String(), "code").String(), tc.Name)
+	}
+	s.db.AssertNotCalled(s.T(), "FindCandles", mock.Anything, mock.Anything)
+}
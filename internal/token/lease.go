@@ -0,0 +1,73 @@
+package token
+
+import (
+	"context"
+	"kek-backend/pkg/logging"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// rollupLease is the name of the lease held by the replica running the rollup
+	rollupLease = "token-rollup"
+	// syncLease is the name of the lease held by the replica running the token sync
+	syncLease = "token-sync"
+	// releaseTimeout is a timeout of releasing a lease on shutdown
+	releaseTimeout = 3 * time.Second
+)
+
+// Leases acquires named leases shared by replicas so that a job runs on one replica at a time.
+// It is implemented by the alert database holding the scheduler lease.
+type Leases interface {
+	// AcquireLease acquires or renews a lease with given name for given holder until ttl after given time
+	// and returns true if given holder holds the lease
+	AcquireLease(ctx context.Context, name, holder string, now time.Time, ttl time.Duration) (bool, error)
+	// ReleaseLease releases a lease with given name if held by given holder
+	ReleaseLease(ctx context.Context, name, holder string) error
+}
+
+// leaseGuard runs a job only on the replica holding the lease with its name
+type leaseGuard struct {
+	leases Leases
+	name   string
+	holder string
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// leading acquires or renews the lease and returns true if this replica holds it
+func (g *leaseGuard) leading(ctx context.Context) bool {
+	logger := logging.FromContext(ctx)
+	acquired, err := g.leases.AcquireLease(ctx, g.name, g.holder, g.now(), g.ttl)
+	if err != nil {
+		logger.Errorw("token.lease failed to acquire lease", "name", g.name, "holder", g.holder, "err", err)
+		return false
+	}
+	if !acquired {
+		logger.Debugw("token.lease lease held by another replica", "name", g.name, "holder", g.holder)
+	}
+	return acquired
+}
+
+// release releases the lease so that another replica takes over without waiting for its expiration
+func (g *leaseGuard) release() {
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+	if err := g.leases.ReleaseLease(ctx, g.name, g.holder); err != nil {
+		logging.DefaultLogger().Errorw("token.lease failed to release lease", "name", g.name, "holder", g.holder, "err", err)
+	}
+}
+
+// newLeaseGuard creates a new guard of the lease with given name held for ttl by this replica
+func newLeaseGuard(leases Leases, name string, ttl time.Duration) *leaseGuard {
+	hostname, _ := os.Hostname()
+	return &leaseGuard{
+		leases: leases,
+		name:   name,
+		holder: hostname + "-" + uuid.NewString(),
+		ttl:    ttl,
+		now:    time.Now,
+	}
+}
@@ -0,0 +1,75 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeLeases holds leases in memory like the lease table
+type fakeLeases struct {
+	holders map[string]string
+	expires map[string]time.Time
+	err     error
+}
+
+func (l *fakeLeases) AcquireLease(_ context.Context, name, holder string, now time.Time, ttl time.Duration) (bool, error) {
+	if l.err != nil {
+		return false, l.err
+	}
+	if h, ok := l.holders[name]; ok && h != holder && !l.expires[name].Before(now) {
+		return false, nil
+	}
+	l.holders[name] = holder
+	l.expires[name] = now.Add(ttl)
+	return true, nil
+}
+
+func (l *fakeLeases) ReleaseLease(_ context.Context, name, holder string) error {
+	if l.holders[name] == holder {
+		delete(l.holders, name)
+		delete(l.expires, name)
+	}
+	return nil
+}
+
+func newFakeLeases() *fakeLeases {
+	return &fakeLeases{holders: make(map[string]string), expires: make(map[string]time.Time)}
+}
+
+func TestLeaseGuard_Leading(t *testing.T) {
+	// given
+	now := time.Now()
+	leases := newFakeLeases()
+	replica1 := newLeaseGuard(leases, rollupLease, time.Minute)
+	replica2 := newLeaseGuard(leases, rollupLease, time.Minute)
+	other := newLeaseGuard(leases, syncLease, time.Minute)
+	for _, g := range []*leaseGuard{replica1, replica2, other} {
+		g.now = func() time.Time { return now }
+	}
+
+	// when, then : one replica holds a lease and jobs of other leases are not blocked
+	assert.True(t, replica1.leading(context.Background()))
+	assert.False(t, replica2.leading(context.Background()))
+	assert.True(t, other.leading(context.Background()))
+	assert.True(t, replica1.leading(context.Background()))
+
+	// when, then : another replica takes over the released lease
+	replica1.release()
+	assert.True(t, replica2.leading(context.Background()))
+
+	// when, then : another replica takes over the expired lease
+	now = now.Add(2 * time.Minute)
+	assert.True(t, replica1.leading(context.Background()))
+}
+
+func TestLeaseGuard_NotLeadingIfFailed(t *testing.T) {
+	leases := newFakeLeases()
+	leases.err = errors.New("connection refused")
+	g := newLeaseGuard(leases, syncLease, time.Minute)
+
+	assert.False(t, g.leading(context.Background()))
+}
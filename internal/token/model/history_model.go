@@ -0,0 +1,52 @@
+package model
+
+import "time"
+
+const (
+	Interval1m = "1m"
+	Interval1h = "1h"
+	Interval1d = "1d"
+)
+
+// IntervalDuration returns the length of a candle with given interval
+func IntervalDuration(interval string) (time.Duration, bool) {
+	switch interval {
+	case Interval1m:
+		return time.Minute, true
+	case Interval1h:
+		return time.Hour, true
+	case Interval1d:
+		return 24 * time.Hour, true
+	}
+	return 0, false
+}
+
+// PriceSnapshot is a USD price of a token observed by the alert evaluator
+type PriceSnapshot struct {
+	ID         uint      `gorm:"column:id"`
+	TokenID    string    `gorm:"column:token_id"`
+	PriceUSD   float64   `gorm:"column:price_usd"`
+	ObservedAt time.Time `gorm:"column:observed_at"`
+}
+
+func (PriceSnapshot) TableName() string {
+	return "price_snapshots"
+}
+
+// Candle is an OHLC summary of the USD prices of a token in the interval
+// starting from OpenTime. Samples is the number of snapshots in the candle.
+type Candle struct {
+	TokenID   string    `gorm:"column:token_id;primaryKey"`
+	Interval  string    `gorm:"column:candle_interval;primaryKey"`
+	OpenTime  time.Time `gorm:"column:open_time;primaryKey"`
+	Open      float64   `gorm:"column:open"`
+	High      float64   `gorm:"column:high"`
+	Low       float64   `gorm:"column:low"`
+	Close     float64   `gorm:"column:close"`
+	Samples   int       `gorm:"column:samples"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+}
+
+func (Candle) TableName() string {
+	return "candles"
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// Token is a metadata of an ERC20 token fetched from the uniswap subgraph.
+// ID is a lower case 0x prefixed address.
+type Token struct {
+	ID        string    `gorm:"column:id;primaryKey"`
+	Name      string    `gorm:"column:name"`
+	Symbol    string    `gorm:"column:symbol"`
+	Decimals  int       `gorm:"column:decimals"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+}
@@ -0,0 +1,75 @@
+package token
+
+import (
+	"context"
+	"kek-backend/internal/database"
+	tokenDB "kek-backend/internal/token/database"
+	"kek-backend/internal/token/model"
+	"kek-backend/internal/uniswap"
+	"kek-backend/pkg/logging"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// ErrUnknownToken is returned if an address does not resolve to a token
+var ErrUnknownToken = errors.New("unknown token")
+
+// Resolver resolves addresses to token metadata.
+// Tokens are looked up in the tokens table first and fetched from the subgraph
+// and saved to the table if not exist.
+type Resolver struct {
+	tokenDB tokenDB.TokenDB
+	prices  *uniswap.PriceCache
+}
+
+// Resolve returns a token with given address.
+// uniswap.ErrInvalidAddress is returned if the address is invalid and
+// ErrUnknownToken is returned if the subgraph has no token with the address
+func (r *Resolver) Resolve(ctx context.Context, address string) (*model.Token, error) {
+	logger := logging.FromContext(ctx)
+	id, err := uniswap.NormalizeAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := r.tokenDB.FindTokenByID(ctx, id)
+	if err == nil {
+		return token, nil
+	}
+	if !database.IsRecordNotFoundErr(err) {
+		return nil, err
+	}
+
+	t, err := r.prices.Token(ctx, id)
+	if err != nil {
+		if errors.Is(err, uniswap.ErrNotFound) {
+			return nil, ErrUnknownToken
+		}
+		return nil, err
+	}
+	token = newToken(id, t)
+	if err := r.tokenDB.SaveToken(ctx, token); err != nil {
+		// the token is still resolved even if failed to cache it
+		logger.Warnw("token.resolver failed to save token", "id", id, "err", err)
+	}
+	return token, nil
+}
+
+// newToken returns token metadata with given normalized address of given subgraph token
+func newToken(id string, t *uniswap.Token) *model.Token {
+	decimals, _ := strconv.Atoi(t.Decimals)
+	return &model.Token{
+		ID:       id,
+		Name:     t.Name,
+		Symbol:   t.Symbol,
+		Decimals: decimals,
+	}
+}
+
+func NewResolver(tokenDB tokenDB.TokenDB, prices *uniswap.PriceCache) *Resolver {
+	return &Resolver{
+		tokenDB: tokenDB,
+		prices:  prices,
+	}
+}
@@ -0,0 +1,140 @@
+package token
+
+import (
+	"kek-backend/internal/token/model"
+	"kek-backend/internal/uniswap"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type TokenResponse struct {
+	Token Token `json:"token"`
+}
+
+type Token struct {
+	Address        string  `json:"address"`
+	Name           string  `json:"name"`
+	Symbol         string  `json:"symbol"`
+	DerivedETH     float64 `json:"derivedETH"`
+	PriceUSD       float64 `json:"priceUSD"`
+	TotalLiquidity float64 `json:"totalLiquidity"`
+}
+
+type TokensResponse struct {
+	Tokens []TokenMetadata `json:"tokens"`
+}
+
+type TokenMetadata struct {
+	Address  string `json:"address"`
+	Name     string `json:"name"`
+	Symbol   string `json:"symbol"`
+	Decimals int    `json:"decimals"`
+}
+
+type PriceResponse struct {
+	Price Price `json:"price"`
+}
+
+type Price struct {
+	Address    string  `json:"address"`
+	Symbol     string  `json:"symbol"`
+	DerivedETH float64 `json:"derivedETH"`
+	EthPrice   float64 `json:"ethPrice"`
+	PriceUSD   float64 `json:"priceUSD"`
+}
+
+type CandlesResponse struct {
+	Address  string   `json:"address"`
+	Interval string   `json:"interval"`
+	Candles  []Candle `json:"candles"`
+}
+
+type Candle struct {
+	OpenTime time.Time `json:"openTime"`
+	Open     float64   `json:"open"`
+	High     float64   `json:"high"`
+	Low      float64   `json:"low"`
+	Close    float64   `json:"close"`
+}
+
+type EthPriceResponse struct {
+	EthPrice float64 `json:"ethPrice"`
+}
+
+// NewTokenResponse converts a uniswap token and the USD price of ETH to TokenResponse
+func NewTokenResponse(t *uniswap.Token, ethPrice float64) (*TokenResponse, error) {
+	derivedETH, err := strconv.ParseFloat(t.DerivedETH, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse derivedETH")
+	}
+	var liquidity float64
+	if t.TotalLiquidity != "" {
+		if liquidity, err = strconv.ParseFloat(t.TotalLiquidity, 64); err != nil {
+			return nil, errors.Wrap(err, "parse totalLiquidity")
+		}
+	}
+	return &TokenResponse{
+		Token: Token{
+			Address:        t.Id,
+			Name:           t.Name,
+			Symbol:         t.Symbol,
+			DerivedETH:     derivedETH,
+			PriceUSD:       derivedETH * ethPrice,
+			TotalLiquidity: liquidity,
+		},
+	}, nil
+}
+
+// NewPriceResponse converts a uniswap token and the USD price of ETH to PriceResponse
+func NewPriceResponse(t *uniswap.Token, ethPrice float64) (*PriceResponse, error) {
+	derivedETH, err := strconv.ParseFloat(t.DerivedETH, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse derivedETH")
+	}
+	return &PriceResponse{
+		Price: Price{
+			Address:    t.Id,
+			Symbol:     t.Symbol,
+			DerivedETH: derivedETH,
+			EthPrice:   ethPrice,
+			PriceUSD:   derivedETH * ethPrice,
+		},
+	}, nil
+}
+
+// NewTokensResponse converts token models to TokensResponse
+func NewTokensResponse(tokens []*model.Token) *TokensResponse {
+	t := []TokenMetadata{}
+	for _, token := range tokens {
+		t = append(t, TokenMetadata{
+			Address:  token.ID,
+			Name:     token.Name,
+			Symbol:   token.Symbol,
+			Decimals: token.Decimals,
+		})
+	}
+	return &TokensResponse{
+		Tokens: t,
+	}
+}
+
+// NewCandlesResponse converts candle models to CandlesResponse
+func NewCandlesResponse(address, interval string, candles []*model.Candle) *CandlesResponse {
+	c := []Candle{}
+	for _, candle := range candles {
+		c = append(c, Candle{
+			OpenTime: candle.OpenTime,
+			Open:     candle.Open,
+			High:     candle.High,
+			Low:      candle.Low,
+			Close:    candle.Close,
+		})
+	}
+	return &CandlesResponse{
+		Address:  address,
+		Interval: interval,
+		Candles:  c,
+	}
+}
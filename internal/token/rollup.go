@@ -0,0 +1,282 @@
+package token
+
+import (
+	"context"
+	"fmt"
+	"kek-backend/internal/config"
+	"kek-backend/internal/database"
+	tokenDB "kek-backend/internal/token/database"
+	"kek-backend/internal/token/model"
+	"kek-backend/pkg/logging"
+	"sort"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.uber.org/fx"
+)
+
+// backfillStep is the range of candles built at once by the backfill
+const backfillStep = 6 * time.Hour
+
+// rollups are the candle intervals built by the rollup and the source of each of them.
+// 1m candles are built from price snapshots and the others from the smaller candles.
+var rollups = []struct {
+	Interval string
+	Source   string
+}{
+	{Interval: model.Interval1m},
+	{Interval: model.Interval1h, Source: model.Interval1m},
+	{Interval: model.Interval1d, Source: model.Interval1h},
+}
+
+// Rollup aggregates price snapshots into OHLC candles.
+// Each run rebuilds the current and the previous candle of every interval so that
+// a candle is completed by the first run after it is closed.
+// Runs are started only on the replica holding the rollup lease and the first run
+// after acquiring it backfills candles missed while no replica was running the rollup.
+type Rollup struct {
+	tokenDB   tokenDB.TokenDB
+	lease     *leaseGuard
+	interval  time.Duration
+	retention time.Duration
+	// candleRetentions are how long candles are kept by interval. Candles are kept forever if absent
+	candleRetentions map[string]time.Duration
+	// caughtUp is true if candles have been backfilled since this replica acquired the lease
+	caughtUp bool
+	now      func() time.Time
+}
+
+// Run builds candles of all intervals and deletes snapshots and candles older than their retentions
+func (r *Rollup) Run(ctx context.Context) error {
+	now := r.now().UTC()
+	for _, rollup := range rollups {
+		d, _ := model.IntervalDuration(rollup.Interval)
+		if err := r.build(ctx, rollup.Interval, rollup.Source, now.Truncate(d).Add(-d), now, now); err != nil {
+			return err
+		}
+	}
+	return r.prune(ctx, now)
+}
+
+// Backfill builds candles of all intervals from the last stored candle up to now
+// and deletes snapshots and candles older than their retentions.
+// Candles are not built before the snapshot retention since their sources are deleted.
+func (r *Rollup) Backfill(ctx context.Context) error {
+	logger := logging.FromContext(ctx)
+	now := r.now().UTC()
+	for _, rollup := range rollups {
+		d, _ := model.IntervalDuration(rollup.Interval)
+		from := now.Add(-r.retention)
+		last, err := r.tokenDB.FindLastCandle(ctx, rollup.Interval)
+		if err != nil && !database.IsRecordNotFoundErr(err) {
+			return err
+		}
+		if last != nil && last.OpenTime.After(from) {
+			from = last.OpenTime
+		}
+		from = from.UTC().Truncate(d)
+		logger.Infow("token.rollup backfilling candles", "interval", rollup.Interval, "from", from)
+
+		// builds candles by steps so that the sources are not loaded at once
+		step := backfillStep
+		if d > step {
+			step = d
+		}
+		for start := from; start.Before(now); start = start.Add(step) {
+			end := start.Add(step)
+			if end.After(now) {
+				end = now
+			}
+			if err := r.build(ctx, rollup.Interval, rollup.Source, start, end, now); err != nil {
+				return err
+			}
+		}
+	}
+	return r.prune(ctx, now)
+}
+
+// build saves candles of given interval opened in [from, to) built from given source.
+// Candles are built from price snapshots if source is empty.
+func (r *Rollup) build(ctx context.Context, interval, source string, from, to, now time.Time) error {
+	logger := logging.FromContext(ctx)
+	d, _ := model.IntervalDuration(interval)
+
+	var points []*model.Candle
+	if source == "" {
+		snapshots, err := r.tokenDB.FindPriceSnapshots(ctx, from, to)
+		if err != nil {
+			return err
+		}
+		for _, s := range snapshots {
+			points = append(points, snapshotCandle(s))
+		}
+	} else {
+		candles, err := r.tokenDB.FindCandles(ctx, tokenDB.FindCandlesCriteria{
+			Interval: source,
+			From:     from,
+			To:       to,
+		})
+		if err != nil {
+			return err
+		}
+		points = candles
+	}
+
+	candles := Aggregate(interval, d, points)
+	for _, c := range candles {
+		c.UpdatedAt = now
+	}
+	if err := r.tokenDB.SaveCandles(ctx, candles); err != nil {
+		return err
+	}
+	logger.Debugw("token.rollup built candles", "interval", interval, "count", len(candles))
+	return nil
+}
+
+// prune deletes snapshots and candles older than their retentions
+func (r *Rollup) prune(ctx context.Context, now time.Time) error {
+	logger := logging.FromContext(ctx)
+	deleted, err := r.tokenDB.DeletePriceSnapshotsBefore(ctx, now.Add(-r.retention))
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		logger.Infow("token.rollup deleted price snapshots", "count", deleted)
+	}
+
+	for _, rollup := range rollups {
+		retention, ok := r.candleRetentions[rollup.Interval]
+		if !ok {
+			continue
+		}
+		deleted, err := r.tokenDB.DeleteCandlesBefore(ctx, rollup.Interval, now.Add(-retention))
+		if err != nil {
+			return err
+		}
+		if deleted > 0 {
+			logger.Infow("token.rollup deleted candles", "interval", rollup.Interval, "count", deleted)
+		}
+	}
+	return nil
+}
+
+// Aggregate merges given candles into candles of the interval with duration d.
+// A snapshot is merged as a candle whose open, high, low and close are the same price.
+func Aggregate(interval string, d time.Duration, points []*model.Candle) []*model.Candle {
+	sorted := make([]*model.Candle, len(points))
+	copy(sorted, points)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].TokenID != sorted[j].TokenID {
+			return sorted[i].TokenID < sorted[j].TokenID
+		}
+		return sorted[i].OpenTime.Before(sorted[j].OpenTime)
+	})
+
+	var ret []*model.Candle
+	var last *model.Candle
+	for _, p := range sorted {
+		openTime := p.OpenTime.UTC().Truncate(d)
+		if last == nil || last.TokenID != p.TokenID || !last.OpenTime.Equal(openTime) {
+			last = &model.Candle{
+				TokenID:  p.TokenID,
+				Interval: interval,
+				OpenTime: openTime,
+				Open:     p.Open,
+				High:     p.High,
+				Low:      p.Low,
+			}
+			ret = append(ret, last)
+		}
+		if p.High > last.High {
+			last.High = p.High
+		}
+		if p.Low < last.Low {
+			last.Low = p.Low
+		}
+		last.Close = p.Close
+		last.Samples += p.Samples
+	}
+	return ret
+}
+
+func snapshotCandle(s *model.PriceSnapshot) *model.Candle {
+	return &model.Candle{
+		TokenID:  s.TokenID,
+		OpenTime: s.ObservedAt,
+		Open:     s.PriceUSD,
+		High:     s.PriceUSD,
+		Low:      s.PriceUSD,
+		Close:    s.PriceUSD,
+		Samples:  1,
+	}
+}
+
+// StartRollup runs given rollup periodically with the application
+// and waits for the running rollup on shutdown
+func StartRollup(lc fx.Lifecycle, r *Rollup) {
+	logger := logging.DefaultLogger()
+	c := cron.New(cron.WithSeconds(), cron.WithChain(cron.SkipIfStillRunning(cron.DefaultLogger)))
+	c.AddFunc(fmt.Sprintf("@every %s", r.interval), func() {
+		ctx := context.Background()
+		if !r.lease.leading(ctx) {
+			// another replica may run the rollup until this replica acquires the lease again
+			r.caughtUp = false
+			return
+		}
+		run := r.Run
+		if !r.caughtUp {
+			run = r.Backfill
+		}
+		if err := run(ctx); err != nil {
+			logger.Errorw("token.rollup failed to build candles", "err", err)
+			return
+		}
+		r.caughtUp = true
+	})
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			c.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			select {
+			case <-c.Stop().Done():
+				r.lease.release()
+				return nil
+			case <-ctx.Done():
+				logger.Warnw("token.rollup is still running at shutdown", "err", ctx.Err())
+				return ctx.Err()
+			}
+		},
+	})
+}
+
+func NewRollup(cfg *config.Config, tokenDB tokenDB.TokenDB, leases Leases) (*Rollup, error) {
+	retention := time.Duration(cfg.TokenConfig.History.RetentionHours) * time.Hour
+	candleRetentions := make(map[string]time.Duration)
+	for interval, hours := range cfg.TokenConfig.History.CandleRetentionHours {
+		if _, ok := model.IntervalDuration(interval); !ok {
+			return nil, fmt.Errorf("token.history.candleRetentionHours has unknown interval: %s", interval)
+		}
+		if hours == 0 {
+			continue
+		}
+		// candles are rebuilt from the sources kept as long as the snapshots
+		if hours < cfg.TokenConfig.History.RetentionHours {
+			return nil, fmt.Errorf("token.history.candleRetentionHours.%s must be 0 or at least retentionHours: %d < %d",
+				interval, hours, cfg.TokenConfig.History.RetentionHours)
+		}
+		candleRetentions[interval] = time.Duration(hours) * time.Hour
+	}
+
+	interval := time.Duration(cfg.TokenConfig.History.RollupIntervalSecs) * time.Second
+	return &Rollup{
+		tokenDB: tokenDB,
+		// the lease outlives a run and is renewed by the next one
+		lease:            newLeaseGuard(leases, rollupLease, 2*interval),
+		interval:         interval,
+		retention:        retention,
+		candleRetentions: candleRetentions,
+		now:              time.Now,
+	}, nil
+}
@@ -0,0 +1,208 @@
+package token
+
+import (
+	"context"
+	"kek-backend/internal/config"
+	coreDB "kek-backend/internal/database"
+	tokenDB "kek-backend/internal/token/database"
+	"kek-backend/internal/token/database/mocks"
+	"kek-backend/internal/token/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAggregate(t *testing.T) {
+	base := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	snapshot := func(tokenID string, offset time.Duration, price float64) *model.Candle {
+		return snapshotCandle(&model.PriceSnapshot{TokenID: tokenID, PriceUSD: price, ObservedAt: base.Add(offset)})
+	}
+	points := []*model.Candle{
+		snapshot("0xb", 10*time.Second, 5),
+		snapshot("0xa", 50*time.Second, 1.5),
+		snapshot("0xa", 5*time.Second, 1),
+		snapshot("0xa", 20*time.Second, 3),
+		snapshot("0xa", 30*time.Second, 0.5),
+		snapshot("0xa", 65*time.Second, 2),
+	}
+
+	candles := Aggregate(model.Interval1m, time.Minute, points)
+
+	assert.Len(t, candles, 3)
+	assert.Equal(t, model.Candle{
+		TokenID: "0xa", Interval: model.Interval1m, OpenTime: base,
+		Open: 1, High: 3, Low: 0.5, Close: 1.5, Samples: 4,
+	}, *candles[0])
+	assert.Equal(t, model.Candle{
+		TokenID: "0xa", Interval: model.Interval1m, OpenTime: base.Add(time.Minute),
+		Open: 2, High: 2, Low: 2, Close: 2, Samples: 1,
+	}, *candles[1])
+	assert.Equal(t, "0xb", candles[2].TokenID)
+	assert.Equal(t, base, candles[2].OpenTime)
+}
+
+func TestAggregate_MergeCandles(t *testing.T) {
+	base := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	points := []*model.Candle{
+		{TokenID: "0xa", Interval: model.Interval1m, OpenTime: base, Open: 1, High: 4, Low: 1, Close: 2, Samples: 12},
+		{TokenID: "0xa", Interval: model.Interval1m, OpenTime: base.Add(time.Minute), Open: 2, High: 3, Low: 0.5, Close: 3, Samples: 12},
+	}
+
+	candles := Aggregate(model.Interval1h, time.Hour, points)
+
+	assert.Len(t, candles, 1)
+	assert.Equal(t, model.Candle{
+		TokenID: "0xa", Interval: model.Interval1h, OpenTime: base,
+		Open: 1, High: 4, Low: 0.5, Close: 3, Samples: 24,
+	}, *candles[0])
+}
+
+func TestRollup_Run(t *testing.T) {
+	now := time.Date(2021, 10, 1, 12, 30, 10, 0, time.UTC)
+	cfg := &config.Config{}
+	cfg.TokenConfig.History.RetentionHours = 48
+
+	db := &mocks.TokenDB{}
+	db.On("FindPriceSnapshots", mock.Anything, now.Truncate(time.Minute).Add(-time.Minute), now).Return([]*model.PriceSnapshot{
+		{TokenID: "0xa", PriceUSD: 1, ObservedAt: now.Add(-5 * time.Second)},
+		{TokenID: "0xa", PriceUSD: 2, ObservedAt: now.Add(-1 * time.Second)},
+	}, nil)
+	minute := &model.Candle{TokenID: "0xa", Interval: model.Interval1m, OpenTime: now.Truncate(time.Minute), Open: 1, High: 2, Low: 1, Close: 2, Samples: 2}
+	db.On("FindCandles", mock.Anything, mock.MatchedBy(func(criteria tokenDB.FindCandlesCriteria) bool {
+		return criteria.Interval == model.Interval1m && criteria.From.Equal(now.Truncate(time.Hour).Add(-time.Hour))
+	})).Return([]*model.Candle{minute}, nil)
+	db.On("FindCandles", mock.Anything, mock.MatchedBy(func(criteria tokenDB.FindCandlesCriteria) bool {
+		return criteria.Interval == model.Interval1h && criteria.From.Equal(now.Truncate(24*time.Hour).Add(-24*time.Hour))
+	})).Return([]*model.Candle{}, nil)
+	db.On("SaveCandles", mock.Anything, mock.Anything).Return(nil)
+	db.On("DeletePriceSnapshotsBefore", mock.Anything, now.Add(-48*time.Hour)).Return(int64(3), nil)
+
+	r, err := NewRollup(cfg, db, nil)
+	assert.NoError(t, err)
+	r.now = func() time.Time { return now }
+
+	err = r.Run(context.Background())
+
+	assert.NoError(t, err)
+	db.AssertCalled(t, "SaveCandles", mock.Anything, mock.MatchedBy(func(candles []*model.Candle) bool {
+		return len(candles) == 1 && candles[0].Interval == model.Interval1m && candles[0].Close == 2 && candles[0].Samples == 2
+	}))
+	db.AssertCalled(t, "SaveCandles", mock.Anything, mock.MatchedBy(func(candles []*model.Candle) bool {
+		return len(candles) == 1 && candles[0].Interval == model.Interval1h && candles[0].OpenTime.Equal(now.Truncate(time.Hour))
+	}))
+	db.AssertCalled(t, "DeletePriceSnapshotsBefore", mock.Anything, now.Add(-48*time.Hour))
+}
+
+func TestRollup_RunDeleteCandles(t *testing.T) {
+	now := time.Date(2021, 10, 1, 12, 30, 10, 0, time.UTC)
+	cfg := &config.Config{}
+	cfg.TokenConfig.History.RetentionHours = 48
+	cfg.TokenConfig.History.CandleRetentionHours = map[string]int{
+		model.Interval1m: 72,
+		model.Interval1h: 2160,
+		model.Interval1d: 0,
+	}
+
+	db := &mocks.TokenDB{}
+	db.On("FindPriceSnapshots", mock.Anything, mock.Anything, mock.Anything).Return([]*model.PriceSnapshot{}, nil)
+	db.On("FindCandles", mock.Anything, mock.Anything).Return([]*model.Candle{}, nil)
+	db.On("SaveCandles", mock.Anything, mock.Anything).Return(nil)
+	db.On("DeletePriceSnapshotsBefore", mock.Anything, mock.Anything).Return(int64(0), nil)
+	db.On("DeleteCandlesBefore", mock.Anything, mock.Anything, mock.Anything).Return(int64(10), nil)
+
+	r, err := NewRollup(cfg, db, nil)
+	assert.NoError(t, err)
+	r.now = func() time.Time { return now }
+
+	err = r.Run(context.Background())
+
+	assert.NoError(t, err)
+	db.AssertCalled(t, "DeleteCandlesBefore", mock.Anything, model.Interval1m, now.Add(-72*time.Hour))
+	db.AssertCalled(t, "DeleteCandlesBefore", mock.Anything, model.Interval1h, now.Add(-2160*time.Hour))
+	db.AssertNotCalled(t, "DeleteCandlesBefore", mock.Anything, model.Interval1d, mock.Anything)
+}
+
+func TestRollup_Backfill(t *testing.T) {
+	now := time.Date(2021, 10, 1, 12, 30, 10, 0, time.UTC)
+	cfg := &config.Config{}
+	cfg.TokenConfig.History.RetentionHours = 48
+
+	db := &mocks.TokenDB{}
+	// 1m candles are missed for 2 hours, 1h candles are never built
+	// and 1d candles are missed longer than the snapshot retention
+	lastMinute := &model.Candle{TokenID: "0xa", Interval: model.Interval1m, OpenTime: now.Truncate(time.Minute).Add(-2 * time.Hour)}
+	lastDay := &model.Candle{TokenID: "0xa", Interval: model.Interval1d, OpenTime: time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)}
+	db.On("FindLastCandle", mock.Anything, model.Interval1m).Return(lastMinute, nil)
+	db.On("FindLastCandle", mock.Anything, model.Interval1h).Return(nil, coreDB.ErrNotFound)
+	db.On("FindLastCandle", mock.Anything, model.Interval1d).Return(lastDay, nil)
+	db.On("FindPriceSnapshots", mock.Anything, lastMinute.OpenTime, now).Return([]*model.PriceSnapshot{
+		{TokenID: "0xa", PriceUSD: 1, ObservedAt: lastMinute.OpenTime.Add(time.Second)},
+		{TokenID: "0xa", PriceUSD: 2, ObservedAt: now.Add(-time.Second)},
+	}, nil)
+	db.On("FindCandles", mock.Anything, mock.Anything).Return([]*model.Candle{}, nil)
+	db.On("SaveCandles", mock.Anything, mock.Anything).Return(nil)
+	db.On("DeletePriceSnapshotsBefore", mock.Anything, now.Add(-48*time.Hour)).Return(int64(0), nil)
+
+	r, err := NewRollup(cfg, db, nil)
+	assert.NoError(t, err)
+	r.now = func() time.Time { return now }
+
+	err = r.Backfill(context.Background())
+
+	assert.NoError(t, err)
+	db.AssertNumberOfCalls(t, "FindPriceSnapshots", 1)
+	db.AssertCalled(t, "SaveCandles", mock.Anything, mock.MatchedBy(func(candles []*model.Candle) bool {
+		return len(candles) == 2 && candles[0].OpenTime.Equal(lastMinute.OpenTime) && candles[1].OpenTime.Equal(now.Truncate(time.Minute))
+	}))
+	// 1h candles are built by 6 hours from the snapshot retention
+	hourFrom := now.Add(-48 * time.Hour).Truncate(time.Hour)
+	for from := hourFrom; from.Before(now); from = from.Add(6 * time.Hour) {
+		from := from
+		db.AssertCalled(t, "FindCandles", mock.Anything, mock.MatchedBy(func(criteria tokenDB.FindCandlesCriteria) bool {
+			return criteria.Interval == model.Interval1m && criteria.From.Equal(from)
+		}))
+	}
+	// 1d candles are built by a day from the snapshot retention
+	dayFrom := now.Add(-48 * time.Hour).Truncate(24 * time.Hour)
+	for from := dayFrom; from.Before(now); from = from.Add(24 * time.Hour) {
+		from := from
+		db.AssertCalled(t, "FindCandles", mock.Anything, mock.MatchedBy(func(criteria tokenDB.FindCandlesCriteria) bool {
+			return criteria.Interval == model.Interval1h && criteria.From.Equal(from)
+		}))
+	}
+	db.AssertNumberOfCalls(t, "FindCandles", 9+3)
+	db.AssertCalled(t, "DeletePriceSnapshotsBefore", mock.Anything, now.Add(-48*time.Hour))
+}
+
+func TestNewRollup_FailIfInvalidCandleRetention(t *testing.T) {
+	cases := []struct {
+		Name       string
+		Retentions map[string]int
+		Err        string
+	}{
+		{
+			Name:       "unknown interval",
+			Retentions: map[string]int{"5m": 72},
+			Err:        "token.history.candleRetentionHours has unknown interval: 5m",
+		}, {
+			Name:       "shorter than snapshots",
+			Retentions: map[string]int{model.Interval1m: 24},
+			Err:        "token.history.candleRetentionHours.1m must be 0 or at least retentionHours: 24 < 48",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.TokenConfig.History.RetentionHours = 48
+			cfg.TokenConfig.History.CandleRetentionHours = tc.Retentions
+
+			r, err := NewRollup(cfg, &mocks.TokenDB{}, nil)
+
+			assert.Nil(t, r)
+			assert.EqualError(t, err, tc.Err)
+		})
+	}
+}
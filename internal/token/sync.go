@@ -0,0 +1,121 @@
+package token
+
+import (
+	"context"
+	"fmt"
+	"kek-backend/internal/config"
+	tokenDB "kek-backend/internal/token/database"
+	"kek-backend/internal/uniswap"
+	"kek-backend/pkg/logging"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.uber.org/fx"
+)
+
+// syncPageSize is the number of tokens fetched from the subgraph at once
+const syncPageSize = 100
+
+// Sync saves metadata of the most traded tokens in the subgraph to the tokens table
+// so that tokens are searched before any alert references them.
+// Syncs are started only on the replica holding the sync lease.
+type Sync struct {
+	tokenDB  tokenDB.TokenDB
+	lease    *leaseGuard
+	client   *uniswap.Client
+	interval time.Duration
+	limit    int
+}
+
+// Run saves up to limit tokens ordered by trade volume page by page and returns the number of saved tokens.
+// Tokens saved before an error are kept.
+func (s *Sync) Run(ctx context.Context) (int, error) {
+	logger := logging.FromContext(ctx)
+	saved := 0
+	for skip := 0; skip < s.limit; skip += syncPageSize {
+		first := syncPageSize
+		if s.limit-skip < first {
+			first = s.limit - skip
+		}
+		tokens, err := s.client.TopTokens(ctx, first, skip)
+		if err != nil {
+			return saved, err
+		}
+		for i := range tokens {
+			id, err := uniswap.NormalizeAddress(tokens[i].Id)
+			if err != nil {
+				logger.Warnw("token.sync invalid token address", "id", tokens[i].Id, "err", err)
+				continue
+			}
+			if err := s.tokenDB.SaveToken(ctx, newToken(id, &tokens[i])); err != nil {
+				return saved, err
+			}
+			saved++
+		}
+		if len(tokens) < first {
+			break
+		}
+	}
+	return saved, nil
+}
+
+// StartSync runs given sync on start and periodically with the application
+// and waits for the running sync on shutdown
+func StartSync(lc fx.Lifecycle, s *Sync) {
+	logger := logging.DefaultLogger()
+	run := func() {
+		ctx := context.Background()
+		if !s.lease.leading(ctx) {
+			return
+		}
+		saved, err := s.Run(ctx)
+		if err != nil {
+			logger.Errorw("token.sync failed to sync tokens", "saved", saved, "err", err)
+			return
+		}
+		logger.Infow("token.sync synced tokens", "saved", saved)
+	}
+	c := cron.New(cron.WithSeconds(), cron.WithChain(cron.SkipIfStillRunning(cron.DefaultLogger)))
+	id, _ := c.AddFunc(fmt.Sprintf("@every %s", s.interval), run)
+	first := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			c.Start()
+			// the first sync runs through the wrapped job so that ticks are skipped while it is running
+			go func() {
+				defer close(first)
+				c.Entry(id).WrappedJob.Run()
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			stopped := c.Stop().Done()
+			select {
+			case <-first:
+			case <-ctx.Done():
+				logger.Warnw("token.sync is still running at shutdown", "err", ctx.Err())
+				return ctx.Err()
+			}
+			select {
+			case <-stopped:
+				s.lease.release()
+				return nil
+			case <-ctx.Done():
+				logger.Warnw("token.sync is still running at shutdown", "err", ctx.Err())
+				return ctx.Err()
+			}
+		},
+	})
+}
+
+func NewSync(cfg *config.Config, tokenDB tokenDB.TokenDB, client *uniswap.Client, leases Leases) *Sync {
+	interval := time.Duration(cfg.TokenConfig.Sync.IntervalSecs) * time.Second
+	return &Sync{
+		tokenDB: tokenDB,
+		// the lease outlives a sync and is renewed by the next one
+		lease:    newLeaseGuard(leases, syncLease, 2*interval),
+		client:   client,
+		interval: interval,
+		limit:    cfg.TokenConfig.Sync.Limit,
+	}
+}
@@ -0,0 +1,50 @@
+package token
+
+import (
+	"context"
+	"encoding/json"
+	"kek-backend/internal/config"
+	"kek-backend/internal/token/database/mocks"
+	"kek-backend/internal/token/model"
+	"kek-backend/internal/uniswap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSync_Run(t *testing.T) {
+	var requests []map[string]interface{}
+	subgraph := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req uniswap.GraphQLRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req.Variables)
+		_, _ = w.Write([]byte(`{"data":{"tokens":[
+			{"id":"0xA0B86991C6218B36C1D19D4A2E9EB0CE3606EB48","name":"USD Coin","symbol":"USDC","decimals":"6"},
+			{"id":"invalid","name":"Invalid","symbol":"INV","decimals":"18"}
+		]}}`))
+	}))
+	defer subgraph.Close()
+	cfg := &config.Config{}
+	cfg.UniswapConfig.Endpoint = subgraph.URL
+	cfg.UniswapConfig.TimeoutSecs = 5
+	cfg.TokenConfig.Sync.Limit = 2
+
+	db := &mocks.TokenDB{}
+	db.On("SaveToken", mock.Anything, mock.Anything).Return(nil)
+	s := NewSync(cfg, db, uniswap.NewClient(cfg), nil)
+
+	saved, err := s.Run(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, saved)
+	assert.Len(t, requests, 1)
+	assert.EqualValues(t, 2, requests[0]["first"])
+	assert.EqualValues(t, 0, requests[0]["skip"])
+	db.AssertCalled(t, "SaveToken", mock.Anything, mock.MatchedBy(func(token *model.Token) bool {
+		return token.ID == "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48" && token.Symbol == "USDC" && token.Decimals == 6
+	}))
+	db.AssertNumberOfCalls(t, "SaveToken", 1)
+}
@@ -0,0 +1,23 @@
+package uniswap
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidAddress is returned if an address is not a 20 bytes hex string with 0x prefix
+var ErrInvalidAddress = errors.New("invalid address")
+
+var addressPattern = regexp.MustCompile(`^0x[0-9a-f]{40}$`)
+
+// NormalizeAddress returns given address in lower case without surrounding spaces.
+// ErrInvalidAddress is returned if the address is not 0x followed by 40 hex characters
+func NormalizeAddress(address string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(address))
+	if !addressPattern.MatchString(normalized) {
+		return "", ErrInvalidAddress
+	}
+	return normalized, nil
+}
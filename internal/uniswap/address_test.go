@@ -0,0 +1,52 @@
+package uniswap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeAddress(t *testing.T) {
+	cases := []struct {
+		Name    string
+		Address string
+		// expected
+		Normalized string
+		Err        error
+	}{
+		{
+			Name:       "Lower case",
+			Address:    "0x6b175474e89094c44da98b954eedeac495271d0f",
+			Normalized: "0x6b175474e89094c44da98b954eedeac495271d0f",
+		}, {
+			Name:       "Checksum case with spaces",
+			Address:    " 0x6B175474E89094C44Da98b954EedeAC495271d0F\n",
+			Normalized: "0x6b175474e89094c44da98b954eedeac495271d0f",
+		}, {
+			Name:    "Without prefix",
+			Address: "6b175474e89094c44da98b954eedeac495271d0f",
+			Err:     ErrInvalidAddress,
+		}, {
+			Name:    "Too short",
+			Address: "0x6b175474e89094c44da98b954eedeac495271d0",
+			Err:     ErrInvalidAddress,
+		}, {
+			Name:    "Not hex",
+			Address: "0x6b175474e89094c44da98b954eedeac495271d0g",
+			Err:     ErrInvalidAddress,
+		}, {
+			Name:    "Injection",
+			Address: `0x6b175474e89094c44da98b954eedeac495271d0f" }) { id`,
+			Err:     ErrInvalidAddress,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			normalized, err := NormalizeAddress(tc.Address)
+
+			assert.Equal(t, tc.Err, err)
+			assert.Equal(t, tc.Normalized, normalized)
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package uniswap
+
+import (
+	"context"
+	"fmt"
+	"kek-backend/internal/config"
+	"kek-backend/internal/metric"
+	"kek-backend/pkg/logging"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ethPriceKey    = "eth"
+	tokenKeyPrefix = "token:"
+	dayKeyPrefix   = "tokenDayDatas:"
+	cacheName      = "uniswap_price"
+)
+
+type cacheEntry struct {
+	value     interface{}
+	fetchedAt time.Time
+}
+
+// call is an in-flight fetch shared by concurrent lookups of the same key
+type call struct {
+	done chan struct{}
+	val  interface{}
+	err  error
+}
+
+// PriceCache caches the ETH price and tokens fetched by a Client.
+//
+// A value younger than ttl is returned as is. A value older than ttl but younger
+// than ttl + staleTTL is returned while being refreshed in background. Otherwise
+// the value is fetched before returning. Concurrent fetches of the same key are
+// de-duplicated so that the subgraph is requested once. Values older than
+// ttl + staleTTL are evicted while caching new values.
+type PriceCache struct {
+	client       *Client
+	mp           *metric.MetricsProvider
+	ttl          time.Duration
+	staleTTL     time.Duration
+	fetchTimeout time.Duration
+	now          func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]*cacheEntry
+	calls     map[string]*call
+	lastSweep time.Time
+}
+
+// EthPrice returns the USD price of ETH
+func (c *PriceCache) EthPrice(ctx context.Context) (float64, error) {
+	v, err := c.get(ctx, ethPriceKey, func(ctx context.Context) (interface{}, error) {
+		price, err := c.client.EthPrice(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.set(ethPriceKey, price)
+		return price, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return v.(float64), nil
+}
+
+// Token returns a token with given address.
+// ErrInvalidAddress is returned if the address is invalid and ErrNotFound is returned if the token does not exist
+func (c *PriceCache) Token(ctx context.Context, address string) (*Token, error) {
+	id, err := NormalizeAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	key := tokenKeyPrefix + id
+	v, err := c.get(ctx, key, func(ctx context.Context) (interface{}, error) {
+		token, err := c.client.Token(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		c.set(key, token)
+		return token, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*Token), nil
+}
+
+// Tokens returns tokens with given addresses keyed by normalized address.
+// Only tokens which are not cached are fetched from the client in a batch.
+// See Client.Tokens for errors and missing tokens.
+func (c *PriceCache) Tokens(ctx context.Context, addresses []string) (map[string]*Token, error) {
+	ret := make(map[string]*Token, len(addresses))
+	var missing, stale []string
+	now := c.now()
+	c.mu.Lock()
+	for _, address := range addresses {
+		id, err := NormalizeAddress(address)
+		if err != nil {
+			c.mu.Unlock()
+			return nil, err
+		}
+		if _, ok := ret[id]; ok {
+			continue
+		}
+		e, ok := c.entries[tokenKeyPrefix+id]
+		switch {
+		case ok && now.Sub(e.fetchedAt) < c.ttl:
+			ret[id] = e.value.(*Token)
+			c.mp.RecordCacheHit(cacheName, false)
+		case ok && now.Sub(e.fetchedAt) < c.ttl+c.staleTTL:
+			ret[id] = e.value.(*Token)
+			stale = append(stale, id)
+			c.mp.RecordCacheHit(cacheName, true)
+		default:
+			missing = append(missing, id)
+			c.mp.RecordCacheMiss(cacheName)
+		}
+	}
+	c.mu.Unlock()
+
+	if len(stale) != 0 {
+		go func() {
+			if _, err := c.fetchTokens(context.Background(), stale); err != nil {
+				logging.DefaultLogger().Warnw("uniswap.cache failed to refresh tokens", "count", len(stale), "err", err)
+			}
+		}()
+	}
+	if len(missing) == 0 {
+		return ret, nil
+	}
+	fetched, err := c.fetchTokens(ctx, missing)
+	for id, token := range fetched {
+		ret[id] = token
+	}
+	return ret, err
+}
+
+// TokenDayDatas returns daily data of tokens with given addresses for the latest given days.
+// The data are cached as a whole for the set of addresses.
+// See Client.TokenDayDatas for the order and errors.
+func (c *PriceCache) TokenDayDatas(ctx context.Context, addresses []string, days int) (map[string][]TokenDayData, error) {
+	ids := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		id, err := NormalizeAddress(address)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	key := fmt.Sprintf("%s%d:%s", dayKeyPrefix, days, strings.Join(ids, ","))
+	v, err := c.get(ctx, key, func(ctx context.Context) (interface{}, error) {
+		dayDatas, err := c.client.TokenDayDatas(ctx, ids, days, c.now())
+		if err != nil {
+			return nil, err
+		}
+		c.set(key, dayDatas)
+		return dayDatas, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(map[string][]TokenDayData), nil
+}
+
+// fetchTokens fetches tokens with given normalized addresses and caches them
+func (c *PriceCache) fetchTokens(ctx context.Context, ids []string) (map[string]*Token, error) {
+	sorted := append([]string{}, ids...)
+	sort.Strings(sorted)
+	v, err := c.do(ctx, "tokens:"+strings.Join(sorted, ","), func(ctx context.Context) (interface{}, error) {
+		tokens, err := c.client.Tokens(ctx, sorted)
+		for id, token := range tokens {
+			c.set(tokenKeyPrefix+id, token)
+		}
+		return tokens, err
+	})
+	tokens, _ := v.(map[string]*Token)
+	return tokens, err
+}
+
+// get returns a cached value of given key and fetches it with given function if needed
+func (c *PriceCache) get(ctx context.Context, key string, fetch func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	now := c.now()
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+
+	if ok && now.Sub(e.fetchedAt) < c.ttl {
+		c.mp.RecordCacheHit(cacheName, false)
+		return e.value, nil
+	}
+	if ok && now.Sub(e.fetchedAt) < c.ttl+c.staleTTL {
+		c.mp.RecordCacheHit(cacheName, true)
+		go func() {
+			if _, err := c.do(context.Background(), key, fetch); err != nil {
+				logging.DefaultLogger().Warnw("uniswap.cache failed to refresh", "key", key, "err", err)
+			}
+		}()
+		return e.value, nil
+	}
+	c.mp.RecordCacheMiss(cacheName)
+	return c.do(ctx, key, fetch)
+}
+
+// do calls given fetch function unless a call with given key is in flight,
+// in which case the result of the in-flight call is returned.
+// The fetch runs on a context detached from given one bounded by the client timeout so that
+// a canceled caller does not fail the other callers waiting for the same key.
+// Each caller stops waiting when its own context is done.
+func (c *PriceCache) do(ctx context.Context, key string, fetch func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	c.mu.Lock()
+	cl, ok := c.calls[key]
+	if !ok {
+		cl = &call{done: make(chan struct{})}
+		c.calls[key] = cl
+		go func() {
+			fetchCtx, cancel := c.detach(ctx)
+			defer cancel()
+			cl.val, cl.err = fetch(fetchCtx)
+
+			c.mu.Lock()
+			delete(c.calls, key)
+			c.mu.Unlock()
+			close(cl.done)
+		}()
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-cl.done:
+		return cl.val, cl.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// detach returns a context keeping the logger of given context but not its cancellation.
+// The context is bounded by fetchTimeout if configured
+func (c *PriceCache) detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached := logging.WithLogger(context.Background(), logging.FromContext(ctx))
+	if c.fetchTimeout <= 0 {
+		return context.WithCancel(detached)
+	}
+	return context.WithTimeout(detached, c.fetchTimeout)
+}
+
+// set caches given value with given key and evicts expired values
+// at most once per ttl + staleTTL
+func (c *PriceCache) set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	c.entries[key] = &cacheEntry{value: value, fetchedAt: now}
+
+	expiry := c.ttl + c.staleTTL
+	if now.Sub(c.lastSweep) < expiry {
+		return
+	}
+	c.lastSweep = now
+	for k, e := range c.entries {
+		if now.Sub(e.fetchedAt) >= expiry {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// NewPriceCache creates a new price cache in front of given client
+func NewPriceCache(cfg *config.Config, client *Client, mp *metric.MetricsProvider) *PriceCache {
+	return &PriceCache{
+		client:       client,
+		mp:           mp,
+		ttl:          time.Duration(cfg.UniswapConfig.Cache.TTLSecs) * time.Second,
+		staleTTL:     time.Duration(cfg.UniswapConfig.Cache.StaleSecs) * time.Second,
+		fetchTimeout: client.Timeout(),
+		now:          time.Now,
+		entries:      make(map[string]*cacheEntry),
+		calls:        make(map[string]*call),
+	}
+}
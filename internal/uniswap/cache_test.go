@@ -0,0 +1,245 @@
+package uniswap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"kek-backend/internal/config"
+	"kek-backend/internal/metric"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// metrics are registered globally so that the provider is created once
+var testMetricsProvider = metric.NewMetricsProvider(&config.Config{})
+
+// subgraphStub is a stand-in subgraph which serves an ETH price increasing on each request
+// and tokens with given addresses
+type subgraphStub struct {
+	server  *httptest.Server
+	calls   int32
+	release chan struct{}
+
+	mu  sync.Mutex
+	ids [][]interface{}
+}
+
+func newSubgraphStub() *subgraphStub {
+	stub := &subgraphStub{}
+	stub.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&stub.calls, 1)
+		if stub.release != nil {
+			<-stub.release
+		}
+		var req GraphQLRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if ids, ok := req.Variables["ids"].([]interface{}); ok {
+			stub.mu.Lock()
+			stub.ids = append(stub.ids, ids)
+			stub.mu.Unlock()
+			var tokens Tokens
+			for _, id := range ids {
+				tokens.Tokens = append(tokens.Tokens, Token{Id: id.(string), DerivedETH: "1"})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": tokens})
+			return
+		}
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"data":{"bundles":[{"ethPrice":"%d"}]}}`, n)))
+	}))
+	return stub
+}
+
+func (s *subgraphStub) Calls() int32 {
+	return atomic.LoadInt32(&s.calls)
+}
+
+func newTestPriceCache(endpoint string, now *time.Time) *PriceCache {
+	cfg := config.Config{}
+	cfg.UniswapConfig.Cache.TTLSecs = 5
+	cfg.UniswapConfig.Cache.StaleSecs = 10
+	cache := NewPriceCache(&cfg, newTestClient(endpoint, 0), testMetricsProvider)
+	var mu sync.Mutex
+	cache.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return *now
+	}
+	return cache
+}
+
+func TestPriceCache_EthPrice(t *testing.T) {
+	// given
+	stub := newSubgraphStub()
+	defer stub.server.Close()
+	now := time.Now()
+	cache := newTestPriceCache(stub.server.URL, &now)
+
+	// when : miss and fresh hit
+	price1, err1 := cache.EthPrice(context.Background())
+	price2, err2 := cache.EthPrice(context.Background())
+
+	// then
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.Equal(t, float64(1), price1)
+	assert.Equal(t, float64(1), price2)
+	assert.Equal(t, int32(1), stub.Calls())
+}
+
+func TestPriceCache_EthPriceStaleWhileRevalidate(t *testing.T) {
+	// given
+	stub := newSubgraphStub()
+	defer stub.server.Close()
+	now := time.Now()
+	cache := newTestPriceCache(stub.server.URL, &now)
+	_, _ = cache.EthPrice(context.Background())
+
+	// when : stale
+	now = now.Add(6 * time.Second)
+	price, err := cache.EthPrice(context.Background())
+
+	// then : stale value is returned and refreshed in background
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), price)
+	assert.Eventually(t, func() bool {
+		price, _ := cache.EthPrice(context.Background())
+		return price == 2
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestPriceCache_EthPriceExpired(t *testing.T) {
+	// given
+	stub := newSubgraphStub()
+	defer stub.server.Close()
+	now := time.Now()
+	cache := newTestPriceCache(stub.server.URL, &now)
+	_, _ = cache.EthPrice(context.Background())
+
+	// when : older than ttl + stale ttl
+	now = now.Add(16 * time.Second)
+	price, err := cache.EthPrice(context.Background())
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, float64(2), price)
+	assert.Equal(t, int32(2), stub.Calls())
+}
+
+func TestPriceCache_EvictExpired(t *testing.T) {
+	// given
+	stub := newSubgraphStub()
+	defer stub.server.Close()
+	now := time.Now()
+	cache := newTestPriceCache(stub.server.URL, &now)
+	_, err := cache.Tokens(context.Background(), []string{
+		"0x6b175474e89094c44da98b954eedeac495271d0f",
+		"0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48",
+	})
+	assert.NoError(t, err)
+
+	// when : tokens are older than ttl + stale ttl
+	now = now.Add(16 * time.Second)
+	_, err = cache.EthPrice(context.Background())
+
+	// then
+	assert.NoError(t, err)
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	assert.Len(t, cache.entries, 1)
+	assert.Contains(t, cache.entries, ethPriceKey)
+}
+
+func TestPriceCache_SingleFlight(t *testing.T) {
+	// given
+	stub := newSubgraphStub()
+	stub.release = make(chan struct{})
+	defer stub.server.Close()
+	now := time.Now()
+	cache := newTestPriceCache(stub.server.URL, &now)
+
+	// when
+	var wg sync.WaitGroup
+	prices := make([]float64, 10)
+	for i := range prices {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			prices[i], _ = cache.EthPrice(context.Background())
+		}(i)
+	}
+	assert.Eventually(t, func() bool {
+		return stub.Calls() == 1
+	}, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	close(stub.release)
+	wg.Wait()
+
+	// then
+	assert.Equal(t, int32(1), stub.Calls())
+	for _, price := range prices {
+		assert.Equal(t, float64(1), price)
+	}
+}
+
+func TestPriceCache_SingleFlightSurvivesCanceledCaller(t *testing.T) {
+	// given
+	stub := newSubgraphStub()
+	stub.release = make(chan struct{})
+	defer stub.server.Close()
+	now := time.Now()
+	cache := newTestPriceCache(stub.server.URL, &now)
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := cache.EthPrice(ctx)
+		firstErr <- err
+	}()
+	assert.Eventually(t, func() bool {
+		return stub.Calls() == 1
+	}, time.Second, time.Millisecond)
+	second := make(chan float64, 1)
+	go func() {
+		price, _ := cache.EthPrice(context.Background())
+		second <- price
+	}()
+
+	// when
+	cancel()
+
+	// then
+	assert.Equal(t, context.Canceled, <-firstErr)
+	close(stub.release)
+	assert.Equal(t, float64(1), <-second)
+	assert.Equal(t, int32(1), stub.Calls())
+}
+
+func TestPriceCache_Tokens(t *testing.T) {
+	// given
+	stub := newSubgraphStub()
+	defer stub.server.Close()
+	now := time.Now()
+	cache := newTestPriceCache(stub.server.URL, &now)
+	_, err := cache.Tokens(context.Background(), []string{tokenAddress(1)})
+	assert.NoError(t, err)
+
+	// when
+	tokens, err := cache.Tokens(context.Background(), []string{tokenAddress(1), tokenAddress(2)})
+
+	// then : only the token not cached is fetched
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(tokens))
+	assert.Equal(t, [][]interface{}{
+		{tokenAddress(1)},
+		{tokenAddress(2)},
+	}, stub.ids)
+	token, err := cache.Token(context.Background(), tokenAddress(2))
+	assert.NoError(t, err)
+	assert.Equal(t, tokenAddress(2), token.Id)
+	assert.Equal(t, int32(2), stub.Calls())
+}
@@ -0,0 +1,298 @@
+package uniswap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"kek-backend/internal/config"
+	"kek-backend/pkg/logging"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrNotFound is returned if the subgraph has no requested entity
+	ErrNotFound = errors.New("not found in uniswap subgraph")
+)
+
+// StatusError is returned if the subgraph responds with a non 2xx status code
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d, body: %s", e.StatusCode, e.Body)
+}
+
+// retryable returns true if a request may succeed when retried
+func (e *StatusError) retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// Client queries the uniswap subgraph
+type Client struct {
+	endpoint   string
+	batchSize  int
+	userAgent  string
+	retries    int
+	retryDelay time.Duration
+	httpClient *http.Client
+}
+
+// Timeout returns the longest time a request may take including retries
+func (c *Client) Timeout() time.Duration {
+	timeout := c.httpClient.Timeout * time.Duration(c.retries+1)
+	for attempt := 1; attempt <= c.retries; attempt++ {
+		timeout += c.retryDelay * time.Duration(attempt)
+	}
+	return timeout
+}
+
+// Query sends given GraphQL request and decodes the data of the response to given data.
+// GraphQLErrors is returned if the response has errors.
+func (c *Client) Query(ctx context.Context, req *GraphQLRequest, data interface{}) error {
+	body, err := c.Request(ctx, req)
+	if err != nil {
+		return err
+	}
+	var res graphQLResponse
+	if err := json.Unmarshal(body, &res); err != nil {
+		return errors.Wrap(err, "unmarshal response")
+	}
+	if len(res.Errors) != 0 {
+		return res.Errors
+	}
+	if len(res.Data) == 0 || string(res.Data) == "null" {
+		return errors.New("empty data in response")
+	}
+	if err := json.Unmarshal(res.Data, data); err != nil {
+		return errors.Wrap(err, "unmarshal data")
+	}
+	return nil
+}
+
+// Request posts given GraphQL request to the subgraph and returns the response body.
+// Network errors and 429, 5xx responses are retried up to the configured retries.
+func (c *Client) Request(ctx context.Context, req *GraphQLRequest) ([]byte, error) {
+	logger := logging.FromContext(ctx)
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal request")
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(c.retryDelay * time.Duration(attempt)):
+			}
+		}
+		data, err := c.do(ctx, body)
+		if err == nil {
+			return data, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if sErr, ok := err.(*StatusError); ok && !sErr.retryable() {
+			break
+		}
+		logger.Warnw("uniswap.client request failed", "attempt", attempt+1, "err", err)
+	}
+	return nil, lastErr
+}
+
+// do sends a single request with given body
+func (c *Client) do(ctx context.Context, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "create request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "send request")
+	}
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "read response")
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		if len(data) > 512 {
+			data = data[:512]
+		}
+		return nil, &StatusError{StatusCode: res.StatusCode, Body: string(data)}
+	}
+	return data, nil
+}
+
+// EthPrice returns the USD price of ETH
+func (c *Client) EthPrice(ctx context.Context) (float64, error) {
+	var bundles Bundles
+	if err := c.Query(ctx, QueryBundles(), &bundles); err != nil {
+		return 0, err
+	}
+	if len(bundles.Bundles) == 0 {
+		return 0, ErrNotFound
+	}
+	price, err := strconv.ParseFloat(bundles.Bundles[0].EthPrice, 64)
+	if err != nil {
+		return 0, errors.Wrap(err, "parse ethPrice")
+	}
+	return price, nil
+}
+
+// Token returns a token with given address.
+// ErrInvalidAddress is returned if the address is invalid and ErrNotFound is returned if the token does not exist
+func (c *Client) Token(ctx context.Context, address string) (*Token, error) {
+	req, err := QueryToken(address)
+	if err != nil {
+		return nil, err
+	}
+	var tokens Tokens
+	if err := c.Query(ctx, req, &tokens); err != nil {
+		return nil, err
+	}
+	if len(tokens.Tokens) == 0 {
+		return nil, ErrNotFound
+	}
+	return &tokens.Tokens[0], nil
+}
+
+// TopTokens returns given number of tokens ordered by trade volume in USD skipping given number of tokens
+func (c *Client) TopTokens(ctx context.Context, first, skip int) ([]Token, error) {
+	var tokens Tokens
+	if err := c.Query(ctx, QueryTopTokens(first, skip), &tokens); err != nil {
+		return nil, err
+	}
+	return tokens.Tokens, nil
+}
+
+// Tokens returns tokens with given addresses keyed by normalized address.
+// Addresses are deduplicated and fetched in chunks of the configured batch size.
+// Tokens which do not exist are absent in the result. If a chunk fails, tokens of
+// the chunks fetched so far are returned with the error.
+// ErrInvalidAddress is returned if any of the addresses is invalid
+func (c *Client) Tokens(ctx context.Context, addresses []string) (map[string]*Token, error) {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, address := range addresses {
+		id, err := NormalizeAddress(address)
+		if err != nil {
+			return nil, err
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+
+	batchSize := c.batchSize
+	if batchSize <= 0 {
+		batchSize = len(ids)
+	}
+	ret := make(map[string]*Token, len(ids))
+	for start := 0; start < len(ids); start += batchSize {
+		end := start + batchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		req, err := QueryTokens(ids[start:end])
+		if err != nil {
+			return ret, err
+		}
+		var tokens Tokens
+		if err := c.Query(ctx, req, &tokens); err != nil {
+			return ret, err
+		}
+		for i := range tokens.Tokens {
+			token := tokens.Tokens[i]
+			ret[strings.ToLower(token.Id)] = &token
+		}
+	}
+	return ret, nil
+}
+
+// maxFirst is the maximum number of entities the subgraph returns for a query
+const maxFirst = 1000
+
+// TokenDayDatas returns daily data of tokens with given addresses for the latest given days
+// keyed by normalized address. The data of each token are ordered by date descending and
+// the first one is the current UTC day if it has any data. Addresses are fetched in chunks
+// so that a response does not exceed the subgraph's limit. If a chunk fails, data of the
+// chunks fetched so far are returned with the error.
+// ErrInvalidAddress is returned if any of the addresses is invalid
+func (c *Client) TokenDayDatas(ctx context.Context, addresses []string, days int, now time.Time) (map[string][]TokenDayData, error) {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, address := range addresses {
+		id, err := NormalizeAddress(address)
+		if err != nil {
+			return nil, err
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+
+	if days < 1 {
+		days = 1
+	}
+	batchSize := maxFirst / days
+	if c.batchSize > 0 && c.batchSize < batchSize {
+		batchSize = c.batchSize
+	}
+	since := now.UTC().Truncate(24*time.Hour).AddDate(0, 0, -(days - 1)).Unix()
+	ret := make(map[string][]TokenDayData, len(ids))
+	for start := 0; start < len(ids); start += batchSize {
+		end := start + batchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		req, err := QueryTokenDayDatas(ids[start:end], since, (end-start)*days)
+		if err != nil {
+			return ret, err
+		}
+		var dayDatas TokenDayDatas
+		if err := c.Query(ctx, req, &dayDatas); err != nil {
+			return ret, err
+		}
+		for _, d := range dayDatas.TokenDayDatas {
+			id := strings.ToLower(d.Token.Id)
+			ret[id] = append(ret[id], d)
+		}
+	}
+	return ret, nil
+}
+
+// NewClient creates a new subgraph client with given config
+func NewClient(cfg *config.Config) *Client {
+	c := cfg.UniswapConfig
+	return &Client{
+		endpoint:   c.Endpoint,
+		batchSize:  c.BatchSize,
+		userAgent:  c.UserAgent,
+		retries:    c.Retries,
+		retryDelay: time.Duration(c.RetryDelayMillis) * time.Millisecond,
+		httpClient: &http.Client{Timeout: time.Duration(c.TimeoutSecs) * time.Second},
+	}
+}
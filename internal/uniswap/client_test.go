@@ -0,0 +1,306 @@
+package uniswap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"kek-backend/internal/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_EthPrice(t *testing.T) {
+	// given
+	var (
+		userAgent string
+		query     map[string]string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		_ = json.NewDecoder(r.Body).Decode(&query)
+		_, _ = w.Write([]byte(`{"data":{"bundles":[{"ethPrice":"2000.5"}]}}`))
+	}))
+	defer server.Close()
+	client := newTestClient(server.URL, 0)
+
+	// when
+	price, err := client.EthPrice(context.Background())
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 2000.5, price)
+	assert.Equal(t, "kek-backend-test", userAgent)
+	assert.Contains(t, query["query"], "bundles")
+}
+
+func TestClient_Token(t *testing.T) {
+	// given
+	var req GraphQLRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_, _ = w.Write([]byte(`{"data":{"tokens":[{"id":"0x6b175474e89094c44da98b954eedeac495271d0f","symbol":"DAI","derivedETH":"0.0005"}]}}`))
+	}))
+	defer server.Close()
+	client := newTestClient(server.URL, 0)
+
+	// when
+	token, err := client.Token(context.Background(), " 0x6B175474E89094C44Da98b954EedeAC495271d0F ")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "DAI", token.Symbol)
+	assert.Equal(t, "0x6b175474e89094c44da98b954eedeac495271d0f", req.Variables["id"])
+	assert.NotContains(t, req.Query, "0x6b17")
+	price, err := token.PriceUSD(2000)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), price)
+}
+
+func TestClient_TokenNotFound(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"tokens":[]}}`))
+	}))
+	defer server.Close()
+	client := newTestClient(server.URL, 0)
+
+	// when
+	token, err := client.Token(context.Background(), "0x6b175474e89094c44da98b954eedeac495271d0f")
+
+	// then
+	assert.Nil(t, token)
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestClient_TokenInvalidAddress(t *testing.T) {
+	// given
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer server.Close()
+	client := newTestClient(server.URL, 0)
+
+	// when
+	token, err := client.Token(context.Background(), `0x6b17" }) { id } #`)
+
+	// then
+	assert.Nil(t, token)
+	assert.Equal(t, ErrInvalidAddress, err)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+}
+
+func TestClient_QueryErrors(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errors":[{"message":"Unknown field","locations":[{"line":1,"column":2}]},{"message":"Bad id"}]}`))
+	}))
+	defer server.Close()
+	client := newTestClient(server.URL, 0)
+
+	// when
+	_, err := client.EthPrice(context.Background())
+
+	// then
+	gErrs, ok := err.(GraphQLErrors)
+	assert.True(t, ok)
+	assert.Equal(t, 2, len(gErrs))
+	assert.Equal(t, 1, gErrs[0].Locations[0].Line)
+	assert.Equal(t, "graphql errors: Unknown field; Bad id", err.Error())
+}
+
+func TestClient_RetryIfServerError(t *testing.T) {
+	// given
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"bundles":[{"ethPrice":"2000"}]}}`))
+	}))
+	defer server.Close()
+	client := newTestClient(server.URL, 2)
+
+	// when
+	price, err := client.EthPrice(context.Background())
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, float64(2000), price)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestClient_FailWithoutRetryIfClientError(t *testing.T) {
+	// given
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+	client := newTestClient(server.URL, 2)
+
+	// when
+	_, err := client.EthPrice(context.Background())
+
+	// then
+	sErr, ok := err.(*StatusError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusBadRequest, sErr.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestClient_FailIfContextCanceled(t *testing.T) {
+	// given
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+	client := newTestClient(server.URL, 2)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// when
+	_, err := client.EthPrice(ctx)
+
+	// then
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func newTestClient(endpoint string, retries int) *Client {
+	cfg := config.Config{}
+	cfg.UniswapConfig = config.UniswapConfig{
+		Endpoint:         endpoint,
+		TimeoutSecs:      1,
+		Retries:          retries,
+		RetryDelayMillis: 1,
+		UserAgent:        "kek-backend-test",
+	}
+	return NewClient(&cfg)
+}
+
+func TestClient_Tokens(t *testing.T) {
+	// given
+	var (
+		mu       sync.Mutex
+		requests [][]interface{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req GraphQLRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		ids := req.Variables["ids"].([]interface{})
+		mu.Lock()
+		requests = append(requests, ids)
+		mu.Unlock()
+
+		var tokens Tokens
+		for _, id := range ids {
+			// the subgraph has no token 3
+			if id == tokenAddress(3) {
+				continue
+			}
+			tokens.Tokens = append(tokens.Tokens, Token{Id: id.(string), DerivedETH: "1"})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": tokens})
+	}))
+	defer server.Close()
+	client := newTestClient(server.URL, 0)
+	client.batchSize = 2
+
+	// when
+	tokens, err := client.Tokens(context.Background(), []string{
+		tokenAddress(1), tokenAddress(2), strings.ToUpper(tokenAddress(1)), tokenAddress(3),
+	})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(tokens))
+	assert.Equal(t, tokenAddress(1), tokens[tokenAddress(1)].Id)
+	assert.Equal(t, tokenAddress(2), tokens[tokenAddress(2)].Id)
+	assert.Nil(t, tokens[tokenAddress(3)])
+	// 3 distinct addresses in chunks of 2
+	assert.Equal(t, [][]interface{}{
+		{tokenAddress(1), tokenAddress(2)},
+		{tokenAddress(3)},
+	}, requests)
+}
+
+func TestClient_TokensPartialFailure(t *testing.T) {
+	// given
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) > 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"tokens":[{"id":"` + tokenAddress(1) + `","derivedETH":"1"}]}}`))
+	}))
+	defer server.Close()
+	client := newTestClient(server.URL, 0)
+	client.batchSize = 1
+
+	// when
+	tokens, err := client.Tokens(context.Background(), []string{tokenAddress(1), tokenAddress(2)})
+
+	// then
+	assert.Error(t, err)
+	assert.Equal(t, 1, len(tokens))
+	assert.NotNil(t, tokens[tokenAddress(1)])
+}
+
+func TestClient_TokenDayDatas(t *testing.T) {
+	// given
+	now := time.Date(2021, 10, 8, 15, 0, 0, 0, time.UTC)
+	today := now.Truncate(24 * time.Hour).Unix()
+	var requests []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req GraphQLRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req.Variables)
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"data":{"tokenDayDatas":[
+			{"date":%d,"token":{"id":"%s"},"dailyVolumeUSD":"300"},
+			{"date":%d,"token":{"id":"%s"},"dailyVolumeUSD":"200"},
+			{"date":%d,"token":{"id":"%s"},"dailyVolumeUSD":"100"}
+		]}}`, today, tokenAddress(1), today-86400, tokenAddress(2), today-86400, tokenAddress(1))))
+	}))
+	defer server.Close()
+	client := newTestClient(server.URL, 0)
+
+	// when
+	dayDatas, err := client.TokenDayDatas(context.Background(), []string{tokenAddress(1), tokenAddress(2)}, 3, now)
+
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, dayDatas[tokenAddress(1)], 2)
+	assert.Equal(t, "300", dayDatas[tokenAddress(1)][0].DailyVolumeUSD)
+	assert.Equal(t, "100", dayDatas[tokenAddress(1)][1].DailyVolumeUSD)
+	assert.Len(t, dayDatas[tokenAddress(2)], 1)
+	// 2 tokens for 3 days from 2 days ago
+	assert.Len(t, requests, 1)
+	assert.Equal(t, float64(today-2*86400), requests[0]["since"])
+	assert.Equal(t, float64(6), requests[0]["first"])
+}
+
+func TestToken_LiquidityUSD(t *testing.T) {
+	token := Token{DerivedETH: "0.0005", TotalLiquidity: "1000"}
+
+	liquidity, err := token.LiquidityUSD(2000)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1000.0, liquidity)
+}
+
+// tokenAddress returns a valid address ending with given number
+func tokenAddress(n int) string {
+	return fmt.Sprintf("0x%040x", n)
+}
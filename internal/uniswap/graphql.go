@@ -0,0 +1,41 @@
+package uniswap
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// GraphQLRequest is a body of a GraphQL request.
+// Values must be passed through Variables rather than formatted into Query.
+type GraphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+// GraphQLError is an error reported in the errors array of a GraphQL response
+type GraphQLError struct {
+	Message   string `json:"message"`
+	Locations []struct {
+		Line   int `json:"line"`
+		Column int `json:"column"`
+	} `json:"locations,omitempty"`
+	Path []interface{} `json:"path,omitempty"`
+}
+
+// GraphQLErrors is returned if a GraphQL response has errors
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Message)
+	}
+	return fmt.Sprintf("graphql errors: %s", strings.Join(messages, "; "))
+}
+
+// graphQLResponse is a body of a GraphQL response
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors GraphQLErrors   `json:"errors"`
+}
@@ -1,12 +1,9 @@
 package uniswap
 
-import (
-	"fmt"
-)
-
-func QueryBundles() map[string]string {
-	return map[string]string{
-		"query": `
+// QueryBundles returns a request of the USD price of ETH
+func QueryBundles() *GraphQLRequest {
+	return &GraphQLRequest{
+		Query: `
 			query bundles {
 				bundles(where: { id: "1" }) {
 					ethPrice
@@ -16,17 +13,103 @@ func QueryBundles() map[string]string {
 	}
 }
 
-func QueryToken(address string) map[string]string {
-	query := fmt.Sprintf(`
-		query tokens {
-			tokens(where: { id: "%s" }) {
-				id
-				name
-				symbol
-				derivedETH
-				totalLiquidity
+// QueryToken returns a request of a token with given address.
+// ErrInvalidAddress is returned if the address is invalid
+func QueryToken(address string) (*GraphQLRequest, error) {
+	id, err := NormalizeAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	return &GraphQLRequest{
+		Query: `
+			query tokens($id: ID!) {
+				tokens(where: { id: $id }) {
+					id
+					name
+					symbol
+					decimals
+					derivedETH
+					totalLiquidity
+				}
 			}
+		`,
+		Variables: map[string]interface{}{"id": id},
+	}, nil
+}
+
+// QueryTokens returns a request of tokens with given addresses.
+// ErrInvalidAddress is returned if any of the addresses is invalid
+func QueryTokens(addresses []string) (*GraphQLRequest, error) {
+	ids := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		id, err := NormalizeAddress(address)
+		if err != nil {
+			return nil, err
 		}
-	`, address)
-	return map[string]string{"query": query}
+		ids = append(ids, id)
+	}
+	return &GraphQLRequest{
+		Query: `
+			query tokens($ids: [ID!]!, $first: Int!) {
+				tokens(first: $first, where: { id_in: $ids }) {
+					id
+					name
+					symbol
+					decimals
+					derivedETH
+					totalLiquidity
+				}
+			}
+		`,
+		Variables: map[string]interface{}{"ids": ids, "first": len(ids)},
+	}, nil
+}
+
+// QueryTopTokens returns a request of given number of tokens ordered by trade volume in USD
+// skipping given number of tokens
+func QueryTopTokens(first, skip int) *GraphQLRequest {
+	return &GraphQLRequest{
+		Query: `
+			query tokens($first: Int!, $skip: Int!) {
+				tokens(first: $first, skip: $skip, orderBy: tradeVolumeUSD, orderDirection: desc) {
+					id
+					name
+					symbol
+					decimals
+					derivedETH
+					totalLiquidity
+				}
+			}
+		`,
+		Variables: map[string]interface{}{"first": first, "skip": skip},
+	}
+}
+
+// QueryTokenDayDatas returns a request of daily data of tokens with given addresses since given unix time.
+// first must be large enough for all days of all the tokens.
+// ErrInvalidAddress is returned if any of the addresses is invalid
+func QueryTokenDayDatas(addresses []string, since int64, first int) (*GraphQLRequest, error) {
+	ids := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		id, err := NormalizeAddress(address)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return &GraphQLRequest{
+		Query: `
+			query tokenDayDatas($ids: [String!]!, $since: Int!, $first: Int!) {
+				tokenDayDatas(first: $first, orderBy: date, orderDirection: desc, where: { token_in: $ids, date_gte: $since }) {
+					date
+					token {
+						id
+					}
+					dailyVolumeUSD
+					totalLiquidityUSD
+				}
+			}
+		`,
+		Variables: map[string]interface{}{"ids": ids, "since": since, "first": first},
+	}, nil
 }
@@ -1,21 +1,58 @@
 package uniswap
 
+import "strconv"
+
 type Bundles struct {
-	Data struct {
-		Bundles []struct {
-			EthPrice string `json:"ethPrice"`
-		} `json:"bundles"`
-	} `json:"data"`
+	Bundles []struct {
+		EthPrice string `json:"ethPrice"`
+	} `json:"bundles"`
 }
 
 type Tokens struct {
-	Data struct {
-		Tokens []struct {
-			Id             string `json:"id"`
-			Name           string `json:"name"`
-			Symbol         string `json:"symbol"`
-			DerivedETH     string `json:"derivedETH"`
-			TotalLiquidity string `json:"totalLiquidity"`
-		} `json:"tokens"`
-	} `json:"data"`
+	Tokens []Token `json:"tokens"`
+}
+
+type Token struct {
+	Id             string `json:"id"`
+	Name           string `json:"name"`
+	Symbol         string `json:"symbol"`
+	Decimals       string `json:"decimals"`
+	DerivedETH     string `json:"derivedETH"`
+	TotalLiquidity string `json:"totalLiquidity"`
+}
+
+type TokenDayDatas struct {
+	TokenDayDatas []TokenDayData `json:"tokenDayDatas"`
+}
+
+// TokenDayData is a summary of a token in a UTC day starting from Date in unix seconds
+type TokenDayData struct {
+	Date  int64 `json:"date"`
+	Token struct {
+		Id string `json:"id"`
+	} `json:"token"`
+	DailyVolumeUSD    string `json:"dailyVolumeUSD"`
+	TotalLiquidityUSD string `json:"totalLiquidityUSD"`
+}
+
+// PriceUSD returns the USD price of the token with given USD price of ETH
+func (t *Token) PriceUSD(ethPrice float64) (float64, error) {
+	derived, err := strconv.ParseFloat(t.DerivedETH, 64)
+	if err != nil {
+		return 0, err
+	}
+	return ethPrice * derived, nil
+}
+
+// LiquidityUSD returns the total liquidity of the token in USD with given USD price of ETH
+func (t *Token) LiquidityUSD(ethPrice float64) (float64, error) {
+	liquidity, err := strconv.ParseFloat(t.TotalLiquidity, 64)
+	if err != nil {
+		return 0, err
+	}
+	price, err := t.PriceUSD(ethPrice)
+	if err != nil {
+		return 0, err
+	}
+	return liquidity * price, nil
 }
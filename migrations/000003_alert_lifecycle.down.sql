@@ -0,0 +1,3 @@
+ALTER TABLE alerts DROP COLUMN last_triggered_at;
+ALTER TABLE alerts DROP COLUMN status_changed_at;
+ALTER TABLE alerts DROP COLUMN rearm_policy;
@@ -0,0 +1,3 @@
+ALTER TABLE alerts ADD COLUMN rearm_policy VARCHAR ( 10 ) NOT NULL DEFAULT 'once';
+ALTER TABLE alerts ADD COLUMN status_changed_at TIMESTAMP NULL;
+ALTER TABLE alerts ADD COLUMN last_triggered_at TIMESTAMP NULL;
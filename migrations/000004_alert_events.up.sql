@@ -0,0 +1,12 @@
+-- alert events
+CREATE TABLE alert_events (
+	id serial PRIMARY KEY,
+	alert_id INTEGER NOT NULL,
+	observed_price DOUBLE PRECISION NOT NULL,
+	threshold DOUBLE PRECISION NOT NULL,
+	delivery_status TEXT NOT NULL,
+	fired_at TIMESTAMP NOT NULL,
+	created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX alert_events_alert_id_fired_at ON alert_events (alert_id, fired_at);
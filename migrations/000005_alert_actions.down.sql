@@ -0,0 +1,11 @@
+-- actions configured with a webhook, email or bot do not fit in the old column
+DO $$
+BEGIN
+	IF EXISTS (SELECT 1 FROM alerts WHERE alert_actions NOT IN ('[]', '[{"type":"push"}]')) THEN
+		RAISE EXCEPTION 'alerts have actions other than push, remove them before migrating down';
+	END IF;
+END $$;
+
+-- push only actions are converted back to the value converted by the up migration
+UPDATE alerts SET alert_actions = 'push' WHERE alert_actions = '[{"type":"push"}]';
+ALTER TABLE alerts ALTER COLUMN alert_actions TYPE VARCHAR ( 20 );
@@ -0,0 +1,2 @@
+ALTER TABLE alerts ALTER COLUMN alert_actions TYPE TEXT;
+UPDATE alerts SET alert_actions = '[{"type":"push"}]' WHERE alert_actions NOT LIKE '[%';
@@ -0,0 +1,16 @@
+-- notification outbox
+CREATE TABLE notification_outbox (
+	id serial PRIMARY KEY,
+	alert_event_id INTEGER NOT NULL,
+	alert_id INTEGER NOT NULL,
+	channel VARCHAR ( 20 ) NOT NULL,
+	payload TEXT NOT NULL,
+	status VARCHAR ( 10 ) NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at TIMESTAMP NOT NULL,
+	last_error TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX notification_outbox_status_next_attempt_at ON notification_outbox (status, next_attempt_at);
@@ -0,0 +1,12 @@
+-- tokens
+CREATE TABLE tokens (
+	id VARCHAR ( 42 ) PRIMARY KEY,
+	name VARCHAR ( 255 ) NOT NULL,
+	symbol VARCHAR ( 64 ) NOT NULL,
+	decimals INTEGER NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX tokens_lower_symbol ON tokens (LOWER(symbol) varchar_pattern_ops);
+CREATE INDEX tokens_lower_name ON tokens (LOWER(name) varchar_pattern_ops);
@@ -0,0 +1,26 @@
+-- price_snapshots
+CREATE TABLE price_snapshots (
+	id serial PRIMARY KEY,
+	token_id VARCHAR ( 42 ) NOT NULL,
+	price_usd DOUBLE PRECISION NOT NULL,
+	observed_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX price_snapshots_observed_at ON price_snapshots (observed_at);
+CREATE INDEX price_snapshots_token_id_observed_at ON price_snapshots (token_id, observed_at);
+
+-- candles
+CREATE TABLE candles (
+	token_id VARCHAR ( 42 ) NOT NULL,
+	candle_interval VARCHAR ( 8 ) NOT NULL,
+	open_time TIMESTAMP NOT NULL,
+	open DOUBLE PRECISION NOT NULL,
+	high DOUBLE PRECISION NOT NULL,
+	low DOUBLE PRECISION NOT NULL,
+	close DOUBLE PRECISION NOT NULL,
+	samples INTEGER NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (token_id, candle_interval, open_time)
+);
+
+CREATE INDEX candles_candle_interval_open_time ON candles (candle_interval, open_time);
@@ -0,0 +1,2 @@
+ALTER TABLE alerts DROP COLUMN baseline_price;
+ALTER TABLE alerts DROP COLUMN alert_window;
@@ -0,0 +1,2 @@
+ALTER TABLE alerts ADD COLUMN alert_window VARCHAR ( 16 ) NOT NULL DEFAULT '';
+ALTER TABLE alerts ADD COLUMN baseline_price DOUBLE PRECISION NULL;
@@ -0,0 +1 @@
+ALTER TABLE alerts ADD COLUMN alert_expression TEXT NULL;
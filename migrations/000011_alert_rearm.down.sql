@@ -0,0 +1,4 @@
+ALTER TABLE alerts DROP COLUMN last_observed_at;
+ALTER TABLE alerts DROP COLUMN last_observed_price;
+ALTER TABLE alerts DROP COLUMN hysteresis;
+ALTER TABLE alerts DROP COLUMN cooldown_minutes;
@@ -0,0 +1,4 @@
+ALTER TABLE alerts ADD COLUMN cooldown_minutes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE alerts ADD COLUMN hysteresis DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE alerts ADD COLUMN last_observed_price DOUBLE PRECISION NULL;
+ALTER TABLE alerts ADD COLUMN last_observed_at TIMESTAMP NULL;
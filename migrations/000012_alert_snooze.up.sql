@@ -0,0 +1 @@
+ALTER TABLE alerts ADD COLUMN snoozed_until TIMESTAMP NULL;
@@ -0,0 +1 @@
+ALTER TABLE alert_events DROP COLUMN is_test;
@@ -0,0 +1 @@
+ALTER TABLE alert_events ADD COLUMN is_test BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,6 @@
+-- scheduler leases
+CREATE TABLE scheduler_leases (
+	name VARCHAR ( 64 ) PRIMARY KEY,
+	holder VARCHAR ( 128 ) NOT NULL,
+	expires_at TIMESTAMP NOT NULL
+);
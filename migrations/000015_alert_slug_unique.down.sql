@@ -0,0 +1 @@
+DROP INDEX alerts_slug;
@@ -0,0 +1,8 @@
+-- rename duplicated slugs of alerts not deleted except the oldest one
+UPDATE alerts a SET slug = LEFT(a.slug, 89) || '-' || a.id
+WHERE a.deleted_at_unix = 0 AND EXISTS (
+	SELECT 1 FROM alerts o WHERE o.slug = a.slug AND o.deleted_at_unix = 0 AND o.id < a.id
+);
+
+-- unique slugs of alerts not deleted
+CREATE UNIQUE INDEX alerts_slug ON alerts (slug) WHERE deleted_at_unix = 0;